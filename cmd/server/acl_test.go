@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/acl"
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ACL(t *testing.T) {
+	list, err := acl.New([]acl.Rule{
+		{Principal: "app", Prefix: "app:", Operations: []acl.Operation{acl.Write, acl.Read}},
+	})
+	if err != nil {
+		t.Fatalf("new acl: %v", err)
+	}
+
+	handler := httpMux(storage.New(), options{
+		keyLiveTime: time.Minute,
+		auth:        auth.NewAuthenticator(map[string]string{"app": "key"}, nil),
+		acl:         list,
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		expect int
+	}{
+		{
+			name:   "set allowed",
+			method: "POST",
+			target: "/set",
+			body:   `{"key": "app:1", "value": "v"}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "peek allowed",
+			method: "GET",
+			target: "/get",
+			body:   `{"key": "app:1", "peek": true}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "consume denied",
+			method: "GET",
+			target: "/get",
+			body:   `{"key": "app:1"}`,
+			expect: http.StatusForbidden,
+		},
+		{
+			name:   "set of other prefix denied",
+			method: "POST",
+			target: "/set",
+			body:   `{"key": "ops:1", "value": "v"}`,
+			expect: http.StatusForbidden,
+		},
+		{
+			name:   "get with path denied",
+			method: "GET",
+			target: "/get",
+			body:   `{"key": "ops:1", "path": ""}`,
+			expect: http.StatusForbidden,
+		},
+		{
+			name:   "hset of other prefix denied",
+			method: "POST",
+			target: "/hset",
+			body:   `{"key": "ops:2", "field": "f", "value": "v"}`,
+			expect: http.StatusForbidden,
+		},
+		{
+			name:   "malformed key denied",
+			method: "POST",
+			target: "/set",
+			body:   `{"key": ["ops:1"], "value": "v"}`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "case variant key of get denied",
+			method: "GET",
+			target: "/get",
+			body:   `{"key": "app:1", "KEY": "ops:secret", "peek": true}`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "case variant key of set denied",
+			method: "POST",
+			target: "/set",
+			body:   `{"key": "app:1", "Key": "ops:secret", "value": "x"}`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "folded key denied",
+			method: "POST",
+			target: "/set",
+			body:   `{"\u212aey": "ops:secret", "value": "x"}`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "case variant keys denied",
+			method: "GET",
+			target: "/sinter",
+			body:   `{"keys": ["app:1"], "Keys": ["ops:1"]}`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "case variant peek denied",
+			method: "GET",
+			target: "/get",
+			body:   `{"key": "app:1", "PEEK": true}`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "other prefix denied",
+			method: "PUT",
+			target: "/v1/keys/ops:1",
+			body:   `"v"`,
+			expect: http.StatusForbidden,
+		},
+		{
+			name:   "admin denied",
+			method: "GET",
+			target: "/admin/schemas",
+			expect: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "http://any-host"+tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "key")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func Test_AdminSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		schema string
+	}{
+		{
+			name:   "register",
+			method: "POST",
+			path:   "/admin/schemas",
+			body:   `{"prefix": "user:", "schema": {"type": "object", "required": ["name"]}}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message"], "properties": {"message": {"type": "string"}}}`,
+		},
+		{
+			name:   "invalid schema",
+			method: "POST",
+			path:   "/admin/schemas",
+			body:   `{"prefix": "user:", "schema": {"type": 1}}`,
+			code:   http.StatusBadRequest,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "items": {"type": "object", "properties": {"field": {"enum": ["schema"]}}}}}}`,
+		},
+		{
+			name:   "list",
+			method: "GET",
+			path:   "/admin/schemas",
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["schemas"], "properties": {"schemas": {"type": "object", "required": ["user:"]}}}}}`,
+		},
+		{
+			name:   "set violates schema",
+			method: "POST",
+			path:   "/set",
+			body:   `{"key": "user:1", "value": {"age": 1}}`,
+			code:   http.StatusBadRequest,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"enum": ["value"]}, "message": {"type": "string"}}}}}}`,
+		},
+		{
+			name:   "set conforms schema",
+			method: "POST",
+			path:   "/set",
+			body:   `{"key": "user:1", "value": {"name": "name"}}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message"], "properties": {"message": {"type": "string"}}}`,
+		},
+		{
+			name:   "delete",
+			method: "DELETE",
+			path:   "/admin/schemas",
+			body:   `{"prefix": "user:"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message"], "properties": {"message": {"type": "string"}}}`,
+		},
+		{
+			name:   "delete not found",
+			method: "DELETE",
+			path:   "/admin/schemas",
+			body:   `{"prefix": "user:"}`,
+			code:   http.StatusNotFound,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [404]}, "code": {"enum": ["schema_not_found"]}}}`,
+		},
+	}
+
+	handler := httpMux(storage.New(), options{keyLiveTime: time.Second})
+
+	// Cases depend on each other and run sequentially.
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(handler)
+			defer s.Close()
+
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("%s%s", s.URL, tt.path), strings.NewReader(tt.body))
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+
+			schema := gojsonschema.NewStringLoader(tt.schema)
+			doc := gojsonschema.NewStringLoader(res.Body.String())
+
+			result, err := gojsonschema.Validate(schema, doc)
+
+			assert.Nil(t, err)
+			assert.True(t, result.Valid())
+			assert.Empty(t, result.Errors())
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Auth(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		expect int
+	}{
+		{
+			name:   "valid key",
+			apiKey: "key",
+			expect: http.StatusOK,
+		},
+		{
+			name:   "invalid key",
+			apiKey: "other",
+			expect: http.StatusUnauthorized,
+		},
+		{
+			name:   "anonymous",
+			expect: http.StatusUnauthorized,
+		},
+	}
+
+	handler := httpMux(storage.New(), options{
+		keyLiveTime: time.Minute,
+		auth:        auth.NewAuthenticator(map[string]string{"app": "key"}, nil),
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "http://any-host/v1/keys/key", strings.NewReader("value"))
+			req.Header.Set("Content-Type", "text/plain")
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+			assert.Equal(t, tt.expect, res.Code)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/codec"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Codecs(t *testing.T) {
+	handler := httpMux(storage.New(), options{keyLiveTime: time.Minute})
+
+	body, err := codec.MessagePack.Marshal(map[string]interface{}{
+		"key":   "key",
+		"value": map[string]interface{}{"count": 1},
+	})
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest("POST", "http://any-host/set", bytes.NewReader(body))
+	req.Header.Set("Content-Type", codec.MessagePackMediaType)
+	req.Header.Set("Accept", codec.MessagePackMediaType)
+	res := httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, codec.MessagePackMediaType, res.Header().Get("Content-Type"))
+
+	body, err = codec.CBOR.Marshal(map[string]interface{}{"key": "key", "path": "/count"})
+	assert.Nil(t, err)
+
+	req = httptest.NewRequest("GET", "http://any-host/get", bytes.NewReader(body))
+	req.Header.Set("Content-Type", codec.CBORMediaType)
+	req.Header.Set("Accept", codec.CBORMediaType)
+	res = httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, codec.CBORMediaType, res.Header().Get("Content-Type"))
+
+	var got struct {
+		Message string `json:"message"`
+		Data    struct {
+			Value int64 `json:"value"`
+		} `json:"data"`
+	}
+	assert.Nil(t, codec.CBOR.Unmarshal(res.Body.Bytes(), &got))
+	assert.Equal(t, int64(1), got.Data.Value)
+}
@@ -27,28 +27,42 @@ func Test_GetGet(t *testing.T) {
 			code:   http.StatusOK,
 			schema: `{"type":"object", "required": ["message"], "properties": {"message": {"type": "string"}}}`,
 		},
+		{
+			name:   "peek path",
+			body:   `{"key": "doc", "path": "/a/0", "peek": true}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["value"], "properties": {"value": {"enum": ["b"]}}}}}`,
+		},
+		{
+			name:   "jsonpath without matches",
+			body:   `{"key": "doc", "path": "$.c", "peek": true}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["value"], "properties": {"value": {"type": "array", "maxItems": 0}}}}}`,
+		},
 		{
 			name:   "not found",
 			body:   `{"key": "not found"}`,
 			code:   http.StatusNotFound,
-			schema: `{"type":"object", "required": ["message"], "properties": {"message": {"type": "string"}}}`,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [404]}, "code": {"enum": ["key_not_found"]}}}`,
 		},
 		{
 			name:   "validaion errors",
-			schema: `{"type":"object", "required": ["message", "errors"], "properties": {"message": {"type": "string"}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
+			body:   `{}`,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
 			code:   http.StatusBadRequest,
 		},
 	}
 
 	s := storage.New()
 	s.Set(context.Background(), "key", "value")
+	s.Set(context.Background(), "doc", map[string]interface{}{"a": []interface{}{"b"}})
 
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			handler := httpMux(s, time.Second)
+			handler := httpMux(s, options{keyLiveTime: time.Second})
 			s := httptest.NewServer(handler)
 			defer s.Close()
 
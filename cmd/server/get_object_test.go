@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func Test_GetObject(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		code   int
+		schema string
+	}{
+		{
+			name:   "type",
+			path:   "/type",
+			body:   `{"key": "key"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["type"], "properties": {"type": {"enum": ["hash"]}}}}}`,
+		},
+		{
+			name:   "object",
+			path:   "/object",
+			body:   `{"key": "key"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["type", "created_at", "accessed_at", "idle_seconds", "ttl_seconds", "size"]}}}`,
+		},
+		{
+			name:   "not found",
+			path:   "/object",
+			body:   `{"key": "not found"}`,
+			code:   http.StatusNotFound,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [404]}, "code": {"enum": ["key_not_found"]}}}`,
+		},
+		{
+			name:   "validaion errors",
+			path:   "/type",
+			body:   `{}`,
+			code:   http.StatusBadRequest,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
+		},
+	}
+
+	s := storage.New()
+	s.HSet(context.Background(), "key", "field", "value")
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := httpMux(s, options{keyLiveTime: time.Second})
+			s := httptest.NewServer(handler)
+			defer s.Close()
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("%s%s", s.URL, tt.path), strings.NewReader(tt.body))
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+
+			schema := gojsonschema.NewStringLoader(tt.schema)
+			doc := gojsonschema.NewStringLoader(res.Body.String())
+
+			result, err := gojsonschema.Validate(schema, doc)
+
+			assert.Nil(t, err)
+			assert.True(t, result.Valid())
+			assert.Empty(t, result.Errors())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func Test_Hash(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		schema string
+	}{
+		{
+			name:   "hset",
+			method: "POST",
+			path:   "/hset",
+			body:   `{"key": "new", "field": "field", "value": "value"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["created"], "properties": {"created": {"type": "boolean"}}}}}`,
+		},
+		{
+			name:   "hget",
+			method: "GET",
+			path:   "/hget",
+			body:   `{"key": "hash", "field": "field"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["value"]}}}`,
+		},
+		{
+			name:   "hgetall",
+			method: "GET",
+			path:   "/hgetall",
+			body:   `{"key": "hash"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["fields"], "properties": {"fields": {"type": "object"}}}}}`,
+		},
+		{
+			name:   "hincrby",
+			method: "POST",
+			path:   "/hincrby",
+			body:   `{"key": "hash", "field": "counter", "increment": 2}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["value"], "properties": {"value": {"type": "integer"}}}}}`,
+		},
+		{
+			name:   "hdel not found",
+			method: "POST",
+			path:   "/hdel",
+			body:   `{"key": "not found", "fields": ["field"]}`,
+			code:   http.StatusNotFound,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [404]}, "code": {"enum": ["key_not_found"]}}}`,
+		},
+		{
+			name:   "wrong type",
+			method: "POST",
+			path:   "/hset",
+			body:   `{"key": "string", "field": "field", "value": "value"}`,
+			code:   http.StatusConflict,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [409]}, "code": {"enum": ["wrong_type"]}}}`,
+		},
+		{
+			name:   "validaion errors",
+			method: "POST",
+			path:   "/hset",
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
+			body:   `{}`,
+			code:   http.StatusBadRequest,
+		},
+	}
+
+	s := storage.New()
+	s.Set(context.Background(), "string", "value")
+	s.HSet(context.Background(), "hash", "field", "value")
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := httpMux(s, options{keyLiveTime: time.Second})
+			s := httptest.NewServer(handler)
+			defer s.Close()
+
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("%s%s", s.URL, tt.path), strings.NewReader(tt.body))
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+
+			schema := gojsonschema.NewStringLoader(tt.schema)
+			doc := gojsonschema.NewStringLoader(res.Body.String())
+
+			result, err := gojsonschema.Validate(schema, doc)
+
+			assert.Nil(t, err)
+			assert.True(t, result.Valid())
+			assert.Empty(t, result.Errors())
+		})
+	}
+}
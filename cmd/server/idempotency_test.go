@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/pkg/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Idempotency(t *testing.T) {
+	server := httptest.NewServer(httpMux(storage.New(), options{
+		keyLiveTime: time.Minute,
+	}))
+	defer server.Close()
+
+	var payments int
+	started, proceed := make(chan struct{}), make(chan struct{})
+
+	handler := idempotency.Middleware(idempotency.NewClient(server.URL, nil), time.Minute, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payments++
+		started <- struct{}{}
+		<-proceed
+
+		w.Header().Set("Location", "/payments/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("paid"))
+	}))
+
+	pay := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://any-host/payments", strings.NewReader(body))
+		req.Header.Set(idempotency.KeyHeader, "pay-1")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		return res
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() {
+		first <- pay(`{"amount": 10}`)
+	}()
+	<-started
+
+	res := pay(`{"amount": 10}`)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Contains(t, res.Body.String(), `"code":"request_in_flight"`)
+
+	close(proceed)
+	res = <-first
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "paid", res.Body.String())
+
+	res = pay(`{"amount": 10}`)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "paid", res.Body.String())
+	assert.Equal(t, "/payments/1", res.Header().Get("Location"))
+	assert.Equal(t, "true", res.Header().Get(idempotency.ReplayedHeader))
+
+	res = pay(`{"amount": 20}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Contains(t, res.Body.String(), `"code":"request_mismatch"`)
+
+	assert.Equal(t, 1, payments)
+
+	// Records are out of reach of key requests.
+	req := httptest.NewRequest("GET", "http://any-host/get", strings.NewReader(`{"key": "pay-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	out := httptest.NewRecorder()
+	server.Config.Handler.ServeHTTP(out, req)
+	assert.Equal(t, http.StatusNotFound, out.Code)
+
+	res = pay(`{"amount": 10}`)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "true", res.Header().Get(idempotency.ReplayedHeader))
+	assert.Equal(t, 1, payments)
+
+	// Server endpoints validate requests.
+	req = httptest.NewRequest("POST", "http://any-host/v1/idempotency/pay-2/complete", strings.NewReader(`{"fingerprint": "f", "ttl": "1h"}`))
+	req.Header.Set("Content-Type", "application/json")
+	out = httptest.NewRecorder()
+	server.Config.Handler.ServeHTTP(out, req)
+	assert.Equal(t, http.StatusBadRequest, out.Code)
+	assert.Contains(t, out.Body.String(), `"field":"response"`)
+}
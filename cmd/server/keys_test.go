@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func Test_PutGetKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		contentType string
+		body        []byte
+		expect      string
+	}{
+		{
+			name:        "raw",
+			key:         "image",
+			contentType: "image/png",
+			body:        []byte{0x89, 'P', 'N', 'G', 0, 1},
+			expect:      "image/png",
+		},
+		{
+			name:        "octet stream",
+			key:         "blob",
+			contentType: "application/octet-stream",
+			body:        []byte{0, 0xff},
+			expect:      "application/octet-stream",
+		},
+	}
+
+	handler := httpMux(storage.New(), options{keyLiveTime: time.Minute})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "http://any-host/v1/keys/"+tt.key, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+			assert.Equal(t, http.StatusOK, res.Code)
+
+			req = httptest.NewRequest("GET", "http://any-host/v1/keys/"+tt.key, nil)
+			res = httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, tt.expect, res.Header().Get("Content-Type"))
+			assert.Equal(t, tt.body, res.Body.Bytes())
+		})
+	}
+}
+
+func Test_PutGetKeys_json(t *testing.T) {
+	handler := httpMux(storage.New(), options{keyLiveTime: time.Minute})
+
+	req := httptest.NewRequest("PUT", "http://any-host/v1/keys/doc", bytes.NewReader([]byte(`{"name": "value"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	req = httptest.NewRequest("GET", "http://any-host/v1/keys/doc", nil)
+	res = httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+
+	schema := gojsonschema.NewStringLoader(`{"type":"object", "required": ["message", "data"], "properties": {"data": {"type": "object", "required": ["value"], "properties": {"value": {"type": "object", "required": ["name"], "properties": {"name": {"enum": ["value"]}}}}}}}`)
+	result, err := gojsonschema.Validate(schema, gojsonschema.NewStringLoader(res.Body.String()))
+	assert.Nil(t, err)
+	assert.True(t, result.Valid())
+
+	req = httptest.NewRequest("GET", "http://any-host/v1/keys/missing", nil)
+	res = httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
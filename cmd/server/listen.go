@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// listen returns listener of the address with tcp:// or
+// unix:// scheme, address without scheme is TCP one.
+func listen(addr string) (net.Listener, error) {
+	network, address := "tcp", addr
+	if i := strings.Index(addr, "://"); i >= 0 {
+		network, address = addr[:i], addr[i+3:]
+	}
+
+	switch network {
+	case "tcp":
+	case "unix":
+		if err := removeStaleSocket(address); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q of address %q", network, addr)
+	}
+
+	if address == "" {
+		return nil, fmt.Errorf("empty address %q", addr)
+	}
+
+	return net.Listen(network, address)
+}
+
+// removeStaleSocket removes socket file left by the
+// previous process, socket in use is not removed.
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %q is in use", path)
+	}
+
+	return os.Remove(path)
+}
+
+// listenAll returns listeners of comma separated
+// addresses, on error opened ones are closed.
+func listenAll(addrs string) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, addr := range strings.Split(addrs, ",") {
+		l, err := listen(strings.TrimSpace(addr))
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+
+	return listeners, nil
+}
+
+// enableH2C makes server accept HTTP/2 without TLS, with
+// prior knowledge or upgrade. Shutdown of the server asks
+// HTTP/2 clients to go away.
+func enableH2C(srv *http.Server) error {
+	h2s := &http2.Server{}
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return err
+	}
+
+	srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+)
+
+func Test_listen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "integral")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		addr    string
+		network string
+		err     string
+	}{
+		{name: "without scheme", addr: "127.0.0.1:0", network: "tcp"},
+		{name: "tcp", addr: "tcp://127.0.0.1:0", network: "tcp"},
+		{name: "unix", addr: "unix://" + filepath.Join(dir, "integral.sock"), network: "unix"},
+		{name: "unsupported scheme", addr: "udp://127.0.0.1:0", err: `unsupported scheme "udp" of address "udp://127.0.0.1:0"`},
+		{name: "empty", addr: "unix://", err: `empty address "unix://"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := listen(tt.addr)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			defer l.Close()
+			assert.Equal(t, tt.network, l.Addr().Network())
+		})
+	}
+}
+
+func Test_listenStaleSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "integral")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "integral.sock")
+
+	l, err := listen("unix://" + path)
+	assert.Nil(t, err)
+
+	_, err = listen("unix://" + path)
+	assert.EqualError(t, err, fmt.Sprintf("socket %q is in use", path))
+
+	// Socket file stays as if process was killed.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	l, err = listen("unix://" + path)
+	assert.Nil(t, err)
+	l.Close()
+}
+
+func Test_serveListeners(t *testing.T) {
+	dir, err := ioutil.TempDir("", "integral")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "integral.sock")
+
+	listeners, err := listenAll("127.0.0.1:0, unix://" + path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	srv := http.Server{Handler: httpMux(storage.New(), options{keyLiveTime: time.Minute})}
+	assert.Nil(t, enableH2C(&srv))
+
+	done := make(chan error, len(listeners))
+	for _, l := range listeners {
+		l := l
+		go func() { done <- srv.Serve(l) }()
+	}
+
+	tcpAddr := listeners[0].Addr().String()
+	clients := []struct {
+		name   string
+		client *http.Client
+		proto  string
+	}{
+		{
+			name: "unix",
+			client: &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			}}},
+			proto: "HTTP/1.1",
+		},
+		{
+			name:   "tcp",
+			client: &http.Client{},
+			proto:  "HTTP/1.1",
+		},
+		{
+			name: "h2c",
+			client: &http.Client{Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+					return net.Dial(network, addr)
+				},
+			}},
+			proto: "HTTP/2.0",
+		},
+	}
+
+	for i, c := range clients {
+		key := fmt.Sprintf("key%d", i)
+		req, err := http.NewRequest("PUT", "http://"+tcpAddr+"/v1/keys/"+key, strings.NewReader("value"))
+		assert.Nil(t, err)
+		req.Header.Set("Content-Type", "text/plain")
+
+		res, err := c.client.Do(req)
+		if !assert.Nil(t, err, c.name) {
+			continue
+		}
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode, c.name)
+		assert.Equal(t, c.proto, res.Proto, c.name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, srv.Shutdown(ctx))
+
+	for range listeners {
+		assert.Equal(t, http.ErrServerClosed, <-done)
+	}
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
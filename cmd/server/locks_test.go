@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Locks(t *testing.T) {
+	handler := httpMux(storage.New(), options{
+		keyLiveTime: time.Minute,
+	})
+
+	post := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://any-host"+target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		return res
+	}
+
+	res := post("/v1/locks/job/acquire", `{"ttl": "30s"}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var acquired struct {
+		Data struct {
+			Token uint64 `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&acquired); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	token := acquired.Data.Token
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		expect int
+		result string
+	}{
+		{
+			name:   "held",
+			target: "/v1/locks/job/acquire",
+			body:   `{"ttl": "30s"}`,
+			expect: http.StatusConflict,
+			result: `"code":"lock_held"`,
+		},
+		{
+			name:   "renew with stale token",
+			target: "/v1/locks/job/renew",
+			body:   fmt.Sprintf(`{"token": %d, "ttl": "30s"}`, token+1),
+			expect: http.StatusConflict,
+			result: `"code":"lock_not_held"`,
+		},
+		{
+			name:   "renew",
+			target: "/v1/locks/job/renew",
+			body:   fmt.Sprintf(`{"token": %d, "ttl": "1m"}`, token),
+			expect: http.StatusOK,
+			result: fmt.Sprintf(`"token":%d`, token),
+		},
+		{
+			name:   "release",
+			target: "/v1/locks/job/release",
+			body:   fmt.Sprintf(`{"token": %d}`, token),
+			expect: http.StatusOK,
+		},
+		{
+			name:   "released",
+			target: "/v1/locks/job/release",
+			body:   fmt.Sprintf(`{"token": %d}`, token),
+			expect: http.StatusConflict,
+		},
+		{
+			name:   "acquire with greater token",
+			target: "/v1/locks/job/acquire",
+			body:   `{"ttl": "30s"}`,
+			expect: http.StatusOK,
+			result: fmt.Sprintf(`"token":%d`, token+1),
+		},
+	}
+
+	// Cases share the lock, so they run in order.
+	for _, tt := range tests {
+		res := post(tt.target, tt.body)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+		assert.Contains(t, res.Body.String(), tt.result, tt.name)
+	}
+}
@@ -1,48 +1,286 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"flag"
+	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/romanyx/integral_db/internal/acl"
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/certs"
+	"github.com/romanyx/integral_db/internal/decode"
 	"github.com/romanyx/integral_db/internal/get"
+	"github.com/romanyx/integral_db/internal/hash"
+	"github.com/romanyx/integral_db/internal/idempotency"
+	"github.com/romanyx/integral_db/internal/keys"
+	"github.com/romanyx/integral_db/internal/locks"
+	"github.com/romanyx/integral_db/internal/memcache"
+	"github.com/romanyx/integral_db/internal/namespace"
+	"github.com/romanyx/integral_db/internal/object"
+	"github.com/romanyx/integral_db/internal/patch"
+	"github.com/romanyx/integral_db/internal/ratelimit"
+	"github.com/romanyx/integral_db/internal/resp"
+	"github.com/romanyx/integral_db/internal/rpc"
+	"github.com/romanyx/integral_db/internal/rpc/integralpb"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/semaphores"
 	"github.com/romanyx/integral_db/internal/set"
+	"github.com/romanyx/integral_db/internal/sets"
 	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/romanyx/integral_db/internal/zsets"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
 	shutdownTimeout = 30 * time.Second
 	readTimeout     = 15 * time.Second
 	writeTimeout    = 15 * time.Second
+
+	// rateLimitNamespace keeps rate limit counters,
+	// it is not a valid name of tenant namespaces.
+	rateLimitNamespace = "#ratelimit"
 )
 
 func main() {
 	var (
-		httpAddr    = flag.String("http", "0.0.0.0:80", "HTTP service address.")
+		httpAddrs   = flag.String("http", "0.0.0.0:80", "Comma separated HTTP service addresses, with tcp:// or unix:// scheme.")
+		h2cEnabled  = flag.Bool("h2c", false, "Serve HTTP/2 without TLS.")
+		tlsCert     = flag.String("tls-cert", "", "TLS certificate file of HTTP and gRPC services, plaintext when empty.")
+		tlsKey      = flag.String("tls-key", "", "TLS key file of the certificate.")
+		tlsClientCA = flag.String("tls-client-ca", "", "CA bundle file verifying required client certificates, not required when empty.")
+		apiKeysPath = flag.String("api-keys", "", "JSON file with API keys by principal names.")
+		jwtSecret   = flag.String("jwt-secret-file", "", "File with HMAC secret of HS256 bearer tokens.")
+		aclPath     = flag.String("acl", "", "JSON file with access rules of principals, all access is allowed when empty.")
+		nsFrom      = flag.String("namespace-from", "", "Namespace source of HTTP requests: header, path or principal, disabled when empty.")
+		nsPath      = flag.String("namespaces", "", "JSON file with configs of served namespaces, any namespace is served when empty.")
+		rateLimits  = flag.String("rate-limits", "", "JSON file with rate limit rules of HTTP clients, not limited when empty.")
+		respAddr    = flag.String("resp", "", "Redis protocol service address, disabled when empty.")
+		respIdle    = flag.Duration("resp-idle-timeout", 5*time.Minute, "Idle time after which Redis protocol connections are closed, zero disables.")
+		grpcAddr    = flag.String("grpc", "", "gRPC service address, disabled when empty.")
+		memcAddr    = flag.String("memcache", "", "Memcached protocol service address, disabled when empty.")
+		memcIdle    = flag.Duration("memcache-idle-timeout", 5*time.Minute, "Idle time after which memcached protocol connections are closed, zero disables.")
 		keyLiveTime = flag.Duration("key-live-time", time.Second*30, "key liveness time")
+		schemasPath = flag.String("schemas", "", "JSON file with value schemas by key prefixes.")
+
+		maxKeyLength     = flag.Int("max-key-length", 0, "Maximum key length in characters, 0 means no limit.")
+		keyPattern       = flag.String("key-pattern", "", "Regular expression keys must match.")
+		reservedPrefixes = flag.String("reserved-prefixes", "", "Comma separated key prefixes reserved for internal use.")
+		maxValueSize     = flag.Int("max-value-size", 0, "Maximum JSON encoded value size in bytes, 0 means no limit.")
+		maxBodySize      = flag.Int64("max-body-size", decode.DefaultMaxBodySize, "Maximum request body size in bytes.")
 	)
 
 	flag.Parse()
 
+	rules := validate.Rules{
+		MaxKeyLength: *maxKeyLength,
+		MaxValueSize: *maxValueSize,
+	}
+	if *keyPattern != "" {
+		re, err := regexp.Compile(*keyPattern)
+		if err != nil {
+			log.Fatalf("invalid key pattern: %v", err)
+		}
+		rules.KeyPattern = re
+	}
+	if *reservedPrefixes != "" {
+		rules.ReservedPrefixes = strings.Split(*reservedPrefixes, ",")
+	}
+
+	schemas := schema.NewRegistry()
+	if *schemasPath != "" {
+		var err error
+		if schemas, err = schema.Load(*schemasPath); err != nil {
+			log.Fatalf("could not load schemas: %v", err)
+		}
+	}
+
+	// Requests are authenticated when API
+	// keys or token secret are configured.
+	var authenticator *auth.Authenticator
+	if *apiKeysPath != "" || *jwtSecret != "" {
+		var keys map[string]string
+		if *apiKeysPath != "" {
+			var err error
+			if keys, err = auth.LoadKeys(*apiKeysPath); err != nil {
+				log.Fatalf("could not load api keys: %v", err)
+			}
+		}
+
+		var secret []byte
+		if *jwtSecret != "" {
+			b, err := ioutil.ReadFile(*jwtSecret)
+			if err != nil {
+				log.Fatalf("could not read jwt secret: %v", err)
+			}
+			if secret = bytes.TrimSpace(b); len(secret) == 0 {
+				log.Fatal("jwt secret is empty")
+			}
+		}
+
+		authenticator = auth.NewAuthenticator(keys, secret)
+	}
+
+	var accessList *acl.List
+	if *aclPath != "" {
+		var err error
+		if accessList, err = acl.Load(*aclPath); err != nil {
+			log.Fatalf("could not load acl: %v", err)
+		}
+	}
+
+	// Other protocols serve the root storage without
+	// credentials, so they would bypass HTTP access control.
+	if authenticator != nil || accessList != nil {
+		if *respAddr != "" || *memcAddr != "" || *grpcAddr != "" {
+			log.Fatal("resp, memcache and grpc services can't be served with api-keys, jwt-secret-file or acl")
+		}
+	}
+
+	var (
+		nsSource   namespace.Source
+		namespaces *namespace.Registry
+	)
+	if *nsFrom != "" {
+		var err error
+		if nsSource, err = namespace.ParseSource(*nsFrom); err != nil {
+			log.Fatalf("invalid namespace source: %v", err)
+		}
+		if nsSource == namespace.SourcePrincipal && authenticator == nil {
+			log.Fatal("principal namespaces require api-keys or jwt-secret-file")
+		}
+		// Authenticated clients select only namespaces
+		// allowed to them, so the served ones must be known.
+		if nsSource != namespace.SourcePrincipal && authenticator != nil && *nsPath == "" {
+			log.Fatal("header and path namespaces with authentication require namespaces file")
+		}
+		if *nsPath != "" {
+			if namespaces, err = namespace.Load(*nsPath); err != nil {
+				log.Fatalf("could not load namespaces: %v", err)
+			}
+		}
+	}
+
+	// Certificate files are reloaded on change,
+	// so they can be rotated without restart.
+	var tlsConfig *tls.Config
+	if *tlsCert != "" || *tlsKey != "" || *tlsClientCA != "" {
+		if *tlsCert == "" || *tlsKey == "" {
+			log.Fatal("tls-cert and tls-key must be set together")
+		}
+		if *h2cEnabled {
+			log.Fatal("h2c can't be served with TLS")
+		}
+
+		reloader, err := certs.NewReloader(*tlsCert, *tlsKey, *tlsClientCA)
+		if err != nil {
+			log.Fatalf("could not load certificates: %v", err)
+		}
+		tlsConfig = reloader.Config()
+	}
+
 	errChan := make(chan error)
+	store := storage.New()
+
+	var limiter *ratelimit.Limiter
+	if *rateLimits != "" {
+		rules, err := ratelimit.Load(*rateLimits)
+		if err != nil {
+			log.Fatalf("could not load rate limits: %v", err)
+		}
+		if limiter, err = ratelimit.New(store.Namespace(rateLimitNamespace), rules); err != nil {
+			log.Fatalf("invalid rate limits: %v", err)
+		}
+	}
 
 	httpServer := http.Server{
 		ReadTimeout:    readTimeout,
 		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
-		Handler:        httpMux(storage.New(), *keyLiveTime),
-		Addr:           *httpAddr,
+		Handler: httpMux(store, options{
+			keyLiveTime: *keyLiveTime,
+			rules:       rules,
+			schemas:     schemas,
+			maxBodySize: *maxBodySize,
+			auth:        authenticator,
+			acl:         accessList,
+
+			namespaceFrom: nsSource,
+			namespaces:    namespaces,
+			rateLimit:     limiter,
+		}),
+		TLSConfig:   tlsConfig,
+		ConnContext: ratelimit.ConnContext,
+	}
+	if *h2cEnabled {
+		if err := enableH2C(&httpServer); err != nil {
+			log.Fatalf("could not enable h2c: %v", err)
+		}
+	}
+
+	// All listeners are served by the same server,
+	// so they are shut down gracefully together.
+	listeners, err := listenAll(*httpAddrs)
+	if err != nil {
+		log.Fatalf("could not listen http address: %v", err)
+	}
+	for _, l := range listeners {
+		l := l
+		go func() {
+			if tlsConfig != nil {
+				errChan <- httpServer.ServeTLS(l, "", "")
+				return
+			}
+			errChan <- httpServer.Serve(l)
+		}()
 	}
 
-	go func() {
-		errChan <- httpServer.ListenAndServe()
-	}()
+	// RESP server shares the storage,
+	// so keys are visible to both APIs.
+	respServer := resp.NewServer(store, *keyLiveTime, rules, schemas)
+	respServer.IdleTimeout = *respIdle
+	if *respAddr != "" {
+		go func() {
+			errChan <- respServer.ListenAndServe(*respAddr)
+		}()
+	}
+
+	memcServer := memcache.NewServer(store, *keyLiveTime, rules, schemas)
+	memcServer.IdleTimeout = *memcIdle
+	if *memcAddr != "" {
+		go func() {
+			errChan <- memcServer.ListenAndServe(*memcAddr)
+		}()
+	}
+
+	var grpcOpts []grpc.ServerOption
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(grpcOpts...)
+	integralpb.RegisterIntegralServer(grpcServer, rpc.NewServer(store, *keyLiveTime, rules, schemas))
+	if *grpcAddr != "" {
+		l, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatalf("could not listen grpc address: %v", err)
+		}
+		go func() {
+			errChan <- grpcServer.Serve(l)
+		}()
+	}
 
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
@@ -56,26 +294,205 @@ func main() {
 		case s := <-signalChan:
 			log.Printf("captured %v. exiting...", s)
 
+			if err := respServer.Close(); err != nil {
+				log.Printf("could not stop resp server: %v", err)
+			}
+			if err := memcServer.Close(); err != nil {
+				log.Printf("could not stop memcache server: %v", err)
+			}
+
 			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 			defer cancel()
 
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+
 			if err := httpServer.Shutdown(ctx); err != nil {
 				log.Printf("graceful shutdown did not complete in %v : %v", shutdownTimeout, err)
 				if err := httpServer.Close(); err != nil {
 					log.Fatalf("could not stop http server: %v", err)
 				}
 			}
+
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				log.Printf("graceful grpc shutdown did not complete in %v", shutdownTimeout)
+				grpcServer.Stop()
+			}
 		}
 	}
 }
 
-func httpMux(s storage.Storage, keyLiveTime time.Duration) http.Handler {
+// options configures services of the HTTP mux.
+type options struct {
+	keyLiveTime time.Duration
+	rules       validate.Rules
+	schemas     *schema.Registry
+	// maxBodySize limits request bodies,
+	// zero means the default limit.
+	maxBodySize int64
+	// auth authenticates requests,
+	// nil allows anonymous ones.
+	auth *auth.Authenticator
+	// acl authorizes requests,
+	// nil allows all of them.
+	acl *acl.List
+	// namespaceFrom selects namespaces of requests,
+	// empty serves all of them in the root storage.
+	namespaceFrom namespace.Source
+	// namespaces configures served namespaces,
+	// nil serves any of them with defaults.
+	namespaces *namespace.Registry
+	// rateLimit limits requests of clients,
+	// nil doesn't limit them.
+	rateLimit *ratelimit.Limiter
+}
+
+func httpMux(s storage.Storage, opts options) http.Handler {
+	if opts.schemas == nil {
+		opts.schemas = schema.NewRegistry()
+	}
+
 	mux := mux.NewRouter()
+	mux.Use(decode.MaxBodySize(opts.maxBodySize))
+	mux.Use(certs.Middleware)
+	if opts.rateLimit != nil {
+		mux.Use(opts.rateLimit.Middleware)
+	}
+	if opts.auth != nil {
+		mux.Use(auth.Middleware(opts.auth))
+		if opts.rateLimit != nil {
+			mux.Use(opts.rateLimit.PrincipalMiddleware)
+		}
+	}
+
+	// guard checks access of the principal
+	// to the target before the handler runs.
+	guard := func(target acl.Target, h http.Handler) http.Handler {
+		if opts.acl == nil {
+			return h
+		}
+		return opts.acl.Middleware(target)(h)
+	}
+
+	schemaSrv := schema.NewService(opts.schemas)
+	mux.Handle("/admin/schemas", guard(acl.NoKey(acl.Admin), schema.NewListHandler(schemaSrv))).Methods("GET")
+	mux.Handle("/admin/schemas", guard(acl.NoKey(acl.Admin), schema.NewRegisterHandler(schemaSrv))).Methods("POST")
+	mux.Handle("/admin/schemas", guard(acl.NoKey(acl.Admin), schema.NewDeleteHandler(schemaSrv))).Methods("DELETE")
+
+	if opts.namespaceFrom == "" {
+		routes(mux, s, opts, guard)
+		return mux
+	}
 
-	postSet := set.NewHandler(set.NewService(s, keyLiveTime))
-	mux.HandleFunc("/set", postSet).Methods("POST")
-	getGet := get.NewHandler(get.NewService(s))
-	mux.HandleFunc("/get", getGet).Methods("GET")
+	if opts.namespaces == nil {
+		opts.namespaces, _ = namespace.NewRegistry(nil)
+	}
+
+	namespaceSrv := namespace.NewService(s)
+	mux.Handle("/admin/namespaces/{namespace}", guard(acl.NoKey(acl.Admin), namespace.NewStatsHandler(namespaceSrv))).Methods("GET")
+	mux.Handle("/admin/namespaces/{namespace}", guard(acl.NoKey(acl.Admin), namespace.NewFlushHandler(namespaceSrv))).Methods("DELETE")
+
+	// Keyspace routes of every namespace are built
+	// on its first request with its own defaults.
+	build := func(ns storage.Storage, c namespace.Config) http.Handler {
+		nsOpts := opts
+		if c.KeyLiveTime > 0 {
+			nsOpts.keyLiveTime = c.KeyLiveTime
+		}
+		if c.MaxKeyLength > 0 {
+			nsOpts.rules.MaxKeyLength = c.MaxKeyLength
+		}
+		if c.MaxValueSize > 0 {
+			nsOpts.rules.MaxValueSize = c.MaxValueSize
+		}
+		// Keys set without explicit lifetime
+		// must fit the lifetime quota.
+		if c.MaxTTL > 0 && nsOpts.keyLiveTime > c.MaxTTL {
+			nsOpts.keyLiveTime = c.MaxTTL
+		}
+
+		ns.SetQuota(storage.Quota{
+			MaxKeys:  c.MaxKeys,
+			MaxBytes: c.MaxBytes,
+			MaxTTL:   c.MaxTTL,
+		})
+
+		return keyspace(ns, nsOpts, guard)
+	}
+
+	root := keyspace(s, opts, guard)
+	mux.PathPrefix("/").Handler(namespace.NewDispatcher(s, opts.namespaceFrom, opts.namespaces, root, build))
 
 	return mux
 }
+
+// keyspace returns router of the keyspace routes.
+func keyspace(s storage.Storage, opts options, guard func(acl.Target, http.Handler) http.Handler) http.Handler {
+	router := mux.NewRouter()
+	routes(router, s, opts, guard)
+	return router
+}
+
+// routes registers keyspace routes serving the storage.
+func routes(mux *mux.Router, s storage.Storage, opts options, guard func(acl.Target, http.Handler) http.Handler) {
+	keyLiveTime := opts.keyLiveTime
+
+	postSet := set.NewHandler(set.NewService(s, keyLiveTime, opts.rules, opts.schemas))
+	mux.Handle("/set", guard(acl.BodyKeys(acl.Write), postSet)).Methods("POST")
+	getGet := get.NewHandler(get.NewService(s, opts.rules))
+	mux.Handle("/get", guard(acl.BodyGet(), getGet)).Methods("GET")
+
+	hashSrv := hash.NewService(s, keyLiveTime, opts.rules)
+	mux.Handle("/hset", guard(acl.BodyKeys(acl.Write), hash.NewHSetHandler(hashSrv))).Methods("POST")
+	mux.Handle("/hget", guard(acl.BodyKeys(acl.Read), hash.NewHGetHandler(hashSrv))).Methods("GET")
+	mux.Handle("/hdel", guard(acl.BodyKeys(acl.Write), hash.NewHDelHandler(hashSrv))).Methods("POST")
+	mux.Handle("/hgetall", guard(acl.BodyKeys(acl.Read), hash.NewHGetAllHandler(hashSrv))).Methods("GET")
+	mux.Handle("/hincrby", guard(acl.BodyKeys(acl.Write), hash.NewHIncrByHandler(hashSrv))).Methods("POST")
+
+	setsSrv := sets.NewService(s, keyLiveTime, opts.rules)
+	mux.Handle("/sadd", guard(acl.BodyKeys(acl.Write), sets.NewSAddHandler(setsSrv))).Methods("POST")
+	mux.Handle("/srem", guard(acl.BodyKeys(acl.Write), sets.NewSRemHandler(setsSrv))).Methods("POST")
+	mux.Handle("/sismember", guard(acl.BodyKeys(acl.Read), sets.NewSIsMemberHandler(setsSrv))).Methods("GET")
+	mux.Handle("/smembers", guard(acl.BodyKeys(acl.Read), sets.NewSMembersHandler(setsSrv))).Methods("GET")
+	mux.Handle("/sinter", guard(acl.BodyKeys(acl.Read), sets.NewSInterHandler(setsSrv))).Methods("GET")
+	mux.Handle("/sunion", guard(acl.BodyKeys(acl.Read), sets.NewSUnionHandler(setsSrv))).Methods("GET")
+
+	zsetsSrv := zsets.NewService(s, keyLiveTime, opts.rules)
+	mux.Handle("/zadd", guard(acl.BodyKeys(acl.Write), zsets.NewZAddHandler(zsetsSrv))).Methods("POST")
+	mux.Handle("/zrange", guard(acl.BodyKeys(acl.Read), zsets.NewZRangeHandler(zsetsSrv))).Methods("GET")
+	mux.Handle("/zrangebyscore", guard(acl.BodyKeys(acl.Read), zsets.NewZRangeByScoreHandler(zsetsSrv))).Methods("GET")
+	mux.Handle("/zrank", guard(acl.BodyKeys(acl.Read), zsets.NewZRankHandler(zsetsSrv))).Methods("GET")
+
+	objectSrv := object.NewService(s, opts.rules)
+	mux.Handle("/type", guard(acl.BodyKeys(acl.Read), object.NewTypeHandler(objectSrv))).Methods("GET")
+	mux.Handle("/object", guard(acl.BodyKeys(acl.Read), object.NewObjectHandler(objectSrv))).Methods("GET")
+
+	keysSrv := keys.NewService(s, keyLiveTime, opts.rules, opts.schemas)
+	mux.Handle("/v1/keys/{key}", guard(acl.PathKey(acl.Write), keys.NewPutHandler(keysSrv))).Methods("PUT")
+	mux.Handle("/v1/keys/{key}", guard(acl.PathKey(acl.Read), keys.NewGetHandler(keysSrv))).Methods("GET")
+
+	patchKey := patch.NewHandler(patch.NewService(s, opts.rules, opts.schemas))
+	mux.Handle("/v1/keys/{key}", guard(acl.PathKey(acl.Write), patchKey)).Methods("PATCH")
+
+	throttleSrv := ratelimit.NewService(s, opts.rules)
+	mux.Handle("/v1/ratelimit/{key}", guard(acl.PathKey(acl.Write), ratelimit.NewHandler(throttleSrv))).Methods("POST")
+
+	locksSrv := locks.NewService(s, opts.rules)
+	mux.Handle("/v1/locks/{key}/acquire", guard(acl.PathKey(acl.Write), locks.NewAcquireHandler(locksSrv))).Methods("POST")
+	mux.Handle("/v1/locks/{key}/renew", guard(acl.PathKey(acl.Write), locks.NewRenewHandler(locksSrv))).Methods("POST")
+	mux.Handle("/v1/locks/{key}/release", guard(acl.PathKey(acl.Write), locks.NewReleaseHandler(locksSrv))).Methods("POST")
+
+	semaphoresSrv := semaphores.NewService(s, opts.rules)
+	mux.Handle("/v1/semaphores/{key}/acquire", guard(acl.PathKey(acl.Write), semaphores.NewAcquireHandler(semaphoresSrv))).Methods("POST")
+	mux.Handle("/v1/semaphores/{key}/release", guard(acl.PathKey(acl.Write), semaphores.NewReleaseHandler(semaphoresSrv))).Methods("POST")
+
+	idempotencySrv := idempotency.NewService(s, opts.rules)
+	mux.Handle("/v1/idempotency/{key}/begin", guard(acl.PathKey(acl.Write), idempotency.NewBeginHandler(idempotencySrv))).Methods("POST")
+	mux.Handle("/v1/idempotency/{key}/complete", guard(acl.PathKey(acl.Write), idempotency.NewCompleteHandler(idempotencySrv))).Methods("POST")
+	mux.Handle("/v1/idempotency/{key}/abort", guard(acl.PathKey(acl.Write), idempotency.NewAbortHandler(idempotencySrv))).Methods("POST")
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/namespace"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Namespaces(t *testing.T) {
+	registry, err := namespace.NewRegistry(map[string]namespace.Config{
+		"a": {},
+		"b": {MaxValueSize: 4},
+	})
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	handler := httpMux(storage.New(), options{
+		keyLiveTime:   time.Minute,
+		namespaceFrom: namespace.SourcePath,
+		namespaces:    registry,
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		expect int
+		result string
+	}{
+		{
+			name:   "set in namespace",
+			method: "PUT",
+			target: "/ns/a/v1/keys/k",
+			body:   `"a"`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "set in root",
+			method: "PUT",
+			target: "/v1/keys/k",
+			body:   `"root"`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "namespace value",
+			method: "GET",
+			target: "/ns/a/v1/keys/k",
+			expect: http.StatusOK,
+			result: `"a"`,
+		},
+		{
+			name:   "isolated",
+			method: "GET",
+			target: "/ns/b/v1/keys/k",
+			expect: http.StatusNotFound,
+		},
+		{
+			name:   "namespace limit",
+			method: "PUT",
+			target: "/ns/b/v1/keys/k",
+			body:   `"long value"`,
+			expect: http.StatusBadRequest,
+		},
+		{
+			name:   "not served",
+			method: "GET",
+			target: "/ns/c/v1/keys/k",
+			expect: http.StatusNotFound,
+			result: `"code":"namespace_not_found"`,
+		},
+		{
+			name:   "stats",
+			method: "GET",
+			target: "/admin/namespaces/a",
+			expect: http.StatusOK,
+			result: `"keys":1`,
+		},
+		{
+			name:   "flush",
+			method: "DELETE",
+			target: "/admin/namespaces/a",
+			expect: http.StatusOK,
+			result: `"deleted":1`,
+		},
+		{
+			name:   "flushed",
+			method: "GET",
+			target: "/ns/a/v1/keys/k",
+			expect: http.StatusNotFound,
+		},
+		{
+			name:   "root kept",
+			method: "GET",
+			target: "/v1/keys/k",
+			expect: http.StatusOK,
+			result: `"root"`,
+		},
+	}
+
+	// Cases share the storage, so they run in order.
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "http://any-host"+tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+		assert.Contains(t, res.Body.String(), tt.result, tt.name)
+	}
+}
+
+func Test_Quotas(t *testing.T) {
+	registry, err := namespace.NewRegistry(map[string]namespace.Config{
+		"tenant": {MaxKeys: 2, MaxBytes: 8, MaxTTL: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	handler := httpMux(storage.New(), options{
+		keyLiveTime:   time.Minute,
+		namespaceFrom: namespace.SourceHeader,
+		namespaces:    registry,
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		expect int
+		result string
+	}{
+		{
+			name:   "lock ttl quota",
+			target: "/v1/locks/job/acquire",
+			body:   `{"ttl": "2h"}`,
+			expect: http.StatusTooManyRequests,
+			result: `"code":"ttl_quota_exceeded"`,
+		},
+		{
+			name:   "first key",
+			body:   `{"key": "a", "value": "v"}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "hash bytes quota",
+			target: "/hset",
+			body:   `{"key": "h", "field": "field", "value": "long value"}`,
+			expect: http.StatusInsufficientStorage,
+			result: `"code":"bytes_quota_exceeded"`,
+		},
+		{
+			name:   "bytes quota",
+			body:   `{"key": "b", "value": "long value"}`,
+			expect: http.StatusInsufficientStorage,
+			result: `"code":"bytes_quota_exceeded"`,
+		},
+		{
+			name:   "second key",
+			body:   `{"key": "b", "value": "v"}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "keys quota",
+			body:   `{"key": "c", "value": "v"}`,
+			expect: http.StatusTooManyRequests,
+			result: `"code":"keys_quota_exceeded"`,
+		},
+		{
+			name:   "replaced key",
+			body:   `{"key": "a", "value": "value"}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "set keys quota",
+			target: "/sadd",
+			body:   `{"key": "s", "members": ["m"]}`,
+			expect: http.StatusTooManyRequests,
+			result: `"code":"keys_quota_exceeded"`,
+		},
+		{
+			name:   "lock keys quota",
+			target: "/v1/locks/job/acquire",
+			body:   `{"ttl": "30s"}`,
+			expect: http.StatusTooManyRequests,
+			result: `"code":"keys_quota_exceeded"`,
+		},
+	}
+
+	// Cases share the storage, so they run in order.
+	for _, tt := range tests {
+		target := tt.target
+		if target == "" {
+			target = "/set"
+		}
+
+		req := httptest.NewRequest("POST", "http://any-host"+target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(namespace.Header, "tenant")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+		assert.Contains(t, res.Body.String(), tt.result, tt.name)
+	}
+}
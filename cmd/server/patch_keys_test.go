@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func Test_PatchKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		contentType string
+		body        string
+		code        int
+		schema      string
+	}{
+		{
+			name:        "merge patch",
+			key:         "merge",
+			contentType: "application/merge-patch+json",
+			body:        `{"name": "new", "old": null}`,
+			code:        http.StatusOK,
+			schema:      `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["value"], "properties": {"value": {"type": "object", "required": ["name"], "properties": {"name": {"enum": ["new"]}}, "not": {"required": ["old"]}}}}}}`,
+		},
+		{
+			name:        "json patch",
+			key:         "json",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "add", "path": "/tags/-", "value": "b"}]`,
+			code:        http.StatusOK,
+			schema:      `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["value"], "properties": {"value": {"type": "object", "properties": {"tags": {"type": "array", "minItems": 2}}}}}}}`,
+		},
+		{
+			name:        "invalid operation",
+			key:         "json",
+			contentType: "application/json-patch+json",
+			body:        `[{"op": "remove", "path": "/missing"}]`,
+			code:        http.StatusUnprocessableEntity,
+			schema:      `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [422]}, "code": {"enum": ["patch_failed"]}}}`,
+		},
+		{
+			name:        "schema violation",
+			key:         "user:1",
+			contentType: "application/merge-patch+json",
+			body:        `{"name": null}`,
+			code:        http.StatusBadRequest,
+			schema:      `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"status": {"enum": [400]}, "code": {"enum": ["validation_failed"]}}}`,
+		},
+		{
+			name:        "raw value",
+			key:         "blob",
+			contentType: "application/merge-patch+json",
+			body:        `{"name": "new"}`,
+			code:        http.StatusConflict,
+			schema:      `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [409]}, "code": {"enum": ["wrong_type"]}}}`,
+		},
+		{
+			name:        "not found",
+			key:         "missing",
+			contentType: "application/merge-patch+json",
+			body:        `{}`,
+			code:        http.StatusNotFound,
+			schema:      `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [404]}, "code": {"enum": ["key_not_found"]}}}`,
+		},
+		{
+			name:        "unsupported media type",
+			key:         "json",
+			contentType: "text/plain",
+			body:        `{}`,
+			code:        http.StatusUnsupportedMediaType,
+			schema:      `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [415]}, "code": {"enum": ["unsupported_media_type"]}}}`,
+		},
+	}
+
+	s := storage.New()
+	s.Set(storage.Expire(time.Minute), "merge", map[string]interface{}{"name": "old", "old": true})
+	s.Set(storage.Expire(time.Minute), "json", map[string]interface{}{"tags": []interface{}{"a"}})
+	s.Set(storage.Expire(time.Minute), "user:1", map[string]interface{}{"name": "old"})
+	s.Set(storage.Expire(time.Minute), "blob", storage.Blob{ContentType: "application/octet-stream", Data: []byte{0xff}})
+
+	schemas := schema.NewRegistry()
+	assert.Nil(t, schemas.Register("user:", map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name"},
+	}))
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			handler := httpMux(s, options{keyLiveTime: time.Second, schemas: schemas})
+			s := httptest.NewServer(handler)
+			defer s.Close()
+
+			req := httptest.NewRequest("PATCH", fmt.Sprintf("%s/v1/keys/%s", s.URL, tt.key), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+
+			schema := gojsonschema.NewStringLoader(tt.schema)
+			doc := gojsonschema.NewStringLoader(res.Body.String())
+
+			result, err := gojsonschema.Validate(schema, doc)
+
+			assert.Nil(t, err)
+			assert.True(t, result.Valid())
+			assert.Empty(t, result.Errors())
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/romanyx/integral_db/internal/decode"
 	"github.com/romanyx/integral_db/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/xeipuuv/gojsonschema"
@@ -15,10 +16,11 @@ import (
 
 func Test_PostSet(t *testing.T) {
 	tests := []struct {
-		name   string
-		body   string
-		code   int
-		schema string
+		name        string
+		contentType string
+		body        string
+		code        int
+		schema      string
 	}{
 		{
 			name:   "ok",
@@ -28,9 +30,35 @@ func Test_PostSet(t *testing.T) {
 		},
 		{
 			name:   "validaion errors",
-			schema: `{"type":"object", "required": ["message", "errors"], "properties": {"message": {"type": "string"}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
+			body:   `{}`,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
 			code:   http.StatusBadRequest,
 		},
+		{
+			name:   "malformed body",
+			body:   `{"key": "key", "value": }`,
+			code:   http.StatusBadRequest,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "detail"], "properties": {"status": {"enum": [400]}, "code": {"enum": ["malformed_body"]}}}`,
+		},
+		{
+			name:   "unknown field",
+			body:   `{"key": "key", "val": "value"}`,
+			code:   http.StatusBadRequest,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "detail"], "properties": {"status": {"enum": [400]}, "code": {"enum": ["malformed_body"]}}}`,
+		},
+		{
+			name:        "unsupported media type",
+			contentType: "text/plain",
+			body:        `{"key": "key", "value": "value"}`,
+			code:        http.StatusUnsupportedMediaType,
+			schema:      `{"type":"object", "required": ["type", "title", "status", "code", "detail"], "properties": {"status": {"enum": [415]}, "code": {"enum": ["unsupported_media_type"]}}}`,
+		},
+		{
+			name:   "too large body",
+			body:   fmt.Sprintf(`{"key": "key", "value": "%s"}`, strings.Repeat("a", decode.DefaultMaxBodySize)),
+			code:   http.StatusRequestEntityTooLarge,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "detail"], "properties": {"status": {"enum": [413]}, "code": {"enum": ["body_too_large"]}}}`,
+		},
 	}
 
 	s := storage.New()
@@ -40,11 +68,14 @@ func Test_PostSet(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			handler := httpMux(s, time.Second)
+			handler := httpMux(s, options{keyLiveTime: time.Second})
 			s := httptest.NewServer(handler)
 			defer s.Close()
 
 			req := httptest.NewRequest("POST", fmt.Sprintf("%s/set", s.URL), strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
 			res := httptest.NewRecorder()
 
 			handler.ServeHTTP(res, req)
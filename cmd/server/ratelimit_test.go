@@ -0,0 +1,190 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/ratelimit"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimit(t *testing.T) {
+	store := storage.New()
+	limiter, err := ratelimit.New(store.Namespace(rateLimitNamespace), []ratelimit.Rule{
+		{Path: "/set", Limit: 1, Window: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("new limiter: %v", err)
+	}
+
+	handler := httpMux(store, options{
+		keyLiveTime: time.Minute,
+		rateLimit:   limiter,
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		expect int
+	}{
+		{
+			name:   "allowed",
+			method: "POST",
+			target: "/set",
+			body:   `{"key": "key", "value": "v"}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "limited",
+			method: "POST",
+			target: "/set",
+			body:   `{"key": "key", "value": "v"}`,
+			expect: http.StatusTooManyRequests,
+		},
+		{
+			name:   "other route",
+			method: "GET",
+			target: "/v1/keys/key",
+			expect: http.StatusOK,
+		},
+	}
+
+	// Cases share counters, so they run in order.
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "http://any-host"+tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+	}
+
+	assert.Equal(t, 1, store.Len())
+}
+
+func Test_RateLimitUnauthenticated(t *testing.T) {
+	store := storage.New()
+	limiter, err := ratelimit.New(store.Namespace(rateLimitNamespace), []ratelimit.Rule{
+		{Limit: 2, Window: time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("new limiter: %v", err)
+	}
+
+	handler := httpMux(store, options{
+		keyLiveTime: time.Minute,
+		auth:        auth.NewAuthenticator(map[string]string{"app": "key", "ops": "other"}, nil),
+		rateLimit:   limiter,
+	})
+
+	tests := []struct {
+		name   string
+		addr   string
+		apiKey string
+		expect int
+	}{
+		{name: "unauthorized", addr: "10.0.0.1:1", apiKey: "wrong", expect: http.StatusUnauthorized},
+		{name: "unauthorized again", addr: "10.0.0.1:1", apiKey: "wrong", expect: http.StatusUnauthorized},
+		{name: "address limited", addr: "10.0.0.1:1", apiKey: "wrong", expect: http.StatusTooManyRequests},
+		{name: "principal", addr: "10.0.0.2:1", apiKey: "key", expect: http.StatusNotFound},
+		{name: "principal again", addr: "10.0.0.3:1", apiKey: "key", expect: http.StatusNotFound},
+		{name: "principal limited", addr: "10.0.0.4:1", apiKey: "key", expect: http.StatusTooManyRequests},
+		{name: "other principal", addr: "10.0.0.4:1", apiKey: "other", expect: http.StatusNotFound},
+	}
+
+	// Cases share counters, so they run in order.
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "http://any-host/v1/keys/key", nil)
+		req.RemoteAddr = tt.addr
+		req.Header.Set("X-API-Key", tt.apiKey)
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+	}
+}
+
+func Test_RateLimitAPI(t *testing.T) {
+	store := storage.New()
+	handler := httpMux(store, options{
+		keyLiveTime: time.Minute,
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		expect int
+		result string
+	}{
+		{
+			name:   "allowed",
+			target: "/v1/ratelimit/api",
+			body:   `{"rate": 1, "period": "1h", "burst": 1}`,
+			expect: http.StatusOK,
+			result: `"allowed":true,"limit":1,"remaining":0`,
+		},
+		{
+			name:   "denied",
+			target: "/v1/ratelimit/api",
+			body:   `{"rate": 1, "period": "1h", "burst": 1}`,
+			expect: http.StatusOK,
+			result: `"retry_at"`,
+		},
+		{
+			name:   "invalid",
+			target: "/v1/ratelimit/api",
+			body:   `{"rate": 1, "period": "hourly", "burst": 1}`,
+			expect: http.StatusBadRequest,
+			result: `"field":"period"`,
+		},
+		{
+			name:   "rate over period",
+			target: "/v1/ratelimit/api",
+			body:   `{"rate": 2, "period": "1ns", "burst": 1}`,
+			expect: http.StatusBadRequest,
+			result: `"field":"period"`,
+		},
+		{
+			name:   "burst overflow",
+			target: "/v1/ratelimit/api",
+			body:   `{"rate": 1, "period": "1h", "burst": 1000000000}`,
+			expect: http.StatusBadRequest,
+			result: `"field":"burst"`,
+		},
+		{
+			name:   "set key",
+			target: "/set",
+			body:   `{"key": "key", "value": "v"}`,
+			expect: http.StatusOK,
+		},
+		{
+			name:   "wrong type",
+			target: "/v1/ratelimit/key",
+			body:   `{"rate": 1, "period": "1h", "burst": 1}`,
+			expect: http.StatusConflict,
+			result: `"code":"wrong_type"`,
+		},
+	}
+
+	// Cases share the storage, so they run in order.
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "http://any-host"+tt.target, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		assert.Equal(t, tt.expect, res.Code, tt.name)
+		assert.Contains(t, res.Body.String(), tt.result, tt.name)
+	}
+
+	// Limit state is a key of the keyspace.
+	assert.Equal(t, 2, store.Len())
+}
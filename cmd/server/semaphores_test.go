@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Semaphores(t *testing.T) {
+	handler := httpMux(storage.New(), options{
+		keyLiveTime: time.Minute,
+	})
+
+	post := func(target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "http://any-host"+target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+
+		handler.ServeHTTP(res, req)
+		return res
+	}
+
+	res := post("/v1/semaphores/exports/acquire", `{"limit": 1, "ttl": "1m"}`)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var acquired struct {
+		Data struct {
+			Permit uint64 `json:"permit"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&acquired); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	res = post("/v1/semaphores/exports/acquire", `{"limit": 1, "ttl": "1m", "timeout": "10ms"}`)
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Contains(t, res.Body.String(), `"code":"no_permits"`)
+
+	// Waiter gets the permit once it is released.
+	waited := make(chan *httptest.ResponseRecorder)
+	go func() {
+		waited <- post("/v1/semaphores/exports/acquire", `{"limit": 1, "ttl": "1m", "timeout": "5s"}`)
+	}()
+
+	res = post("/v1/semaphores/exports/release", fmt.Sprintf(`{"permit": %d}`, acquired.Data.Permit))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, http.StatusOK, (<-waited).Code)
+
+	res = post("/v1/semaphores/exports/release", fmt.Sprintf(`{"permit": %d}`, acquired.Data.Permit))
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Contains(t, res.Body.String(), `"code":"permit_not_held"`)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func Test_Sets(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		schema string
+	}{
+		{
+			name:   "sadd",
+			method: "POST",
+			path:   "/sadd",
+			body:   `{"key": "new", "members": ["a", "b"]}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["added"], "properties": {"added": {"type": "integer"}}}}}`,
+		},
+		{
+			name:   "sismember",
+			method: "GET",
+			path:   "/sismember",
+			body:   `{"key": "set", "member": "a"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["is_member"], "properties": {"is_member": {"type": "boolean"}}}}}`,
+		},
+		{
+			name:   "sunion",
+			method: "GET",
+			path:   "/sunion",
+			body:   `{"keys": ["set", "missing"]}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["members"], "properties": {"members": {"type": "array", "items": {"type": "string"}}}}}}`,
+		},
+		{
+			name:   "smembers not found",
+			method: "GET",
+			path:   "/smembers",
+			body:   `{"key": "missing"}`,
+			code:   http.StatusNotFound,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [404]}, "code": {"enum": ["key_not_found"]}}}`,
+		},
+		{
+			name:   "zadd",
+			method: "POST",
+			path:   "/zadd",
+			body:   `{"key": "newzset", "members": [{"member": "a", "score": 1.5}]}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["added"], "properties": {"added": {"type": "integer"}}}}}`,
+		},
+		{
+			name:   "zrangebyscore",
+			method: "GET",
+			path:   "/zrangebyscore",
+			body:   `{"key": "zset", "min": 1}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["members"], "properties": {"members": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["member", "score"]}}}}}}`,
+		},
+		{
+			name:   "zrank",
+			method: "GET",
+			path:   "/zrank",
+			body:   `{"key": "zset", "member": "a"}`,
+			code:   http.StatusOK,
+			schema: `{"type":"object", "required": ["message", "data"], "properties": {"message": {"type": "string"}, "data": {"type": "object", "required": ["rank"], "properties": {"rank": {"type": "integer"}}}}}`,
+		},
+		{
+			name:   "wrong type",
+			method: "GET",
+			path:   "/zrange",
+			body:   `{"key": "set"}`,
+			code:   http.StatusConflict,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code"], "properties": {"status": {"enum": [409]}, "code": {"enum": ["wrong_type"]}}}`,
+		},
+		{
+			name:   "validaion errors",
+			method: "GET",
+			path:   "/sinter",
+			body:   `{}`,
+			code:   http.StatusBadRequest,
+			schema: `{"type":"object", "required": ["type", "title", "status", "code", "errors"], "properties": {"code": {"enum": ["validation_failed"]}, "errors": {"type": "array", "items": {"type": "object", "required": ["field", "message"], "properties": {"field": {"type": "string"}, "message": {"type": "string"}}}}}}`,
+		},
+	}
+
+	s := storage.New()
+	s.SAdd(context.Background(), "set", "a", "b")
+	s.ZAdd(context.Background(), "zset", storage.ZMember{Member: "a", Score: 1})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := httpMux(s, options{keyLiveTime: time.Second})
+			s := httptest.NewServer(handler)
+			defer s.Close()
+
+			req := httptest.NewRequest(tt.method, fmt.Sprintf("%s%s", s.URL, tt.path), strings.NewReader(tt.body))
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+
+			schema := gojsonschema.NewStringLoader(tt.schema)
+			doc := gojsonschema.NewStringLoader(res.Body.String())
+
+			result, err := gojsonschema.Validate(schema, doc)
+
+			assert.Nil(t, err)
+			assert.True(t, result.Valid())
+			assert.Empty(t, result.Errors())
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package acl
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Operation is a kind of access to keys.
+type Operation string
+
+// Operations granted by rules.
+const (
+	// Read reads values without removing them.
+	Read Operation = "read"
+	// Consume reads values removing them.
+	Consume Operation = "consume"
+	// Write sets and modifies values.
+	Write Operation = "write"
+	// Delete removes keys.
+	Delete Operation = "delete"
+	// Scan lists keys.
+	Scan Operation = "scan"
+	// Admin manages the server, like
+	// schemas, it is checked for empty key.
+	Admin Operation = "admin"
+)
+
+var operations = map[Operation]bool{
+	Read:    true,
+	Consume: true,
+	Write:   true,
+	Delete:  true,
+	Scan:    true,
+	Admin:   true,
+}
+
+// AnyPrincipal matches every
+// principal, anonymous as well.
+const AnyPrincipal = "*"
+
+// Rule grants operations on keys
+// with the prefix to the principal.
+type Rule struct {
+	Principal  string      `json:"principal"`
+	Prefix     string      `json:"prefix"`
+	Operations []Operation `json:"operations"`
+}
+
+// List is a list of rules, operations not
+// granted by any of them are denied.
+type List struct {
+	rules []Rule
+}
+
+// New returns list of the rules.
+func New(rules []Rule) (*List, error) {
+	for i, rule := range rules {
+		if rule.Principal == "" {
+			return nil, errors.Errorf("rule %d: empty principal", i)
+		}
+		for _, op := range rule.Operations {
+			if !operations[op] {
+				return nil, errors.Errorf("rule %d: unknown operation %q", i, op)
+			}
+		}
+	}
+
+	return &List{rules: rules}, nil
+}
+
+// Load loads list from the file
+// with JSON array of the rules.
+func Load(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open config")
+	}
+	defer f.Close()
+
+	var rules []Rule
+	if err := json.NewDecoder(f).Decode(&rules); err != nil {
+		return nil, errors.Wrap(err, "decode config")
+	}
+
+	return New(rules)
+}
+
+// Allowed reports whether the principal may do
+// the operation on the key, empty principal is
+// anonymous one.
+func (l *List) Allowed(principal string, op Operation, key string) bool {
+	for _, rule := range l.rules {
+		if rule.Principal != AnyPrincipal && rule.Principal != principal {
+			continue
+		}
+		if !strings.HasPrefix(key, rule.Prefix) {
+			continue
+		}
+		for _, granted := range rule.Operations {
+			if granted == op {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package acl
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList_Allowed(t *testing.T) {
+	l, err := New([]Rule{
+		{Principal: "app", Prefix: "app:", Operations: []Operation{Read, Write}},
+		{Principal: "ops", Prefix: "", Operations: []Operation{Admin, Delete}},
+		{Principal: AnyPrincipal, Prefix: "public:", Operations: []Operation{Read}},
+	})
+	if err != nil {
+		t.Fatalf("new list: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		principal string
+		op        Operation
+		key       string
+		expect    bool
+	}{
+		{name: "granted", principal: "app", op: Write, key: "app:1", expect: true},
+		{name: "other operation", principal: "app", op: Consume, key: "app:1"},
+		{name: "other prefix", principal: "app", op: Read, key: "ops:1"},
+		{name: "other principal", principal: "svc", op: Read, key: "app:1"},
+		{name: "any prefix", principal: "ops", op: Delete, key: "app:1", expect: true},
+		{name: "admin", principal: "ops", op: Admin, key: "", expect: true},
+		{name: "admin of prefixed rule", principal: "app", op: Admin, key: ""},
+		{name: "any principal", principal: "svc", op: Read, key: "public:1", expect: true},
+		{name: "anonymous", principal: "", op: Read, key: "public:1", expect: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, l.Allowed(tt.principal, tt.op, tt.key))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "valid",
+			config: `[{"principal": "app", "prefix": "app:", "operations": ["read", "consume"]}]`,
+		},
+		{
+			name:   "unknown operation",
+			config: `[{"principal": "app", "prefix": "app:", "operations": ["list"]}]`,
+			err:    `rule 0: unknown operation "list"`,
+		},
+		{
+			name:   "empty principal",
+			config: `[{"prefix": "app:", "operations": ["read"]}]`,
+			err:    "rule 0: empty principal",
+		},
+		{
+			name:   "malformed",
+			config: `{}`,
+			err:    "decode config: json: cannot unmarshal object into Go value of type []acl.Rule",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "acl")
+			if err != nil {
+				t.Fatalf("create temp file: %v", err)
+			}
+			defer os.Remove(f.Name())
+			f.WriteString(tt.config)
+			f.Close()
+
+			_, err = Load(f.Name())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package acl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/codec"
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// Error describes denied access.
+type Error struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Code implements the responses.Coder interface.
+func (e *Error) Code() responses.Code {
+	return responses.CodeForbidden
+}
+
+// TargetError describes request which keys
+// can't be determined to check the access.
+type TargetError struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *TargetError) Error() string {
+	return e.Message
+}
+
+// Code implements the responses.Coder interface.
+func (e *TargetError) Code() responses.Code {
+	return responses.CodeMalformedBody
+}
+
+// Target returns operation of the request and keys it
+// accesses, ok is false when they can't be determined.
+type Target func(r *http.Request) (op Operation, keys []string, ok bool)
+
+// Middleware returns middleware which responds 403 to
+// requests of principals not allowed the operation on
+// all keys of the target, denials are logged. Requests
+// with keys which can't be determined get 400.
+func (l *List) Middleware(target Target) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, keys, ok := target(r)
+			if !ok {
+				responses.Error(w, r, &TargetError{
+					Message: "keys of the request can't be determined",
+				})
+				return
+			}
+
+			principal, _ := auth.FromContext(r.Context())
+			for _, key := range keys {
+				if l.Allowed(principal.Name, op, key) {
+					continue
+				}
+
+				log.Printf("acl: denied %s of key %q to principal %q", op, key, principal.Name)
+				responses.Error(w, r, &Error{
+					Message: fmt.Sprintf("%s of key %q is not allowed", op, key),
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// NoKey returns target of the operation
+// not bound to keys, like Admin.
+func NoKey(op Operation) Target {
+	return func(r *http.Request) (Operation, []string, bool) {
+		return op, []string{""}, true
+	}
+}
+
+// PathKey returns target of the operation on
+// the key of {key} variable of the route.
+func PathKey(op Operation) Target {
+	return func(r *http.Request) (Operation, []string, bool) {
+		return op, []string{mux.Vars(r)["key"]}, true
+	}
+}
+
+// BodyKeys returns target of the operation on
+// keys of key and keys fields of the body.
+func BodyKeys(op Operation) Target {
+	return func(r *http.Request) (Operation, []string, bool) {
+		body, ok := peekBody(r)
+		if !ok {
+			return "", nil, false
+		}
+
+		return op, body.keys(), true
+	}
+}
+
+// BodyGet returns target of get requests, they
+// read the key with peek and consume it otherwise.
+func BodyGet() Target {
+	return func(r *http.Request) (Operation, []string, bool) {
+		body, ok := peekBody(r)
+		if !ok {
+			return "", nil, false
+		}
+
+		op := Consume
+		if body.Peek {
+			op = Read
+		}
+
+		return op, body.keys(), true
+	}
+}
+
+// body holds fields of request
+// bodies which select keys.
+type body struct {
+	Key  *string
+	Keys []string
+	Peek bool
+}
+
+func (b body) keys() []string {
+	var keys []string
+	if b.Key != nil || len(b.Keys) == 0 {
+		var key string
+		if b.Key != nil {
+			key = *b.Key
+		}
+		keys = append(keys, key)
+	}
+
+	return append(keys, b.Keys...)
+}
+
+// selectors are fields of the body which select keys.
+var selectors = []string{"key", "keys", "peek"}
+
+// peekBody decodes the body leaving it unread for the
+// handler, other fields of the body are ignored. ok is
+// false when it can't be decoded, fields which select
+// keys are of the wrong kind or named in other case.
+func peekBody(r *http.Request) (body, bool) {
+	var b body
+
+	data, err := ioutil.ReadAll(r.Body)
+	// Read part is put back, so handler
+	// gets the same data and error.
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	if err != nil {
+		return b, false
+	}
+
+	c, ok := codec.Default.ForContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		return b, false
+	}
+
+	// Body is decoded into the map, so fields
+	// of every request kind are accepted.
+	var fields map[string]interface{}
+	if err := c.Unmarshal(data, &fields); err != nil {
+		return b, false
+	}
+
+	// Handlers match field names ignoring case,
+	// so they could take keys other than checked.
+	for name := range fields {
+		for _, selector := range selectors {
+			if name != selector && strings.EqualFold(name, selector) {
+				return b, false
+			}
+		}
+	}
+
+	if v, found := fields["key"]; found {
+		key, ok := v.(string)
+		if !ok {
+			return b, false
+		}
+		b.Key = &key
+	}
+
+	if v, found := fields["keys"]; found {
+		keys, ok := v.([]interface{})
+		if !ok {
+			return b, false
+		}
+		for _, k := range keys {
+			key, ok := k.(string)
+			if !ok {
+				return b, false
+			}
+			b.Keys = append(b.Keys, key)
+		}
+	}
+
+	if v, found := fields["peek"]; found {
+		if b.Peek, ok = v.(bool); !ok {
+			return b, false
+		}
+	}
+
+	return b, true
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,153 @@
+package acl
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList_Middleware(t *testing.T) {
+	l, err := New([]Rule{
+		{Principal: "app", Prefix: "app:", Operations: []Operation{Read, Write}},
+		{Principal: "ops", Operations: []Operation{Admin}},
+	})
+	if err != nil {
+		t.Fatalf("new list: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		principal string
+		target    Target
+		path      string
+		body      string
+		status    int
+		detail    string
+	}{
+		{
+			name:      "body key",
+			principal: "app",
+			target:    BodyKeys(Write),
+			body:      `{"key": "app:1", "value": 1}`,
+		},
+		{
+			name:      "body keys",
+			principal: "app",
+			target:    BodyKeys(Read),
+			body:      `{"keys": ["app:1", "ops:1"]}`,
+			detail:    `read of key "ops:1" is not allowed`,
+		},
+		{
+			name:      "peek",
+			principal: "app",
+			target:    BodyGet(),
+			body:      `{"key": "app:1", "peek": true}`,
+		},
+		{
+			name:      "consume",
+			principal: "app",
+			target:    BodyGet(),
+			body:      `{"key": "app:1"}`,
+			detail:    `consume of key "app:1" is not allowed`,
+		},
+		{
+			name:      "other fields",
+			principal: "app",
+			target:    BodyGet(),
+			body:      `{"key": "ops:1", "path": "a.b"}`,
+			detail:    `consume of key "ops:1" is not allowed`,
+		},
+		{
+			name:      "malformed body",
+			principal: "app",
+			target:    BodyKeys(Write),
+			body:      `{"key": 1}`,
+			status:    http.StatusBadRequest,
+			detail:    "keys of the request can't be determined",
+		},
+		{
+			name:      "malformed keys",
+			principal: "app",
+			target:    BodyKeys(Read),
+			body:      `{"keys": ["app:1", 2]}`,
+			status:    http.StatusBadRequest,
+			detail:    "keys of the request can't be determined",
+		},
+		{
+			name:      "case variant key",
+			principal: "app",
+			target:    BodyKeys(Write),
+			body:      `{"key": "app:1", "Key": "ops:1"}`,
+			status:    http.StatusBadRequest,
+			detail:    "keys of the request can't be determined",
+		},
+		{
+			name:      "path key",
+			principal: "app",
+			target:    PathKey(Write),
+			path:      "/v1/keys/ops:1",
+			detail:    `write of key "ops:1" is not allowed`,
+		},
+		{
+			name:      "admin",
+			principal: "ops",
+			target:    NoKey(Admin),
+		},
+		{
+			name:   "anonymous",
+			target: NoKey(Admin),
+			detail: `admin of key "" is not allowed`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body string
+			router := mux.NewRouter()
+			router.Handle("/v1/keys/{key}", l.Middleware(tt.target)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+			})))
+
+			path := tt.path
+			if path == "" {
+				path = "/v1/keys/any"
+			}
+			req := httptest.NewRequest("POST", "http://any-host"+path, strings.NewReader(tt.body))
+			if tt.principal != "" {
+				req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Name: tt.principal}))
+			}
+			res := httptest.NewRecorder()
+
+			router.ServeHTTP(res, req)
+
+			if tt.detail == "" {
+				assert.Equal(t, http.StatusOK, res.Code)
+				assert.Equal(t, tt.body, body)
+				return
+			}
+
+			status, code := http.StatusForbidden, responses.CodeForbidden
+			if tt.status != 0 {
+				status, code = tt.status, responses.CodeMalformedBody
+			}
+			assert.Equal(t, status, res.Code)
+
+			var p responses.Problem
+			assert.Nil(t, json.Unmarshal(res.Body.Bytes(), &p))
+			assert.Equal(t, code, p.Code)
+			assert.Equal(t, tt.detail, p.Detail)
+		})
+	}
+}
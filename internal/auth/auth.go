@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/certs"
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	bearerPrefix = "Bearer "
+	realm        = `Bearer realm="integral_db"`
+)
+
+var now = time.Now
+
+// Method is a way principal was authenticated.
+type Method string
+
+// Authentication methods.
+const (
+	MethodAPIKey      Method = "api_key"
+	MethodToken       Method = "token"
+	MethodCertificate Method = "certificate"
+)
+
+// Principal is an authenticated client.
+type Principal struct {
+	Name   string
+	Method Method
+}
+
+// Error describes request which
+// can't be authenticated.
+type Error struct {
+	Message string
+	// invalidToken is true when bearer
+	// token is presented but rejected.
+	invalidToken bool
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Code implements the responses.Coder interface.
+func (e *Error) Code() responses.Code {
+	return responses.CodeUnauthorized
+}
+
+// Authenticator authenticates requests with static API
+// keys, HS256 signed bearer tokens or verified client
+// certificates.
+type Authenticator struct {
+	// keys maps SHA-256 digests of
+	// API keys to principal names.
+	keys   map[[sha256.Size]byte]string
+	secret []byte
+}
+
+// NewAuthenticator returns authenticator of API keys by
+// principal names and tokens signed with the secret,
+// tokens are not accepted when secret is empty.
+func NewAuthenticator(keys map[string]string, secret []byte) *Authenticator {
+	a := Authenticator{
+		keys:   make(map[[sha256.Size]byte]string, len(keys)),
+		secret: secret,
+	}
+	for name, key := range keys {
+		a.keys[sha256.Sum256([]byte(key))] = name
+	}
+
+	return &a
+}
+
+// LoadKeys loads API keys by principal
+// names from the JSON object file.
+func LoadKeys(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open keys")
+	}
+	defer f.Close()
+
+	var keys map[string]string
+	if err := json.NewDecoder(f).Decode(&keys); err != nil {
+		return nil, errors.Wrap(err, "decode keys")
+	}
+
+	for name, key := range keys {
+		if key == "" {
+			return nil, errors.Errorf("empty key of principal %q", name)
+		}
+	}
+
+	return keys, nil
+}
+
+// Authenticate returns principal of the request, credentials
+// are taken from Authorization header, then X-API-Key header
+// and then verified client certificate.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if !strings.HasPrefix(header, bearerPrefix) {
+			return Principal{}, &Error{Message: "authorization scheme must be Bearer"}
+		}
+
+		if len(a.secret) == 0 {
+			return Principal{}, &Error{Message: "bearer tokens are not accepted", invalidToken: true}
+		}
+
+		subject, err := verifyToken(strings.TrimSpace(header[len(bearerPrefix):]), a.secret, now())
+		if err != nil {
+			return Principal{}, &Error{Message: err.Error(), invalidToken: true}
+		}
+
+		return Principal{Name: subject, Method: MethodToken}, nil
+	}
+
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		name, ok := a.keys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return Principal{}, &Error{Message: "invalid API key"}
+		}
+
+		return Principal{Name: name, Method: MethodAPIKey}, nil
+	}
+
+	if identity, ok := certs.FromContext(r.Context()); ok && identity.CommonName != "" {
+		return Principal{Name: identity.CommonName, Method: MethodCertificate}, nil
+	}
+
+	return Principal{}, &Error{Message: "credentials are required"}
+}
+
+// Middleware returns middleware which responds 401 to
+// requests failed authentication, principal of others
+// is put into request context, see FromContext.
+func Middleware(a *Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := a.Authenticate(r)
+			if err != nil {
+				challenge := realm
+				if e, ok := err.(*Error); ok && e.invalidToken {
+					challenge += `, error="invalid_token"`
+				}
+				w.Header().Set("WWW-Authenticate", challenge)
+				responses.Error(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/certs"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	secret := []byte("secret")
+	a := NewAuthenticator(map[string]string{"app": "key"}, secret)
+
+	valid := sign(`{"alg":"HS256"}`, fmt.Sprintf(`{"sub":"svc","exp":%d}`, time.Now().Add(time.Hour).Unix()), secret)
+	expired := sign(`{"alg":"HS256"}`, fmt.Sprintf(`{"sub":"svc","exp":%d}`, time.Now().Add(-time.Hour).Unix()), secret)
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		identity  *certs.Identity
+		principal Principal
+		detail    string
+		challenge string
+	}{
+		{
+			name:      "api key",
+			header:    http.Header{"X-Api-Key": {"key"}},
+			principal: Principal{Name: "app", Method: MethodAPIKey},
+		},
+		{
+			name:      "token",
+			header:    http.Header{"Authorization": {"Bearer " + valid}},
+			principal: Principal{Name: "svc", Method: MethodToken},
+		},
+		{
+			name:      "certificate",
+			identity:  &certs.Identity{CommonName: "pod"},
+			principal: Principal{Name: "pod", Method: MethodCertificate},
+		},
+		{
+			name:      "invalid api key",
+			header:    http.Header{"X-Api-Key": {"other"}},
+			detail:    "invalid API key",
+			challenge: `Bearer realm="integral_db"`,
+		},
+		{
+			name:      "expired token",
+			header:    http.Header{"Authorization": {"Bearer " + expired}},
+			detail:    "token is expired",
+			challenge: `Bearer realm="integral_db", error="invalid_token"`,
+		},
+		{
+			name:      "basic",
+			header:    http.Header{"Authorization": {"Basic YTpi"}},
+			detail:    "authorization scheme must be Bearer",
+			challenge: `Bearer realm="integral_db"`,
+		},
+		{
+			name:      "anonymous",
+			detail:    "credentials are required",
+			challenge: `Bearer realm="integral_db"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				principal Principal
+				called    bool
+			)
+			h := Middleware(a)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, called = FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "http://any-host/", nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			if tt.identity != nil {
+				req = req.WithContext(certs.NewContext(req.Context(), *tt.identity))
+			}
+			res := httptest.NewRecorder()
+
+			h.ServeHTTP(res, req)
+
+			if tt.detail == "" {
+				assert.True(t, called)
+				assert.Equal(t, tt.principal, principal)
+				return
+			}
+
+			assert.False(t, called)
+			assert.Equal(t, http.StatusUnauthorized, res.Code)
+			assert.Equal(t, tt.challenge, res.Header().Get("WWW-Authenticate"))
+
+			var p responses.Problem
+			assert.Nil(t, json.Unmarshal(res.Body.Bytes(), &p))
+			assert.Equal(t, responses.CodeUnauthorized, p.Code)
+			assert.Equal(t, tt.detail, p.Detail)
+		})
+	}
+}
+
+func TestAuthenticator_tokensDisabled(t *testing.T) {
+	a := NewAuthenticator(nil, nil)
+
+	req := httptest.NewRequest("GET", "http://any-host/", nil)
+	req.Header.Set("Authorization", "Bearer "+sign(`{"alg":"HS256"}`, `{"sub":"svc","exp":9999999999}`, nil))
+
+	_, err := a.Authenticate(req)
+	assert.EqualError(t, err, "bearer tokens are not accepted")
+}
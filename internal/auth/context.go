@@ -0,0 +1,17 @@
+package auth
+
+import "context"
+
+type principalKey struct{}
+
+// FromContext returns principal of the request,
+// ok is false when request is not authenticated.
+func FromContext(ctx context.Context) (principal Principal, ok bool) {
+	principal, ok = ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// NewContext returns context carrying the principal.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
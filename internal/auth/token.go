@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// tokenHeader is JOSE header of the token.
+type tokenHeader struct {
+	Alg string `json:"alg"`
+}
+
+// tokenClaims are claims checked by
+// the server, others are ignored.
+type tokenClaims struct {
+	Subject   string   `json:"sub"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// verifyToken returns subject of the JWT signed by
+// HS256 with the secret, token must have expiry.
+func verifyToken(token string, secret []byte, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", errors.New("malformed token header")
+	}
+	if header.Alg != "HS256" {
+		return "", errors.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.New("malformed token signature")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", errors.New("invalid token signature")
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", errors.New("malformed token claims")
+	}
+
+	unix := float64(now.UnixNano()) / float64(time.Second)
+	switch {
+	case claims.ExpiresAt == nil:
+		return "", errors.New("token has no expiry")
+	case unix >= *claims.ExpiresAt:
+		return "", errors.New("token is expired")
+	case claims.NotBefore != nil && unix < *claims.NotBefore:
+		return "", errors.New("token is not valid yet")
+	case claims.Subject == "":
+		return "", errors.New("token has no subject")
+	}
+
+	return claims.Subject, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
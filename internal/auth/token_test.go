@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sign returns token of the header and
+// claims signed by HS256 with the secret.
+func sign(header, claims string, secret []byte) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func Test_verifyToken(t *testing.T) {
+	var (
+		secret = []byte("secret")
+		header = `{"alg":"HS256","typ":"JWT"}`
+		now    = time.Unix(1000, 0)
+	)
+
+	tests := []struct {
+		name    string
+		token   string
+		subject string
+		err     string
+	}{
+		{
+			name:    "valid",
+			token:   sign(header, `{"sub":"app","exp":1001,"nbf":1000}`, secret),
+			subject: "app",
+		},
+		{
+			name:  "malformed",
+			token: "token",
+			err:   "malformed token",
+		},
+		{
+			name:  "none algorithm",
+			token: sign(`{"alg":"none"}`, `{"sub":"app","exp":1001}`, secret),
+			err:   `unsupported token algorithm "none"`,
+		},
+		{
+			name:  "other secret",
+			token: sign(header, `{"sub":"app","exp":1001}`, []byte("other")),
+			err:   "invalid token signature",
+		},
+		{
+			name:  "without expiry",
+			token: sign(header, `{"sub":"app"}`, secret),
+			err:   "token has no expiry",
+		},
+		{
+			name:  "expired",
+			token: sign(header, `{"sub":"app","exp":1000}`, secret),
+			err:   "token is expired",
+		},
+		{
+			name:  "not valid yet",
+			token: sign(header, `{"sub":"app","exp":1002,"nbf":1001}`, secret),
+			err:   "token is not valid yet",
+		},
+		{
+			name:  "without subject",
+			token: sign(header, `{"exp":1001}`, secret),
+			err:   "token has no subject",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			subject, err := verifyToken(tt.token, secret, now)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.subject, subject)
+		})
+	}
+}
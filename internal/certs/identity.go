@@ -0,0 +1,53 @@
+package certs
+
+import (
+	"context"
+	"net/http"
+)
+
+// Identity describes verified
+// certificate of the client.
+type Identity struct {
+	CommonName     string
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+}
+
+type identityKey struct{}
+
+// FromContext returns identity of the client,
+// ok is false when client is not verified.
+func FromContext(ctx context.Context) (identity Identity, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// NewContext returns context
+// carrying identity of the client.
+func NewContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// Middleware puts identity of the verified client
+// certificate into request context, see FromContext.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			cert := r.TLS.VerifiedChains[0][0]
+
+			identity := Identity{
+				CommonName:     cert.Subject.CommonName,
+				DNSNames:       cert.DNSNames,
+				EmailAddresses: cert.EmailAddresses,
+			}
+			for _, uri := range cert.URIs {
+				identity.URIs = append(identity.URIs, uri.String())
+			}
+
+			r = r.WithContext(NewContext(r.Context(), identity))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,71 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Middleware(t *testing.T) {
+	uri, _ := url.Parse("spiffe://cluster/ns/default/sa/app")
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "app"},
+		DNSNames:       []string{"app.default.svc"},
+		EmailAddresses: []string{"app@example.com"},
+		URIs:           []*url.URL{uri},
+	}
+
+	tests := []struct {
+		name   string
+		state  *tls.ConnectionState
+		expect Identity
+		ok     bool
+	}{
+		{
+			name: "plaintext",
+		},
+		{
+			name:  "unverified",
+			state: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+		},
+		{
+			name:  "verified",
+			state: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			expect: Identity{
+				CommonName:     "app",
+				DNSNames:       []string{"app.default.svc"},
+				EmailAddresses: []string{"app@example.com"},
+				URIs:           []string{"spiffe://cluster/ns/default/sa/app"},
+			},
+			ok: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				identity Identity
+				ok       bool
+			)
+			h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				identity, ok = FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "http://any-host/", nil)
+			req.TLS = tt.state
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, identity)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// checkInterval is how often files
+// are checked for modification.
+var checkInterval = time.Second
+
+var now = time.Now
+
+// Reloader serves certificate and client CAs loaded from
+// files, they are reloaded on handshakes after the files
+// are modified, so certificates rotate without restart.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  []time.Time
+	checked   time.Time
+}
+
+// NewReloader returns reloader of the certificate and key
+// files, client certificates are required and verified
+// against CA bundle of clientCAFile unless it is empty.
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	r := Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+	}
+
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	r.checked = now()
+
+	return &r, nil
+}
+
+// Config returns TLS config serving
+// the current certificate and client CAs.
+func (r *Reloader) Config() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+
+	config := base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert, clientCAs := r.current()
+
+		c := base.Clone()
+		c.Certificates = []tls.Certificate{*cert}
+		if clientCAs != nil {
+			c.ClientCAs = clientCAs
+			c.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+
+		return c, nil
+	}
+
+	return config
+}
+
+// current returns certificate and client CAs,
+// they are reloaded first when files changed.
+// Failed reload keeps the previous ones.
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t := now(); t.Sub(r.checked) >= checkInterval {
+		r.checked = t
+
+		modTimes, err := r.stat()
+		if err != nil {
+			log.Printf("certs: could not check files: %v", err)
+		} else if changed(r.modTimes, modTimes) {
+			if err := r.load(modTimes); err != nil {
+				log.Printf("certs: could not reload: %v", err)
+			}
+		}
+	}
+
+	return r.cert, r.clientCAs
+}
+
+// load reads the files, must be called
+// with lock held unless r is not shared.
+func (r *Reloader) load(modTimes []time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "load key pair")
+	}
+
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		pem, err := ioutil.ReadFile(r.clientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "read client CA")
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.Errorf("no certificates in client CA %s", r.clientCAFile)
+		}
+	}
+
+	r.cert, r.clientCAs, r.modTimes = &cert, clientCAs, modTimes
+
+	return nil
+}
+
+// stat returns modification times of the files.
+func (r *Reloader) stat() ([]time.Time, error) {
+	files := []string{r.certFile, r.keyFile}
+	if r.clientCAFile != "" {
+		files = append(files, r.clientCAFile)
+	}
+
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = fi.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func changed(old, new []time.Time) bool {
+	for i := range new {
+		if !old[i].Equal(new[i]) {
+			return true
+		}
+	}
+
+	return false
+}
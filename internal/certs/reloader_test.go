@@ -0,0 +1,271 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// authority issues certificates for tests.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T, name string) *authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+
+	return &authority{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns PEM encoded certificate and key.
+func (a *authority) issue(t *testing.T, name string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("change times of %s: %v", path, err)
+	}
+}
+
+// handshake returns server certificate of the handshake.
+func handshake(addr string, config *tls.Config) (*x509.Certificate, error) {
+	conn, err := tls.Dial("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Client certificate is verified by
+	// server after client handshake.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return nil, err
+	}
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func Test_Reloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	checkInterval = 0
+	defer func() { checkInterval = time.Second }()
+
+	ca := newAuthority(t, "ca")
+	other := newAuthority(t, "other")
+
+	var (
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+		caFile   = filepath.Join(dir, "ca.pem")
+		modTime  = time.Now().Add(-time.Minute)
+	)
+
+	cert, key := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert, modTime)
+	writeFile(t, keyFile, key, modTime)
+	writeFile(t, caFile, ca.pem, modTime)
+
+	r, err := NewReloader(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", r.Config())
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if err := conn.(*tls.Conn).Handshake(); err == nil {
+					conn.Write([]byte{1})
+				}
+			}()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	roots.AppendCertsFromPEM(other.pem)
+
+	clientConfig := func(a *authority) *tls.Config {
+		config := tls.Config{RootCAs: roots, ServerName: "server"}
+		if a != nil {
+			cert, key := a.issue(t, "client", x509.ExtKeyUsageClientAuth)
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				t.Fatalf("key pair: %v", err)
+			}
+			config.Certificates = []tls.Certificate{pair}
+		}
+		return &config
+	}
+
+	t.Log("Given the need to test certificate reload.")
+	{
+		t.Log("\tTest: 0\tWhen client has certificate of the CA.")
+		{
+			peer, err := handshake(l.Addr().String(), clientConfig(ca))
+			assert.Nil(t, err)
+			assert.Equal(t, ca.cert.Subject, peer.Issuer)
+		}
+
+		t.Log("\tTest: 1\tWhen client has no certificate.")
+		{
+			_, err := handshake(l.Addr().String(), clientConfig(nil))
+			assert.Error(t, err)
+		}
+
+		t.Log("\tTest: 2\tWhen client has certificate of other CA.")
+		{
+			_, err := handshake(l.Addr().String(), clientConfig(other))
+			assert.Error(t, err)
+		}
+
+		t.Log("\tTest: 3\tWhen certificate and CA files are rotated.")
+		{
+			cert, key := other.issue(t, "server", x509.ExtKeyUsageServerAuth)
+			writeFile(t, certFile, cert, modTime.Add(time.Second))
+			writeFile(t, keyFile, key, modTime.Add(time.Second))
+			writeFile(t, caFile, other.pem, modTime.Add(time.Second))
+
+			peer, err := handshake(l.Addr().String(), clientConfig(other))
+			assert.Nil(t, err)
+			assert.Equal(t, other.cert.Subject, peer.Issuer)
+
+			_, err = handshake(l.Addr().String(), clientConfig(ca))
+			assert.Error(t, err)
+		}
+
+		t.Log("\tTest: 4\tWhen rotated files are invalid.")
+		{
+			writeFile(t, certFile, []byte("invalid"), modTime.Add(2*time.Second))
+
+			peer, err := handshake(l.Addr().String(), clientConfig(other))
+			assert.Nil(t, err)
+			assert.Equal(t, other.cert.Subject, peer.Issuer)
+		}
+	}
+}
+
+func Test_NewReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatalf("create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newAuthority(t, "ca")
+	cert, key := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+
+	var (
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+		caFile   = filepath.Join(dir, "ca.pem")
+	)
+	writeFile(t, certFile, cert, time.Now())
+	writeFile(t, keyFile, key, time.Now())
+	writeFile(t, caFile, []byte("invalid"), time.Now())
+
+	tests := []struct {
+		name         string
+		certFile     string
+		clientCAFile string
+		err          bool
+	}{
+		{name: "without client CA", certFile: certFile},
+		{name: "missing certificate", certFile: filepath.Join(dir, "missing.pem"), err: true},
+		{name: "invalid client CA", certFile: certFile, clientCAFile: caFile, err: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReloader(tt.certFile, keyFile, tt.clientCAFile)
+			assert.Equal(t, tt.err, err != nil, err)
+		})
+	}
+}
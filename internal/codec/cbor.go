@@ -0,0 +1,53 @@
+package codec
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBORMediaType is the media type of CBOR.
+const CBORMediaType = "application/cbor"
+
+// CBOR codec. Integers decoded into interface values
+// are int64, maps have string keys and times are
+// encoded as RFC 3339 strings like in JSON.
+var CBOR Codec = newCBORCodec()
+
+type cborCodec struct {
+	enc cbor.EncMode
+	dec cbor.DecMode
+}
+
+func newCBORCodec() cborCodec {
+	enc, err := cbor.EncOptions{
+		Sort: cbor.SortCanonical,
+		Time: cbor.TimeRFC3339Nano,
+	}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+
+	dec, err := cbor.DecOptions{
+		DefaultMapType:    reflect.TypeOf(map[string]interface{}(nil)),
+		IntDec:            cbor.IntDecConvertSigned,
+		ExtraReturnErrors: cbor.ExtraDecErrorUnknownField,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+
+	return cborCodec{enc: enc, dec: dec}
+}
+
+func (cborCodec) MediaType() string {
+	return CBORMediaType
+}
+
+func (c cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return c.enc.Marshal(v)
+}
+
+func (c cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.dec.Unmarshal(data, v)
+}
@@ -0,0 +1,144 @@
+package codec
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Codec encodes and decodes values of the media type.
+// Struct fields are named by their json tags in every
+// format, so request and response types are shared.
+type Codec interface {
+	MediaType() string
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes single value of the data into v,
+	// unknown object fields and trailing data are errors.
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Registry holds codecs by their media types,
+// the first registered codec is the default.
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+	def    Codec
+}
+
+// Default registry with JSON, MessagePack and CBOR codecs.
+var Default = NewRegistry(JSON, MessagePack, CBOR)
+
+// NewRegistry returns registry of the codecs.
+func NewRegistry(codecs ...Codec) *Registry {
+	r := Registry{
+		codecs: make(map[string]Codec),
+	}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+
+	return &r
+}
+
+// Register adds codec to the registry,
+// replacing codec of the same media type.
+func (r *Registry) Register(c Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.def == nil {
+		r.def = c
+	}
+	r.codecs[c.MediaType()] = c
+}
+
+// MediaTypes returns sorted media types of the codecs.
+func (r *Registry) MediaTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.codecs))
+	for mediaType := range r.codecs {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// ForContentType returns codec of the Content-Type header,
+// empty header selects the default codec. Media types with
+// "+json" suffix are decoded as JSON.
+func (r *Registry) ForContentType(header string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if header == "" {
+		return r.def, r.def != nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil, false
+	}
+
+	if c, ok := r.codecs[mediaType]; ok {
+		return c, true
+	}
+
+	if strings.HasSuffix(mediaType, "+json") {
+		c, ok := r.codecs[JSONMediaType]
+		return c, ok
+	}
+
+	return nil, false
+}
+
+// ForAccept returns the most preferred codec of the Accept
+// header, the default codec is returned when header is
+// empty or none of its media types is supported.
+func (r *Registry) ForAccept(header string) Codec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		best    Codec
+		quality float64
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= quality {
+			continue
+		}
+
+		c, ok := r.codecs[mediaType]
+		switch {
+		case ok:
+		case mediaType == "*/*" || mediaType == "application/*":
+			c = r.def
+		default:
+			continue
+		}
+
+		best, quality = c, q
+	}
+
+	if best == nil {
+		return r.def
+	}
+
+	return best
+}
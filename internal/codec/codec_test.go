@@ -0,0 +1,101 @@
+package codec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_ForContentType(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		expect Codec
+		ok     bool
+	}{
+		{name: "empty", header: "", expect: JSON, ok: true},
+		{name: "json", header: "application/json; charset=utf-8", expect: JSON, ok: true},
+		{name: "json suffix", header: "application/merge-patch+json", expect: JSON, ok: true},
+		{name: "msgpack", header: "application/msgpack", expect: MessagePack, ok: true},
+		{name: "cbor", header: "application/cbor", expect: CBOR, ok: true},
+		{name: "unsupported", header: "text/plain"},
+		{name: "malformed", header: "/"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := Default.ForContentType(tt.header)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestRegistry_ForAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		expect Codec
+	}{
+		{name: "empty", header: "", expect: JSON},
+		{name: "any", header: "*/*", expect: JSON},
+		{name: "msgpack", header: "application/msgpack", expect: MessagePack},
+		{name: "quality", header: "application/msgpack;q=0.5, application/cbor;q=0.9, */*;q=0.1", expect: CBOR},
+		{name: "unsupported", header: "text/html", expect: JSON},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expect, Default.ForAccept(tt.header))
+		})
+	}
+}
+
+func TestRegistry_MediaTypes(t *testing.T) {
+	assert.Equal(t, []string{"application/cbor", "application/json", "application/msgpack"}, Default.MediaTypes())
+}
+
+func TestCodecs(t *testing.T) {
+	type request struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+	}
+
+	at := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, c := range []Codec{JSON, MessagePack, CBOR} {
+		c := c
+		t.Run(c.MediaType(), func(t *testing.T) {
+			t.Parallel()
+
+			b, err := c.Marshal(request{Key: "key", Value: map[string]interface{}{"name": "name"}})
+			assert.Nil(t, err)
+
+			var got request
+			assert.Nil(t, c.Unmarshal(b, &got))
+			assert.Equal(t, request{Key: "key", Value: map[string]interface{}{"name": "name"}}, got)
+
+			b, err = c.Marshal(map[string]interface{}{"key": "key", "unknown": 1})
+			assert.Nil(t, err)
+			assert.Error(t, c.Unmarshal(b, &got))
+
+			b, err = c.Marshal("key")
+			assert.Nil(t, err)
+			var s string
+			assert.Error(t, c.Unmarshal(append(b, b...), &s))
+
+			b, err = c.Marshal(at)
+			assert.Nil(t, err)
+			var gotAt time.Time
+			assert.Nil(t, c.Unmarshal(b, &gotAt))
+			assert.True(t, at.Equal(gotAt))
+		})
+	}
+}
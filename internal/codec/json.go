@@ -0,0 +1,44 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// JSONMediaType is the media type of JSON.
+const JSONMediaType = "application/json"
+
+// JSON codec.
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string {
+	return JSONMediaType
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if err := dec.Decode(&json.RawMessage{}); err != io.EOF {
+		return errors.New("trailing data after JSON value")
+	}
+
+	return nil
+}
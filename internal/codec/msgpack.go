@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MessagePackMediaType is the media type of MessagePack.
+const MessagePackMediaType = "application/msgpack"
+
+// MessagePack codec. Integers decoded into interface
+// values are int64 and uint64, maps have string keys.
+var MessagePack Codec = msgpackCodec{}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaType() string {
+	return MessagePackMediaType
+}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetSortMapKeys(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	r := bytes.NewReader(data)
+
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	dec.DisallowUnknownFields(true)
+	dec.UseLooseInterfaceDecoding(true)
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if r.Len() > 0 {
+		return errors.New("trailing data after MessagePack value")
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package decode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/romanyx/integral_db/internal/codec"
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// DefaultMaxBodySize limits request body
+// size when no other limit is configured.
+const DefaultMaxBodySize = 1 << 20
+
+// Error describes request body which can't
+// be decoded, code selects the response.
+type Error struct {
+	code    responses.Code
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Code implements the responses.Coder interface.
+func (e *Error) Code() responses.Code {
+	return e.code
+}
+
+// MaxBodySize returns middleware which limits
+// size of request bodies to n bytes.
+func MaxBodySize(n int64) func(http.Handler) http.Handler {
+	if n <= 0 {
+		n = DefaultMaxBodySize
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Request strictly decodes body of the request into v with
+// codec selected by its Content-Type, JSON when it is not set.
+func Request(r *http.Request, v interface{}) error {
+	c, ok := codec.Default.ForContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		return &Error{
+			code:    responses.CodeUnsupportedMediaType,
+			Message: fmt.Sprintf("content type must be %s", strings.Join(codec.Default.MediaTypes(), " or ")),
+		}
+	}
+
+	b, err := read(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if c.MediaType() == codec.JSONMediaType {
+		return strictJSON(b, v)
+	}
+
+	if err := c.Unmarshal(b, v); err != nil {
+		return malformed("%s", err)
+	}
+
+	return nil
+}
+
+// Strict decodes single JSON value of the body into v,
+// unknown object fields and trailing data are rejected.
+// Errors point to the line and column of the body.
+func Strict(body io.Reader, v interface{}) error {
+	b, err := read(body)
+	if err != nil {
+		return err
+	}
+
+	return strictJSON(b, v)
+}
+
+// Raw reads the whole body which must not be empty.
+func Raw(body io.Reader) ([]byte, error) {
+	return read(body)
+}
+
+// read reads the whole body which must not be empty.
+func read(body io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &Error{
+				code:    responses.CodeBodyTooLarge,
+				Message: fmt.Sprintf("request body must be no more than %d bytes", tooLarge.Limit),
+			}
+		}
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, malformed("request body must not be empty")
+	}
+
+	return b, nil
+}
+
+func strictJSON(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(b, dec, err)
+	}
+
+	end := dec.InputOffset()
+	if err := dec.Decode(&json.RawMessage{}); err != io.EOF {
+		trailing := len(b[end:]) - len(bytes.TrimLeft(b[end:], " \t\r\n"))
+		return malformed("request body must contain a single JSON value, found trailing data %s", location(b, end+int64(trailing)))
+	}
+
+	return nil
+}
+
+// decodeError converts error of the decoder
+// to the error pointing to its location.
+func decodeError(b []byte, dec *json.Decoder, err error) error {
+	var (
+		syntax    *json.SyntaxError
+		unmarshal *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntax):
+		return malformed("%s %s", strings.TrimPrefix(syntax.Error(), "json: "), location(b, syntax.Offset-1))
+	case errors.As(err, &unmarshal):
+		if unmarshal.Field == "" {
+			return malformed("request body must be %s %s", kind(unmarshal.Type), location(b, unmarshal.Offset-1))
+		}
+		return malformed("field %q must be %s %s", unmarshal.Field, kind(unmarshal.Type), location(b, unmarshal.Offset-1))
+	case err == io.ErrUnexpectedEOF:
+		return malformed("unexpected end of JSON input %s", location(b, int64(len(b))))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// Decoder reports unknown fields after the whole
+		// object is read, so the field name is looked up.
+		name := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		offset := int64(bytes.Index(b, []byte(name)))
+		if offset < 0 {
+			offset = dec.InputOffset() - 1
+		}
+		return malformed("unknown field %s %s", name, location(b, offset))
+	default:
+		return malformed("%s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+}
+
+// kind returns JSON kind of values decoded into t.
+func kind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	default:
+		return t.String()
+	}
+}
+
+// location describes byte of the body at offset as line
+// and column, offset of the body length points past the
+// last byte.
+func location(b []byte, offset int64) string {
+	switch {
+	case offset < 0:
+		offset = 0
+	case offset > int64(len(b)):
+		offset = int64(len(b))
+	}
+
+	before := b[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+
+	return fmt.Sprintf("at line %d, column %d", line, column)
+}
+
+func malformed(format string, args ...interface{}) error {
+	return &Error{
+		code:    responses.CodeMalformedBody,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
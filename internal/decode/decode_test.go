@@ -0,0 +1,166 @@
+package decode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/romanyx/integral_db/internal/codec"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expect      request
+		code        responses.Code
+		message     string
+	}{
+		{
+			name:   "without content type",
+			body:   `{"key": "key", "count": 1}`,
+			expect: request{Key: "key", Count: 1},
+		},
+		{
+			name:        "json suffix",
+			contentType: "application/vnd.api+json; charset=utf-8",
+			body:        `{"key": "key"}`,
+			expect:      request{Key: "key"},
+		},
+		{
+			name:        "msgpack",
+			contentType: "application/msgpack",
+			body:        marshal(codec.MessagePack, map[string]interface{}{"key": "key", "count": 1}),
+			expect:      request{Key: "key", Count: 1},
+		},
+		{
+			name:        "msgpack unknown field",
+			contentType: "application/msgpack",
+			body:        marshal(codec.MessagePack, map[string]interface{}{"key": "key", "value": 1}),
+			code:        responses.CodeMalformedBody,
+			message:     "msgpack: unknown field \"value\"",
+		},
+		{
+			name:        "cbor",
+			contentType: "application/cbor",
+			body:        marshal(codec.CBOR, map[string]interface{}{"key": "key", "count": 1}),
+			expect:      request{Key: "key", Count: 1},
+		},
+		{
+			name:        "cbor trailing data",
+			contentType: "application/cbor",
+			body:        marshal(codec.CBOR, "key") + marshal(codec.CBOR, "key"),
+			code:        responses.CodeMalformedBody,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "application/x-www-form-urlencoded",
+			body:        `{"key": "key"}`,
+			code:        responses.CodeUnsupportedMediaType,
+			message:     "content type must be application/cbor or application/json or application/msgpack",
+		},
+		{
+			name:    "empty",
+			body:    " \n",
+			code:    responses.CodeMalformedBody,
+			message: "request body must not be empty",
+		},
+		{
+			name:    "syntax error",
+			body:    "{\n  \"key\": }",
+			code:    responses.CodeMalformedBody,
+			message: "invalid character '}' looking for beginning of value at line 2, column 10",
+		},
+		{
+			name:    "unexpected end",
+			body:    `{"key": "key"`,
+			code:    responses.CodeMalformedBody,
+			message: "unexpected end of JSON input at line 1, column 14",
+		},
+		{
+			name:    "wrong field type",
+			body:    `{"key": "key", "count": "1"}`,
+			code:    responses.CodeMalformedBody,
+			message: `field "count" must be an integer at line 1, column 27`,
+		},
+		{
+			name:    "wrong body type",
+			body:    `["key"]`,
+			code:    responses.CodeMalformedBody,
+			message: "request body must be an object at line 1, column 1",
+		},
+		{
+			name:    "unknown field",
+			body:    `{"key": "key", "value": 1}`,
+			code:    responses.CodeMalformedBody,
+			message: `unknown field "value" at line 1, column 16`,
+		},
+		{
+			name:    "trailing data",
+			body:    `{"key": "key"} {}`,
+			code:    responses.CodeMalformedBody,
+			message: "request body must contain a single JSON value, found trailing data at line 1, column 16",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest("POST", "http://any-host/set", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var got request
+			err := Request(r, &got)
+
+			if tt.code == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+				return
+			}
+
+			if assert.IsType(t, &Error{}, err) {
+				assert.Equal(t, tt.code, err.(*Error).Code())
+				if tt.message != "" {
+					assert.Equal(t, tt.message, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func marshal(c codec.Codec, v interface{}) string {
+	b, err := c.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(b)
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var err error
+	h := MaxBodySize(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		err = Request(r, &req)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "http://any-host/set", strings.NewReader(`{"key": "key"}`)))
+
+	if assert.IsType(t, &Error{}, err) {
+		assert.Equal(t, responses.CodeBodyTooLarge, err.(*Error).Code())
+		assert.Equal(t, "request body must be no more than 8 bytes", err.Error())
+	}
+}
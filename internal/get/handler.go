@@ -3,7 +3,6 @@ package get
 import (
 	"net/http"
 
-	"github.com/pkg/errors"
 	"github.com/romanyx/integral_db/internal/responses"
 )
 
@@ -13,18 +12,11 @@ func NewHandler(srv Getter) http.HandlerFunc {
 		var resp response
 
 		if err := srv.Get(r, &resp); err != nil {
-			switch resp := errors.Cause(err).(type) {
-			case validationErrorResponse:
-				responses.BadRequest(w, resp)
-			case notFoundResponse:
-				responses.NotFound(w, resp)
-			default:
-				responses.InternalServerError(w)
-			}
+			responses.Error(w, r, err)
 			return
 		}
 
-		responses.OK(w, resp)
+		responses.OK(w, r, resp)
 	}
 }
 
@@ -36,6 +36,13 @@ func Test_NewHandler(t *testing.T) {
 			},
 			code: http.StatusNotFound,
 		},
+		{
+			name: "wrong type error",
+			getFunc: func(*http.Request, *response) error {
+				return wrongTypeResponse{}
+			},
+			code: http.StatusConflict,
+		},
 		{
 			name: "unexpected error",
 			getFunc: func(*http.Request, *response) error {
@@ -66,3 +73,7 @@ type GetterFunc func(*http.Request, *response) error
 func (f GetterFunc) Get(r *http.Request, resp *response) error {
 	return f(r, resp)
 }
+
+func (f GetterFunc) GetKey(string, string, bool) (interface{}, error) {
+	return nil, nil
+}
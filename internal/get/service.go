@@ -1,30 +1,42 @@
 package get
 
 import (
-	"encoding/json"
 	"net/http"
 
-	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/jsondoc"
+	"github.com/romanyx/integral_db/internal/responses"
 	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
 )
 
 const (
 	keyFoundMessage                = "key found"
 	validationErrorResponseMessage = "you have validation errors"
 	notFoundMessage                = "key not found"
+	pathNotFoundMessage            = "path not found"
+	invalidPathMessage             = "must be a JSON pointer or JSONPath expression"
+	wrongTypeMessage               = "key holds the wrong kind of value"
 )
 
 // Getter service for get key requests.
 type Getter interface {
 	Get(r *http.Request, resp *response) error
+	// GetKey returns value of the key or its fragment
+	// selected by path for transports other than HTTP,
+	// the key is consumed unless peek is true.
+	GetKey(key, path string, peek bool) (interface{}, error)
 }
 
-// NewService returns initialized service.
-func NewService(storage storage.Storage) Getter {
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, rules validate.Rules) Getter {
 	srv := muxMap{
-		decoder:   jsonDecoder{},
-		validater: ozzoValidater{},
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
 		getter: &sGetter{
 			storage: storage,
 		},
@@ -41,6 +53,11 @@ type muxMap struct {
 
 type request struct {
 	Key string `json:"key"`
+	// Path selects fragment of the value.
+	Path string `json:"path"`
+	// Peek reads value without
+	// removing it from the storage.
+	Peek bool `json:"peek"`
 }
 
 type decoder interface {
@@ -53,6 +70,7 @@ type validater interface {
 
 type getter interface {
 	Get(key string) (value interface{}, err error)
+	View(key string, consume bool, path string) (value interface{}, err error)
 }
 
 func (s muxMap) Get(r *http.Request, resp *response) error {
@@ -62,13 +80,9 @@ func (s muxMap) Get(r *http.Request, resp *response) error {
 		return errors.Wrap(err, "decode failed")
 	}
 
-	if err := s.validater.Validate(req); err != nil {
-		return errors.Wrap(err, "validation failed")
-	}
-
-	value, err := s.getter.Get(req.Key)
+	value, err := s.GetKey(req.Key, req.Path, req.Peek)
 	if err != nil {
-		return errors.Wrap(err, "get value failed")
+		return err
 	}
 
 	resp.Message = keyFoundMessage
@@ -79,27 +93,56 @@ func (s muxMap) Get(r *http.Request, resp *response) error {
 	return nil
 }
 
-type jsonDecoder struct{}
+func (s muxMap) GetKey(key, path string, peek bool) (interface{}, error) {
+	req := request{Key: key, Path: path, Peek: peek}
+
+	if err := s.validater.Validate(req); err != nil {
+		return nil, errors.Wrap(err, "validation failed")
+	}
+
+	var (
+		value interface{}
+		err   error
+	)
+
+	if req.Path == "" && !req.Peek {
+		value, err = s.getter.Get(req.Key)
+	} else {
+		value, err = s.getter.View(req.Key, !req.Peek, req.Path)
+	}
 
-func (d jsonDecoder) Decode(r *http.Request, req *request) error {
-	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		errors.Wrap(err, "unable to decode")
+		return nil, errors.Wrap(err, "get value failed")
 	}
-	return nil
+
+	return value, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	return decode.Request(r, req)
 }
 
-type ozzoValidater struct{}
+type ozzoValidater struct {
+	rules validate.Rules
+}
 
 func (v ozzoValidater) Validate(r request) error {
 	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
 
-	if err := validation.Validate(r.Key, validation.Required); err != nil {
+	if err := v.rules.Key(r.Key); err != nil {
 		validatationError.Errors = append(validatationError.Errors,
 			validationError{Field: "key", Message: err.Error()},
 		)
 	}
 
+	if _, err := jsondoc.ParsePath(r.Path); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "path", Message: invalidPathMessage},
+		)
+	}
+
 	if len(validatationError.Errors) > 0 {
 		return validatationError
 	}
@@ -112,16 +155,67 @@ type sGetter struct {
 }
 
 func (g *sGetter) Get(key string) (interface{}, error) {
-	value, err := g.storage.Get(key)
+	return g.storageValue(g.storage.Get(key))
+}
+
+// errNoMatches stops the view, so the key
+// isn't consumed by JSONPath matching nothing.
+var errNoMatches = errors.New("no matches")
+
+// View returns fragment of the value selected by path,
+// key is removed from the storage only when consume is
+// true and the path is found. JSONPath matching nothing
+// returns empty list and keeps the key.
+func (g *sGetter) View(key string, consume bool, path string) (interface{}, error) {
+	selector, err := jsondoc.ParsePath(path)
 	if err != nil {
-		if err == storage.ErrNotFound {
-			return nil, notFoundResponse{
-				Message: notFoundMessage,
+		return nil, validationErrorResponse{
+			Message: validationErrorResponseMessage,
+			Errors: []validationError{
+				validationError{Field: "path", Message: invalidPathMessage},
+			},
+		}
+	}
+
+	value, err := g.storage.View(key, consume, func(value interface{}) (interface{}, error) {
+		selected, err := selector.Select(value)
+		if _, ok := selector.(jsondoc.JSONPath); ok && err == nil {
+			if matches, _ := selected.([]interface{}); len(matches) == 0 {
+				return nil, errNoMatches
 			}
 		}
+
+		return selected, err
+	})
+	switch err {
+	case errNoMatches:
+		return []interface{}{}, nil
+	case jsondoc.ErrPathNotFound, jsondoc.ErrInvalidIndex:
+		return nil, pathNotFoundResponse{
+			Message: pathNotFoundMessage,
+		}
 	}
 
-	return value, nil
+	return g.storageValue(value, err)
+}
+
+// storageValue converts storage errors
+// to the response errors.
+func (g *sGetter) storageValue(value interface{}, err error) (interface{}, error) {
+	switch err {
+	case nil:
+		return value, nil
+	case storage.ErrNotFound:
+		return nil, notFoundResponse{
+			Message: notFoundMessage,
+		}
+	case storage.ErrWrongType:
+		return nil, wrongTypeResponse{
+			Message: wrongTypeMessage,
+		}
+	default:
+		return nil, err
+	}
 }
 
 type notFoundResponse struct {
@@ -133,6 +227,39 @@ func (r notFoundResponse) Error() string {
 	return r.Message
 }
 
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type pathNotFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r pathNotFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r pathNotFoundResponse) Code() responses.Code {
+	return responses.CodePathNotFound
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
 type validationErrorResponse struct {
 	Message string            `json:"message"`
 	Errors  []validationError `json:"errors"`
@@ -147,3 +274,13 @@ type validationError struct {
 func (r validationErrorResponse) Error() string {
 	return r.Message
 }
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -22,10 +22,17 @@ func (f validaterFunc) Validate(r request) error {
 	return f(r)
 }
 
-type getterFunc func(string) (interface{}, error)
+type getterMock struct {
+	getFunc  func(string) (interface{}, error)
+	viewFunc func(string, bool, string) (interface{}, error)
+}
+
+func (g getterMock) Get(key string) (value interface{}, err error) {
+	return g.getFunc(key)
+}
 
-func (f getterFunc) Get(key string) (value interface{}, err error) {
-	return f(key)
+func (g getterMock) View(key string, consume bool, path string) (value interface{}, err error) {
+	return g.viewFunc(key, consume, path)
 }
 
 func Test_muxMap_Get(t *testing.T) {
@@ -34,6 +41,7 @@ func Test_muxMap_Get(t *testing.T) {
 		decodeFunc   func(*http.Request, *request) error
 		validateFunc func(request) error
 		getFunc      func(string) (interface{}, error)
+		viewFunc     func(string, bool, string) (interface{}, error)
 		wantErr      bool
 		expect       response
 	}{
@@ -85,6 +93,29 @@ func Test_muxMap_Get(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "peek path",
+			decodeFunc: func(_ *http.Request, req *request) error {
+				req.Path = "/a"
+				req.Peek = true
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			viewFunc: func(key string, consume bool, path string) (value interface{}, err error) {
+				if consume || path != "/a" {
+					return nil, errors.New("mock error")
+				}
+				return 1, nil
+			},
+			expect: response{
+				Message: "key found",
+				Data: data{
+					Value: 1,
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -95,7 +126,7 @@ func Test_muxMap_Get(t *testing.T) {
 			s := muxMap{
 				decoder:   decoderFunc(tt.decodeFunc),
 				validater: validaterFunc(tt.validateFunc),
-				getter:    getterFunc(tt.getFunc),
+				getter:    getterMock{getFunc: tt.getFunc, viewFunc: tt.viewFunc},
 			}
 
 			var got response
@@ -113,6 +144,32 @@ func Test_muxMap_Get(t *testing.T) {
 	}
 }
 
+func Test_muxMap_GetKey(t *testing.T) {
+	s := muxMap{
+		validater: validaterFunc(func(request) error {
+			return nil
+		}),
+		getter: getterMock{
+			getFunc: func(string) (interface{}, error) {
+				return "consumed", nil
+			},
+			viewFunc: func(_ string, consume bool, path string) (interface{}, error) {
+				assert.False(t, consume)
+				assert.Equal(t, "/a", path)
+				return "peeked", nil
+			},
+		},
+	}
+
+	value, err := s.GetKey("key", "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "consumed", value)
+
+	value, err = s.GetKey("key", "/a", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "peeked", value)
+}
+
 func Test_ozzoValidater_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -126,6 +183,30 @@ func Test_ozzoValidater_Validate(t *testing.T) {
 				Key: "key",
 			},
 		},
+		{
+			name: "valid path",
+			req: request{
+				Key:  "key",
+				Path: "$.a",
+			},
+		},
+		{
+			name: "invalid path",
+			req: request{
+				Key:  "key",
+				Path: "a",
+			},
+			wantErr: true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "path",
+						Message: "must be a JSON pointer or JSONPath expression",
+					},
+				},
+			},
+		},
 		{
 			name: "invalid key",
 			req: request{
@@ -218,3 +299,35 @@ func Test_sGetter_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_sGetter_View(t *testing.T) {
+	getter := &sGetter{
+		storage: storage.New(),
+	}
+
+	getter.storage.Set(context.Background(), "key", map[string]interface{}{"a": "b"})
+
+	got, err := getter.View("key", false, "/a")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", got)
+
+	_, err = getter.View("key", true, "/c")
+	assert.Equal(t, pathNotFoundResponse{Message: "path not found"}, err)
+
+	_, err = getter.View("key", true, "c")
+	assert.IsType(t, validationErrorResponse{}, err)
+
+	got, err = getter.View("key", true, "$.c")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{}, got)
+
+	_, _, err = getter.storage.Peek("key")
+	assert.Nil(t, err)
+
+	got, err = getter.View("key", true, "$.a")
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"b"}, got)
+
+	_, err = getter.View("key", false, "")
+	assert.Equal(t, notFoundResponse{Message: "key not found"}, err)
+}
@@ -0,0 +1,66 @@
+package hash
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewHSetHandler returns handler for hash field set requests.
+func NewHSetHandler(srv Hasher) http.HandlerFunc {
+	return handler(srv.HSet)
+}
+
+// NewHGetHandler returns handler for hash field get requests.
+func NewHGetHandler(srv Hasher) http.HandlerFunc {
+	return handler(srv.HGet)
+}
+
+// NewHDelHandler returns handler for hash fields delete requests.
+func NewHDelHandler(srv Hasher) http.HandlerFunc {
+	return handler(srv.HDel)
+}
+
+// NewHGetAllHandler returns handler for whole hash get requests.
+func NewHGetAllHandler(srv Hasher) http.HandlerFunc {
+	return handler(srv.HGetAll)
+}
+
+// NewHIncrByHandler returns handler for hash field increment requests.
+func NewHIncrByHandler(srv Hasher) http.HandlerFunc {
+	return handler(srv.HIncrBy)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type createdData struct {
+	Created bool `json:"created"`
+}
+
+type valueData struct {
+	Value interface{} `json:"value"`
+}
+
+type deletedData struct {
+	Deleted int `json:"deleted"`
+}
+
+type fieldsData struct {
+	Fields map[string]interface{} `json:"fields"`
+}
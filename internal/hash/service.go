@@ -0,0 +1,368 @@
+package hash
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	fieldSetMessage                = "field set"
+	fieldFoundMessage              = "field found"
+	fieldsDeletedMessage           = "fields deleted"
+	hashFoundMessage               = "hash found"
+	fieldIncrementedMessage        = "field incremented"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "key or field not found"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+	notIntegerMessage              = "field value is not an integer"
+	overflowMessage                = "increment or decrement would overflow"
+)
+
+// Hasher service for hash requests.
+type Hasher interface {
+	HSet(r *http.Request, resp *response) error
+	HGet(r *http.Request, resp *response) error
+	HDel(r *http.Request, resp *response) error
+	HGetAll(r *http.Request, resp *response) error
+	HIncrBy(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service, keys
+// and values are validated with the rules.
+func NewService(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules) Hasher {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		hasher: &sHasher{
+			storage: storage,
+		},
+		keyLiveTime: keyLiveTime,
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	hasher
+	keyLiveTime time.Duration
+}
+
+type request struct {
+	Key       string      `json:"key"`
+	Field     string      `json:"field"`
+	Fields    []string    `json:"fields"`
+	Value     interface{} `json:"value"`
+	Increment int64       `json:"increment"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type hasher interface {
+	HSet(ctx context.Context, key, field string, value interface{}) (bool, error)
+	HGet(key, field string) (interface{}, error)
+	HDel(key string, fields ...string) (int, error)
+	HGetAll(key string) (map[string]interface{}, error)
+	HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error)
+}
+
+func (s muxMap) HSet(r *http.Request, resp *response) error {
+	req, err := s.request(r, "field")
+	if err != nil {
+		return err
+	}
+
+	created, err := s.hasher.HSet(storage.Expire(s.keyLiveTime), req.Key, req.Field, req.Value)
+	if err != nil {
+		return errors.Wrap(err, "hset failed")
+	}
+
+	resp.Message = fieldSetMessage
+	resp.Data = createdData{Created: created}
+
+	return nil
+}
+
+func (s muxMap) HGet(r *http.Request, resp *response) error {
+	req, err := s.request(r, "field")
+	if err != nil {
+		return err
+	}
+
+	value, err := s.hasher.HGet(req.Key, req.Field)
+	if err != nil {
+		return errors.Wrap(err, "hget failed")
+	}
+
+	resp.Message = fieldFoundMessage
+	resp.Data = valueData{Value: value}
+
+	return nil
+}
+
+func (s muxMap) HDel(r *http.Request, resp *response) error {
+	req, err := s.request(r, "fields")
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.hasher.HDel(req.Key, req.Fields...)
+	if err != nil {
+		return errors.Wrap(err, "hdel failed")
+	}
+
+	resp.Message = fieldsDeletedMessage
+	resp.Data = deletedData{Deleted: deleted}
+
+	return nil
+}
+
+func (s muxMap) HGetAll(r *http.Request, resp *response) error {
+	req, err := s.request(r)
+	if err != nil {
+		return err
+	}
+
+	fields, err := s.hasher.HGetAll(req.Key)
+	if err != nil {
+		return errors.Wrap(err, "hgetall failed")
+	}
+
+	resp.Message = hashFoundMessage
+	resp.Data = fieldsData{Fields: fields}
+
+	return nil
+}
+
+func (s muxMap) HIncrBy(r *http.Request, resp *response) error {
+	req, err := s.request(r, "field")
+	if err != nil {
+		return err
+	}
+
+	value, err := s.hasher.HIncrBy(storage.Expire(s.keyLiveTime), req.Key, req.Field, req.Increment)
+	if err != nil {
+		return errors.Wrap(err, "hincrby failed")
+	}
+
+	resp.Message = fieldIncrementedMessage
+	resp.Data = valueData{Value: value}
+
+	return nil
+}
+
+// request decodes and validates request, key
+// is always required in addition to passed fields.
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	return decode.Request(r, req)
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	values := map[string]interface{}{
+		"field":  r.Field,
+		"fields": r.Fields,
+	}
+
+	for _, field := range append([]string{"key"}, required...) {
+		var err error
+		switch field {
+		case "key":
+			err = v.rules.Key(r.Key)
+		default:
+			err = validation.Validate(values[field], validation.Required)
+		}
+
+		if err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	if err := v.rules.Value(r.Value); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "value", Message: err.Error()},
+		)
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sHasher struct {
+	storage storage.Storage
+}
+
+func (h *sHasher) HSet(ctx context.Context, key, field string, value interface{}) (bool, error) {
+	created, err := h.storage.HSet(ctx, key, field, value)
+	return created, storageError(err)
+}
+
+func (h *sHasher) HGet(key, field string) (interface{}, error) {
+	value, err := h.storage.HGet(key, field)
+	return value, storageError(err)
+}
+
+func (h *sHasher) HDel(key string, fields ...string) (int, error) {
+	deleted, err := h.storage.HDel(key, fields...)
+	return deleted, storageError(err)
+}
+
+func (h *sHasher) HGetAll(key string) (map[string]interface{}, error) {
+	fields, err := h.storage.HGetAll(key)
+	return fields, storageError(err)
+}
+
+func (h *sHasher) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	value, err := h.storage.HIncrBy(ctx, key, field, incr)
+	return value, storageError(err)
+}
+
+// storageError converts storage errors
+// to the response errors.
+func storageError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case storage.ErrNotFound:
+		return notFoundResponse{Message: notFoundMessage}
+	case storage.ErrWrongType:
+		return wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	case storage.ErrNotInteger:
+		return notIntegerResponse{Message: notIntegerMessage}
+	case storage.ErrOverflow:
+		return notIntegerResponse{Message: overflowMessage}
+	default:
+		return err
+	}
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type notIntegerResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notIntegerResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notIntegerResponse) Code() responses.Code {
+	return responses.CodeNotInteger
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
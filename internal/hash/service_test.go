@@ -0,0 +1,253 @@
+package hash
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterFunc func(request, ...string) error
+
+func (f validaterFunc) Validate(r request, required ...string) error {
+	return f(r, required...)
+}
+
+type hasherMock struct {
+	err error
+}
+
+func (h hasherMock) HSet(context.Context, string, string, interface{}) (bool, error) {
+	return true, h.err
+}
+
+func (h hasherMock) HGet(string, string) (interface{}, error) {
+	return "value", h.err
+}
+
+func (h hasherMock) HDel(string, ...string) (int, error) {
+	return 1, h.err
+}
+
+func (h hasherMock) HGetAll(string) (map[string]interface{}, error) {
+	return map[string]interface{}{"field": "value"}, h.err
+}
+
+func (h hasherMock) HIncrBy(context.Context, string, string, int64) (int64, error) {
+	return 2, h.err
+}
+
+func Test_muxMap(t *testing.T) {
+	ok := func(*http.Request, *request) error { return nil }
+	valid := func(request, ...string) error { return nil }
+	mockErr := errors.New("mock error")
+
+	tests := []struct {
+		name         string
+		call         func(Hasher, *http.Request, *response) error
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request, ...string) error
+		hasherErr    error
+		wantErr      bool
+		expect       response
+	}{
+		{
+			name: "decoder error",
+			call: Hasher.HSet,
+			decodeFunc: func(*http.Request, *request) error {
+				return mockErr
+			},
+			wantErr: true,
+		},
+		{
+			name:       "validater error",
+			call:       Hasher.HGet,
+			decodeFunc: ok,
+			validateFunc: func(request, ...string) error {
+				return mockErr
+			},
+			wantErr: true,
+		},
+		{
+			name:         "hasher error",
+			call:         Hasher.HDel,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			hasherErr:    mockErr,
+			wantErr:      true,
+		},
+		{
+			name:         "hset",
+			call:         Hasher.HSet,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "field set", Data: createdData{Created: true}},
+		},
+		{
+			name:         "hget",
+			call:         Hasher.HGet,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "field found", Data: valueData{Value: "value"}},
+		},
+		{
+			name:         "hdel",
+			call:         Hasher.HDel,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "fields deleted", Data: deletedData{Deleted: 1}},
+		},
+		{
+			name:         "hgetall",
+			call:         Hasher.HGetAll,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "hash found", Data: fieldsData{Fields: map[string]interface{}{"field": "value"}}},
+		},
+		{
+			name:         "hincrby",
+			call:         Hasher.HIncrBy,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "field incremented", Data: valueData{Value: int64(2)}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderFunc(tt.decodeFunc),
+				validater: validaterFunc(tt.validateFunc),
+				hasher:    hasherMock{err: tt.hasherErr},
+			}
+
+			var got response
+			req := httptest.NewRequest("POST", "http://any", nil)
+			err := tt.call(s, req, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      request
+		required []string
+		wantErr  bool
+		expect   validationErrorResponse
+	}{
+		{
+			name:     "valid",
+			req:      request{Key: "key", Field: "field"},
+			required: []string{"field"},
+		},
+		{
+			name:     "invalid key and fields",
+			req:      request{Field: "field"},
+			required: []string{"fields"},
+			wantErr:  true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "key",
+						Message: "cannot be blank",
+					},
+					validationError{
+						Field:   "fields",
+						Message: "cannot be blank",
+					},
+				},
+			},
+		},
+		{
+			name:     "value too large",
+			req:      request{Key: "key", Field: "field", Value: "large value"},
+			required: []string{"field"},
+			wantErr:  true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "value",
+						Message: "the size must be no more than 8 bytes",
+					},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{
+		rules: validate.Rules{MaxValueSize: 8},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validater.Validate(tt.req, tt.required...)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				got, ok := err.(validationErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expect, got)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_sHasher(t *testing.T) {
+	h := &sHasher{
+		storage: storage.New(),
+	}
+
+	h.storage.Set(context.Background(), "string", "value")
+
+	_, err := h.HGet("hash", "field")
+	assert.Equal(t, notFoundResponse{Message: "key or field not found"}, err)
+
+	_, err = h.HSet(context.Background(), "string", "field", "value")
+	assert.Equal(t, wrongTypeResponse{Message: "key holds the wrong kind of value"}, err)
+
+	_, err = h.HSet(context.Background(), "hash", "field", "value")
+	assert.Nil(t, err)
+
+	_, err = h.HIncrBy(context.Background(), "hash", "field", 1)
+	assert.Equal(t, notIntegerResponse{Message: "field value is not an integer"}, err)
+
+	_, err = h.HIncrBy(context.Background(), "hash", "counter", math.MaxInt64)
+	assert.Nil(t, err)
+
+	_, err = h.HIncrBy(context.Background(), "hash", "counter", 1)
+	assert.Equal(t, notIntegerResponse{Message: "increment or decrement would overflow"}, err)
+}
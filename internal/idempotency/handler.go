@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewBeginHandler returns handler for request begin requests.
+func NewBeginHandler(srv Keeper) http.HandlerFunc {
+	return handler(srv.Begin)
+}
+
+// NewCompleteHandler returns handler for response store requests.
+func NewCompleteHandler(srv Keeper) http.HandlerFunc {
+	return handler(srv.Complete)
+}
+
+// NewAbortHandler returns handler for request abort requests.
+func NewAbortHandler(srv Keeper) http.HandlerFunc {
+	return handler(srv.Abort)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
@@ -0,0 +1,275 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	idem "github.com/romanyx/integral_db/pkg/idempotency"
+)
+
+const (
+	startedMessage                 = "request started"
+	foundMessage                   = "response found"
+	completedMessage               = "response stored"
+	abortedMessage                 = "request aborted"
+	validationErrorResponseMessage = "you have validation errors"
+	ttlMessage                     = "must be a positive duration like 24h"
+	responseMessage                = "must be a response with valid HTTP status"
+)
+
+// Keeper service for idempotency requests.
+type Keeper interface {
+	Begin(r *http.Request, resp *response) error
+	Complete(r *http.Request, resp *response) error
+	Abort(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, rules validate.Rules) Keeper {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		store: NewStore(storage),
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	store
+}
+
+type request struct {
+	Key         string `json:"-"`
+	Fingerprint string `json:"fingerprint"`
+	// TTL is lease of the begun request or lifetime
+	// of the stored response, a duration like "24h".
+	TTL      string         `json:"ttl"`
+	Response *idem.Response `json:"response"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type store interface {
+	idem.Store
+}
+
+func (s muxMap) Begin(r *http.Request, resp *response) error {
+	req, err := s.request(r, "fingerprint", "ttl")
+	if err != nil {
+		return err
+	}
+
+	ttl, _ := time.ParseDuration(req.TTL)
+	stored, err := s.store.Begin(r.Context(), req.Key, req.Fingerprint, ttl)
+	if err != nil {
+		return errors.Wrap(storageError(err), "begin failed")
+	}
+
+	if stored != nil {
+		resp.Message = foundMessage
+		resp.Data = stored
+		return nil
+	}
+
+	resp.Message = startedMessage
+
+	return nil
+}
+
+func (s muxMap) Complete(r *http.Request, resp *response) error {
+	req, err := s.request(r, "fingerprint", "ttl", "response")
+	if err != nil {
+		return err
+	}
+
+	ttl, _ := time.ParseDuration(req.TTL)
+	if err := s.store.Complete(r.Context(), req.Key, req.Fingerprint, *req.Response, ttl); err != nil {
+		return errors.Wrap(storageError(err), "complete failed")
+	}
+
+	resp.Message = completedMessage
+
+	return nil
+}
+
+func (s muxMap) Abort(r *http.Request, resp *response) error {
+	req, err := s.request(r, "fingerprint")
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Abort(r.Context(), req.Key, req.Fingerprint); err != nil {
+		return errors.Wrap(storageError(err), "abort failed")
+	}
+
+	resp.Message = abortedMessage
+
+	return nil
+}
+
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	if err := decode.Request(r, req); err != nil {
+		return err
+	}
+
+	req.Key = mux.Vars(r)["key"]
+
+	return nil
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	for _, field := range append([]string{"key"}, required...) {
+		var err error
+		switch field {
+		case "key":
+			err = v.rules.Key(r.Key)
+		case "fingerprint":
+			err = validation.Validate(r.Fingerprint, validation.Required)
+		case "ttl":
+			if ttl, parseErr := time.ParseDuration(r.TTL); parseErr != nil || ttl <= 0 {
+				err = errors.New(ttlMessage)
+			}
+		case "response":
+			if r.Response == nil || r.Response.Status < 100 || r.Response.Status > 599 {
+				err = errors.New(responseMessage)
+			}
+		}
+
+		if err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+// requestCodes maps codes of the
+// store errors to response codes.
+var requestCodes = map[idem.Code]responses.Code{
+	idem.CodeInFlight:   responses.CodeRequestInFlight,
+	idem.CodeMismatch:   responses.CodeRequestMismatch,
+	idem.CodeNotStarted: responses.CodeRequestNotStarted,
+}
+
+// storageError converts errors of the store and quota
+// errors of the storage to the response errors.
+func storageError(err error) error {
+	if e, ok := err.(*idem.Error); ok {
+		if code, ok := requestCodes[e.Code()]; ok {
+			return requestErrorResponse{Message: e.Message, code: code}
+		}
+	}
+
+	switch err {
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type requestErrorResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r requestErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r requestErrorResponse) Code() responses.Code {
+	return r.code
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	idem "github.com/romanyx/integral_db/pkg/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+func Test_muxMap(t *testing.T) {
+	var req request
+
+	s := muxMap{
+		decoder: decoderFunc(func(_ *http.Request, r *request) error {
+			*r = req
+			return nil
+		}),
+		validater: ozzoValidater{rules: validate.Rules{}},
+		store:     NewStore(storage.New()),
+	}
+
+	serve := func(serve func(*http.Request, *response) error, r request) (response, error) {
+		req = r
+
+		var got response
+		err := serve(httptest.NewRequest("POST", "http://any", nil), &got)
+		return got, err
+	}
+
+	got, err := serve(s.Begin, request{Key: "pay-1", Fingerprint: "f1", TTL: "1h"})
+	assert.Nil(t, err)
+	assert.Equal(t, response{Message: "request started"}, got)
+
+	resp := &idem.Response{Status: http.StatusCreated, Body: []byte("paid")}
+	got, err = serve(s.Complete, request{Key: "pay-1", Fingerprint: "f1", TTL: "1h", Response: resp})
+	assert.Nil(t, err)
+	assert.Equal(t, response{Message: "response stored"}, got)
+
+	got, err = serve(s.Begin, request{Key: "pay-1", Fingerprint: "f1", TTL: "1h"})
+	assert.Nil(t, err)
+	assert.Equal(t, response{Message: "response found", Data: resp}, got)
+
+	_, err = serve(s.Abort, request{Key: "pay-1", Fingerprint: "f1"})
+	assert.Equal(t, requestErrorResponse{
+		Message: "request with the key is not in progress",
+		code:    responses.CodeRequestNotStarted,
+	}, errors.Cause(err))
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      request
+		required []string
+		expect   error
+	}{
+		{
+			name:     "valid",
+			req:      request{Key: "pay-1", Fingerprint: "f1", TTL: "24h", Response: &idem.Response{Status: 201}},
+			required: []string{"fingerprint", "ttl", "response"},
+		},
+		{
+			name:     "invalid",
+			req:      request{Key: "pay-1", TTL: "forever", Response: &idem.Response{Status: 42}},
+			required: []string{"fingerprint", "ttl", "response"},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "fingerprint", Message: "cannot be blank"},
+					validationError{Field: "ttl", Message: "must be a positive duration like 24h"},
+					validationError{Field: "response", Message: "must be a response with valid HTTP status"},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{rules: validate.Rules{}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, validater.Validate(tt.req, tt.required...))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	idem "github.com/romanyx/integral_db/pkg/idempotency"
+)
+
+// recordKey is key of the record, it is not a string,
+// so records are out of reach of other key requests.
+type recordKey struct {
+	key string
+}
+
+// record is a value stored under the key,
+// response is nil while request is in progress.
+type record struct {
+	Fingerprint string         `json:"fingerprint"`
+	Response    *idem.Response `json:"response,omitempty"`
+}
+
+// Size implements the storage.Sizer interface,
+// so records are limited by quotas of bytes.
+func (r record) Size() int {
+	size := len(r.Fingerprint)
+	if r.Response == nil {
+		return size
+	}
+
+	for name, values := range r.Response.Header {
+		size += len(name)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+
+	return size + len(r.Response.Body) + 8
+}
+
+// NewStore returns the store keeping requests in s,
+// the keys are reserved with conditional set.
+func NewStore(s storage.Storage) idem.Store {
+	return &sStore{storage: s}
+}
+
+type sStore struct {
+	storage storage.Storage
+}
+
+func (s *sStore) Begin(ctx context.Context, key, fingerprint string, lease time.Duration) (*idem.Response, error) {
+	for {
+		ok, err := s.storage.SetIf(storage.Expire(lease), recordKey{key}, record{Fingerprint: fingerprint}, storage.IfNotExists)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		rec, _, err := s.lookup(key)
+		// Key has expired since the set,
+		// so it is reserved once more.
+		if err == storage.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case rec.Fingerprint != fingerprint:
+			return nil, idem.ErrMismatch
+		case rec.Response == nil:
+			return nil, idem.ErrInFlight
+		default:
+			return rec.Response, nil
+		}
+	}
+}
+
+func (s *sStore) Complete(ctx context.Context, key, fingerprint string, resp idem.Response, ttl time.Duration) error {
+	rec, version, err := s.inFlight(key, fingerprint)
+	if err != nil {
+		return err
+	}
+
+	rec.Response = &resp
+	switch err := s.storage.CompareAndSet(storage.Expire(ttl), recordKey{key}, rec, version); err {
+	case storage.ErrNotFound, storage.ErrVersionMismatch:
+		return idem.ErrNotStarted
+	default:
+		return err
+	}
+}
+
+func (s *sStore) Abort(ctx context.Context, key, fingerprint string) error {
+	if _, _, err := s.inFlight(key, fingerprint); err != nil {
+		return err
+	}
+
+	s.storage.Del(recordKey{key})
+
+	return nil
+}
+
+// inFlight returns record of the request
+// in progress under the key and its version.
+func (s *sStore) inFlight(key, fingerprint string) (record, uint64, error) {
+	rec, version, err := s.lookup(key)
+	switch {
+	case err == storage.ErrNotFound, err == idem.ErrMismatch:
+		return rec, 0, idem.ErrNotStarted
+	case err != nil:
+		return rec, 0, err
+	case rec.Fingerprint != fingerprint || rec.Response != nil:
+		return rec, 0, idem.ErrNotStarted
+	}
+
+	return rec, version, nil
+}
+
+// lookup returns record stored under the key,
+// records of other values are mismatched.
+func (s *sStore) lookup(key string) (record, uint64, error) {
+	value, info, err := s.storage.Peek(recordKey{key})
+	switch err {
+	case nil:
+	case storage.ErrWrongType:
+		return record{}, 0, idem.ErrMismatch
+	default:
+		return record{}, 0, err
+	}
+
+	rec, ok := value.(record)
+	if !ok {
+		return record{}, 0, idem.ErrMismatch
+	}
+
+	return rec, info.Version, nil
+}
@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	idem "github.com/romanyx/integral_db/pkg/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sStore(t *testing.T) {
+	s := storage.New()
+	store := NewStore(s)
+	ctx := context.Background()
+
+	t.Log("Given initialized store.")
+	{
+		t.Log("\t Test: 0\t When request begins, should mark it in progress.")
+		{
+			stored, err := store.Begin(ctx, "pay-1", "f1", time.Hour)
+			assert.Nil(t, err)
+			assert.Nil(t, stored)
+
+			_, err = store.Begin(ctx, "pay-1", "f1", time.Hour)
+			assert.Equal(t, idem.ErrInFlight, err)
+
+			_, err = store.Begin(ctx, "pay-1", "f2", time.Hour)
+			assert.Equal(t, idem.ErrMismatch, err)
+		}
+
+		t.Log("\t Test: 1\t When request completes, should replay its response.")
+		{
+			resp := idem.Response{Status: http.StatusCreated, Body: []byte("payment")}
+
+			assert.Equal(t, idem.ErrNotStarted, store.Complete(ctx, "pay-1", "f2", resp, time.Hour))
+			assert.Nil(t, store.Complete(ctx, "pay-1", "f1", resp, time.Hour))
+			assert.Equal(t, idem.ErrNotStarted, store.Complete(ctx, "pay-1", "f1", resp, time.Hour))
+
+			stored, err := store.Begin(ctx, "pay-1", "f1", time.Hour)
+			assert.Nil(t, err)
+			assert.Equal(t, &resp, stored)
+
+			assert.Equal(t, idem.ErrNotStarted, store.Abort(ctx, "pay-1", "f1"))
+		}
+
+		t.Log("\t Test: 2\t When request aborts, should begin it again.")
+		{
+			_, err := store.Begin(ctx, "pay-2", "f1", time.Hour)
+			assert.Nil(t, err)
+
+			assert.Nil(t, store.Abort(ctx, "pay-2", "f1"))
+			assert.Equal(t, idem.ErrNotStarted, store.Abort(ctx, "pay-2", "f1"))
+
+			stored, err := store.Begin(ctx, "pay-2", "f1", time.Hour)
+			assert.Nil(t, err)
+			assert.Nil(t, stored)
+		}
+
+		t.Log("\t Test: 3\t When key is used by other requests, should keep records apart.")
+		{
+			s.Set(ctx, "pay-1", "value")
+			s.Del("pay-2")
+
+			stored, err := store.Begin(ctx, "pay-1", "f1", time.Hour)
+			assert.Nil(t, err)
+			assert.NotNil(t, stored)
+
+			_, err = store.Begin(ctx, "pay-2", "f2", time.Hour)
+			assert.Equal(t, idem.ErrMismatch, err)
+
+			value, err := s.Get("pay-1")
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+		}
+
+		t.Log("\t Test: 4\t When lease expires, should begin request again.")
+		{
+			_, err := store.Begin(ctx, "pay-3", "f1", time.Millisecond)
+			assert.Nil(t, err)
+
+			assert.Eventually(t, func() bool {
+				_, err := store.Begin(ctx, "pay-3", "f1", time.Hour)
+				return err == nil
+			}, time.Second, time.Millisecond)
+
+			resp := idem.Response{Status: http.StatusCreated}
+			assert.Nil(t, store.Complete(ctx, "pay-3", "f1", resp, time.Hour))
+		}
+	}
+}
+
+func Test_sStore_quota(t *testing.T) {
+	ns := storage.New().Namespace("tenant")
+	ns.SetQuota(storage.Quota{MaxBytes: 64})
+	store := NewStore(ns)
+	ctx := context.Background()
+
+	_, err := store.Begin(ctx, "pay-1", "f1", time.Hour)
+	assert.Nil(t, err)
+
+	resp := idem.Response{Status: http.StatusCreated, Body: make([]byte, 64)}
+	assert.Equal(t, storage.ErrBytesQuota, store.Complete(ctx, "pay-1", "f1", resp, time.Hour))
+
+	resp.Body = []byte("paid")
+	assert.Nil(t, store.Complete(ctx, "pay-1", "f1", resp, time.Hour))
+	assert.Equal(t, storage.Stats{Keys: 1, Bytes: 14}, ns.Stats())
+}
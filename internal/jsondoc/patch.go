@@ -0,0 +1,338 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+var (
+	// ErrInvalidOperation returns when JSON Patch
+	// operation is unknown or misses its members.
+	ErrInvalidOperation = errors.New("invalid operation")
+	// ErrTestFailed returns when value of the JSON
+	// Patch test operation does not match.
+	ErrTestFailed = errors.New("test failed")
+)
+
+// Operation is a RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+
+	// hasPath and hasValue distinguish
+	// empty path and null value from
+	// the missing ones.
+	hasPath  bool
+	hasValue bool
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *Operation) UnmarshalJSON(b []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+
+	*o = Operation{}
+
+	for name, dst := range map[string]interface{}{
+		"op":    &o.Op,
+		"path":  &o.Path,
+		"from":  &o.From,
+		"value": &o.Value,
+	} {
+		raw, ok := members[name]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, dst); err != nil {
+			return err
+		}
+	}
+
+	_, o.hasPath = members["path"]
+	_, o.hasValue = members["value"]
+
+	return nil
+}
+
+// OperationError describes JSON Patch
+// operation which can't be applied.
+type OperationError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Err)
+}
+
+// Patch applies RFC 6902 JSON Patch operations to the
+// document and returns the patched copy, document itself
+// is not modified. Operations are applied atomically, on
+// any error original document stays intact.
+func Patch(doc interface{}, ops []Operation) (interface{}, error) {
+	doc = Copy(doc)
+
+	for i, op := range ops {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, &OperationError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+
+	return doc, nil
+}
+
+func (o Operation) apply(doc interface{}) (interface{}, error) {
+	if !o.hasPath {
+		return nil, ErrInvalidOperation
+	}
+
+	path, err := ParsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add", "replace", "test":
+		if !o.hasValue {
+			return nil, ErrInvalidOperation
+		}
+	case "move", "copy":
+		from, err := ParsePointer(o.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.Op == "move" {
+			if from.isPrefixOf(path) {
+				return nil, ErrInvalidOperation
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			value = Copy(value)
+		}
+
+		return add(doc, path, value)
+	}
+
+	switch o.Op {
+	case "add":
+		return add(doc, path, o.Value)
+	case "remove":
+		return remove(doc, path)
+	case "replace":
+		if doc, err = remove(doc, path); err != nil {
+			return nil, err
+		}
+		return add(doc, path, o.Value)
+	case "test":
+		value, err := path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(value, o.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	default:
+		return nil, ErrInvalidOperation
+	}
+}
+
+func add(doc interface{}, path Pointer, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return path.mutate(doc, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+			i, err := index(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, ErrPathNotFound
+		}
+	})
+}
+
+func remove(doc interface{}, path Pointer) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	return path.mutate(doc, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, ErrPathNotFound
+			}
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			i, err := index(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		default:
+			return nil, ErrPathNotFound
+		}
+	})
+}
+
+// MergePatch applies RFC 7396 JSON merge patch to the
+// target and returns the result, target itself is not
+// modified.
+func MergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	merged := make(map[string]interface{}, len(t)+len(p))
+	if ok {
+		for key, value := range t {
+			merged[key] = value
+		}
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = MergePatch(merged[key], value)
+	}
+
+	return merged
+}
+
+// Copy returns deep copy of the decoded JSON value.
+func Copy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			c[key] = Copy(value)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, value := range v {
+			c[i] = Copy(value)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// equal reports whether decoded values are equal,
+// numbers are compared by value whatever their types,
+// so integers of MessagePack or CBOR match JSON ones.
+func equal(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x.Cmp(y) == 0
+	}
+
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// toFloat converts numeric value to the exact big
+// float, ok is false for other values and NaN.
+func toFloat(v interface{}) (*big.Float, bool) {
+	f := new(big.Float)
+
+	switch n := v.(type) {
+	case int:
+		f.SetInt64(int64(n))
+	case int8:
+		f.SetInt64(int64(n))
+	case int16:
+		f.SetInt64(int64(n))
+	case int32:
+		f.SetInt64(int64(n))
+	case int64:
+		f.SetInt64(n)
+	case uint:
+		f.SetUint64(uint64(n))
+	case uint8:
+		f.SetUint64(uint64(n))
+	case uint16:
+		f.SetUint64(uint64(n))
+	case uint32:
+		f.SetUint64(uint64(n))
+	case uint64:
+		f.SetUint64(n)
+	case float32:
+		return toFloat(float64(n))
+	case float64:
+		if math.IsNaN(n) {
+			return nil, false
+		}
+		f.SetFloat64(n)
+	default:
+		return nil, false
+	}
+
+	return f, true
+}
@@ -0,0 +1,217 @@
+package jsondoc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func Test_Patch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		wantErr error
+		expect  string
+	}{
+		{
+			name:   "add replace remove",
+			doc:    `{"a": 1, "b": [1, 2]}`,
+			patch:  `[{"op": "add", "path": "/c", "value": null}, {"op": "replace", "path": "/a", "value": 2}, {"op": "remove", "path": "/b/0"}, {"op": "add", "path": "/b/-", "value": 3}, {"op": "add", "path": "/b/0", "value": 0}]`,
+			expect: `{"a": 2, "b": [0, 2, 3], "c": null}`,
+		},
+		{
+			name:   "move copy test",
+			doc:    `{"a": {"x": 1}, "b": {}}`,
+			patch:  `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "move", "from": "/a/x", "path": "/b/y"}, {"op": "test", "path": "/c/x", "value": 1}]`,
+			expect: `{"a": {}, "b": {"y": 1}, "c": {"x": 1}}`,
+		},
+		{
+			name:   "escaped pointer and root",
+			doc:    `{"a/b": {"~c": 1}}`,
+			patch:  `[{"op": "replace", "path": "/a~1b/~0c", "value": 2}]`,
+			expect: `{"a/b": {"~c": 2}}`,
+		},
+		{
+			name:    "test failed",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "remove", "path": "/a"}, {"op": "test", "path": "/a", "value": 1}]`,
+			wantErr: ErrPathNotFound,
+		},
+		{
+			name:    "test value mismatch",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "test", "path": "/a", "value": 2}]`,
+			wantErr: ErrTestFailed,
+		},
+		{
+			name:    "missing path",
+			doc:     `{}`,
+			patch:   `[{"op": "remove"}]`,
+			wantErr: ErrInvalidOperation,
+		},
+		{
+			name:    "missing value",
+			doc:     `{}`,
+			patch:   `[{"op": "add", "path": "/a"}]`,
+			wantErr: ErrInvalidOperation,
+		},
+		{
+			name:    "unknown op",
+			doc:     `{}`,
+			patch:   `[{"op": "merge", "path": "/a"}]`,
+			wantErr: ErrInvalidOperation,
+		},
+		{
+			name:    "move into itself",
+			doc:     `{"a": {}}`,
+			patch:   `[{"op": "move", "from": "/a", "path": "/a/b"}]`,
+			wantErr: ErrInvalidOperation,
+		},
+		{
+			name:    "index out of range",
+			doc:     `[1]`,
+			patch:   `[{"op": "add", "path": "/2", "value": 1}]`,
+			wantErr: ErrInvalidIndex,
+		},
+		{
+			name:    "invalid pointer",
+			doc:     `{}`,
+			patch:   `[{"op": "add", "path": "a", "value": 1}]`,
+			wantErr: ErrInvalidPointer,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ops []Operation
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatal(err)
+			}
+
+			doc := decode(t, tt.doc)
+			original := Copy(doc)
+
+			got, err := Patch(doc, ops)
+
+			assert.Equal(t, original, doc)
+
+			if tt.wantErr != nil {
+				opErr, ok := err.(*OperationError)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErr, opErr.Err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, decode(t, tt.expect), got)
+		})
+	}
+}
+
+func Test_MergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		expect string
+	}{
+		{
+			name:   "merge nested",
+			target: `{"a": "b", "c": {"d": "e", "f": "g"}}`,
+			patch:  `{"a": "z", "c": {"f": null}}`,
+			expect: `{"a": "z", "c": {"d": "e"}}`,
+		},
+		{
+			name:   "replace non object",
+			target: `{"a": [1]}`,
+			patch:  `{"a": {"b": 1}}`,
+			expect: `{"a": {"b": 1}}`,
+		},
+		{
+			name:   "patch is not object",
+			target: `{"a": 1}`,
+			patch:  `["a"]`,
+			expect: `["a"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := decode(t, tt.target)
+			original := Copy(target)
+
+			got := MergePatch(target, decode(t, tt.patch))
+
+			assert.Equal(t, original, target)
+			assert.Equal(t, decode(t, tt.expect), got)
+		})
+	}
+}
+
+func Test_equal(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   interface{}
+		expect bool
+	}{
+		{name: "int and float", a: int64(1), b: 1.0, expect: true},
+		{name: "uint and float", a: uint64(1), b: 1.0, expect: true},
+		{name: "int and uint", a: int8(-1), b: uint64(1<<64 - 1)},
+		{name: "large ints", a: int64(1<<53 + 1), b: uint64(1<<53 + 1), expect: true},
+		{name: "large int and float", a: int64(1<<53 + 1), b: float64(1 << 53)},
+		{name: "fraction", a: int64(1), b: 1.5},
+		{name: "number and string", a: int64(1), b: "1"},
+		{
+			name:   "nested",
+			a:      map[string]interface{}{"a": []interface{}{int64(1), "b"}},
+			b:      map[string]interface{}{"a": []interface{}{1.0, "b"}},
+			expect: true,
+		},
+		{
+			name: "nested other",
+			a:    map[string]interface{}{"a": []interface{}{int64(1)}},
+			b:    map[string]interface{}{"a": []interface{}{2.0}},
+		},
+		{name: "missing key", a: map[string]interface{}{"a": nil}, b: map[string]interface{}{"b": nil}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, equal(tt.a, tt.b))
+		})
+	}
+}
+
+func Test_Patch_testDecodedIntegers(t *testing.T) {
+	doc := map[string]interface{}{"a": uint64(1), "b": int64(-2)}
+	var ops []Operation
+	err := json.Unmarshal([]byte(`[
+		{"op": "test", "path": "/a", "value": 1},
+		{"op": "test", "path": "/b", "value": -2}
+	]`), &ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Patch(doc, ops)
+	assert.Nil(t, err)
+	assert.Equal(t, doc, got)
+}
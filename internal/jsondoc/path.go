@@ -0,0 +1,348 @@
+package jsondoc
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPath returns when path is neither
+	// JSON pointer nor JSONPath expression.
+	ErrInvalidPath = errors.New("invalid path")
+)
+
+// Selector selects fragment of the document.
+type Selector interface {
+	Select(doc interface{}) (interface{}, error)
+}
+
+// ParsePath parses path which is either RFC 6901
+// JSON pointer, empty or starting with "/", or
+// JSONPath expression starting with "$".
+func ParsePath(path string) (Selector, error) {
+	if strings.HasPrefix(path, "$") {
+		return ParseJSONPath(path)
+	}
+
+	p, err := ParsePointer(path)
+	if err != nil {
+		return nil, ErrInvalidPath
+	}
+
+	return p, nil
+}
+
+// Select implements the Selector interface.
+func (p Pointer) Select(doc interface{}) (interface{}, error) {
+	return p.Get(doc)
+}
+
+// JSONPath is a parsed JSONPath expression. Supported
+// are dot and bracket child names, wildcards, array
+// indexes, slices, unions and recursive descent. It
+// always selects a list of matched values.
+type JSONPath []step
+
+type step struct {
+	recursive bool
+	selectors []selector
+}
+
+type selector interface {
+	apply(node interface{}) []interface{}
+}
+
+// ParseJSONPath parses JSONPath expression.
+func ParseJSONPath(path string) (JSONPath, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, ErrInvalidPath
+	}
+
+	var steps JSONPath
+	rest := path[1:]
+	for rest != "" {
+		var (
+			s   step
+			err error
+		)
+
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			s.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				s.selectors, rest, err = parseBracket(rest)
+			} else {
+				s.selectors, rest, err = parseDot(rest)
+			}
+		case strings.HasPrefix(rest, "."):
+			s.selectors, rest, err = parseDot(rest[1:])
+		case strings.HasPrefix(rest, "["):
+			s.selectors, rest, err = parseBracket(rest)
+		default:
+			err = ErrInvalidPath
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, s)
+	}
+
+	return steps, nil
+}
+
+// Select implements the Selector interface.
+func (p JSONPath) Select(doc interface{}) (interface{}, error) {
+	nodes := []interface{}{doc}
+	for _, s := range p {
+		candidates := nodes
+		if s.recursive {
+			candidates = descendants(nodes)
+		}
+
+		next := []interface{}{}
+		for _, node := range candidates {
+			for _, sel := range s.selectors {
+				next = append(next, sel.apply(node)...)
+			}
+		}
+		nodes = next
+	}
+
+	return nodes, nil
+}
+
+func parseDot(rest string) ([]selector, string, error) {
+	end := strings.IndexAny(rest, ".[")
+	if end == -1 {
+		end = len(rest)
+	}
+
+	name := rest[:end]
+	switch name {
+	case "":
+		return nil, "", ErrInvalidPath
+	case "*":
+		return []selector{wildcard{}}, rest[end:], nil
+	default:
+		return []selector{child(name)}, rest[end:], nil
+	}
+}
+
+func parseBracket(rest string) ([]selector, string, error) {
+	var selectors []selector
+
+	rest = rest[1:]
+	for {
+		rest = strings.TrimLeft(rest, " ")
+
+		var (
+			sel selector
+			err error
+		)
+
+		switch {
+		case strings.HasPrefix(rest, "*"):
+			sel, rest = wildcard{}, rest[1:]
+		case strings.HasPrefix(rest, "'"), strings.HasPrefix(rest, `"`):
+			sel, rest, err = parseQuoted(rest)
+		default:
+			sel, rest, err = parseIndex(rest)
+		}
+
+		if err != nil {
+			return nil, "", err
+		}
+		selectors = append(selectors, sel)
+
+		rest = strings.TrimLeft(rest, " ")
+		switch {
+		case strings.HasPrefix(rest, ","):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "]"):
+			return selectors, rest[1:], nil
+		default:
+			return nil, "", ErrInvalidPath
+		}
+	}
+}
+
+func parseQuoted(rest string) (selector, string, error) {
+	quote := rest[0]
+
+	var name strings.Builder
+	for i := 1; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case c == '\\' && i+1 < len(rest):
+			i++
+			name.WriteByte(rest[i])
+		case c == quote:
+			return child(name.String()), rest[i+1:], nil
+		default:
+			name.WriteByte(c)
+		}
+	}
+
+	return nil, "", ErrInvalidPath
+}
+
+func parseIndex(rest string) (selector, string, error) {
+	end := strings.IndexAny(rest, ",]")
+	if end == -1 {
+		return nil, "", ErrInvalidPath
+	}
+
+	expr := strings.TrimSpace(rest[:end])
+	parts := strings.Split(expr, ":")
+	if len(parts) > 3 {
+		return nil, "", ErrInvalidPath
+	}
+
+	bounds := make([]*int, len(parts))
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, "", ErrInvalidPath
+		}
+		bounds[i] = &n
+	}
+
+	if len(parts) == 1 {
+		if bounds[0] == nil {
+			return nil, "", ErrInvalidPath
+		}
+		return element(*bounds[0]), rest[end:], nil
+	}
+
+	s := slice{start: bounds[0], end: bounds[1]}
+	if len(parts) == 3 {
+		s.step = bounds[2]
+	}
+
+	return s, rest[end:], nil
+}
+
+// descendants returns nodes with all their
+// descendants, object members are visited
+// in order of their names.
+func descendants(nodes []interface{}) []interface{} {
+	var all []interface{}
+	for _, node := range nodes {
+		all = append(all, node)
+		all = append(all, descendants(wildcard{}.apply(node))...)
+	}
+
+	return all
+}
+
+type child string
+
+func (c child) apply(node interface{}) []interface{} {
+	if n, ok := node.(map[string]interface{}); ok {
+		if value, ok := n[string(c)]; ok {
+			return []interface{}{value}
+		}
+	}
+
+	return nil
+}
+
+type wildcard struct{}
+
+func (wildcard) apply(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(n))
+		for name := range n {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		values := make([]interface{}, len(names))
+		for i, name := range names {
+			values[i] = n[name]
+		}
+		return values
+	case []interface{}:
+		return n
+	default:
+		return nil
+	}
+}
+
+type element int
+
+func (e element) apply(node interface{}) []interface{} {
+	n, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	i := int(e)
+	if i < 0 {
+		i += len(n)
+	}
+	if i < 0 || i >= len(n) {
+		return nil
+	}
+
+	return []interface{}{n[i]}
+}
+
+type slice struct {
+	start, end, step *int
+}
+
+func (s slice) apply(node interface{}) []interface{} {
+	n, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	step := 1
+	if s.step != nil {
+		step = *s.step
+	}
+	if step <= 0 {
+		return nil
+	}
+
+	start, end := 0, len(n)
+	if s.start != nil {
+		start = clamp(*s.start, len(n))
+	}
+	if s.end != nil {
+		end = clamp(*s.end, len(n))
+	}
+
+	var values []interface{}
+	for i := start; i < end; i += step {
+		values = append(values, n[i])
+	}
+
+	return values
+}
+
+// clamp normalizes negative slice
+// bound and limits it to length.
+func clamp(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+
+	return i
+}
@@ -0,0 +1,60 @@
+package jsondoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParsePath_Select(t *testing.T) {
+	doc := decode(t, `{
+		"store": {
+			"book": [
+				{"title": "a", "price": 8},
+				{"title": "b", "price": 12},
+				{"title": "c", "price": 9}
+			],
+			"bicycle": {"price": 20}
+		},
+		"odd key": 1
+	}`)
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+		expect  interface{}
+	}{
+		{name: "pointer", path: "/store/book/1/title", expect: "b"},
+		{name: "pointer not found", path: "/store/car", wantErr: ErrPathNotFound},
+		{name: "child", path: "$.store.bicycle.price", expect: []interface{}{20.0}},
+		{name: "bracket", path: "$['odd key']", expect: []interface{}{1.0}},
+		{name: "index", path: "$.store.book[-1].title", expect: []interface{}{"c"}},
+		{name: "wildcard", path: "$.store.book[*].title", expect: []interface{}{"a", "b", "c"}},
+		{name: "slice", path: "$.store.book[:2].title", expect: []interface{}{"a", "b"}},
+		{name: "union", path: `$.store.book[0,2]["title"]`, expect: []interface{}{"a", "c"}},
+		{name: "recursive", path: "$..price", expect: []interface{}{20.0, 8.0, 12.0, 9.0}},
+		{name: "no match", path: "$.store.car", expect: []interface{}{}},
+		{name: "invalid pointer", path: "store", wantErr: ErrInvalidPath},
+		{name: "invalid bracket", path: "$.store[book", wantErr: ErrInvalidPath},
+		{name: "invalid dot", path: "$.", wantErr: ErrInvalidPath},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := ParsePath(tt.path)
+			if err == nil {
+				var got interface{}
+				got, err = s.Select(doc)
+				if tt.wantErr == nil {
+					assert.Equal(t, tt.expect, got)
+				}
+			}
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package jsondoc
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPointer returns when JSON pointer
+	// does not conform RFC 6901 syntax.
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	// ErrPathNotFound returns when document has
+	// no value at the path.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrInvalidIndex returns when array index
+	// is malformed or out of range.
+	ErrInvalidIndex = errors.New("invalid array index")
+)
+
+var (
+	escapes   = strings.NewReplacer("~0", "", "~1", "")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// Pointer is a parsed RFC 6901 JSON pointer.
+type Pointer []string
+
+// ParsePointer parses JSON pointer, empty
+// string points to the whole document.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+
+	if !strings.HasPrefix(s, "/") {
+		return nil, ErrInvalidPointer
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		if strings.Contains(escapes.Replace(token), "~") {
+			return nil, ErrInvalidPointer
+		}
+		tokens[i] = unescaper.Replace(token)
+	}
+
+	return Pointer(tokens), nil
+}
+
+// String returns pointer in RFC 6901 syntax.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteString("/")
+		b.WriteString(escaper.Replace(token))
+	}
+
+	return b.String()
+}
+
+// Get returns value of the document at the pointer.
+func (p Pointer) Get(doc interface{}) (interface{}, error) {
+	node := doc
+	for _, token := range p {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			node = child
+		case []interface{}:
+			i, err := index(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return node, nil
+}
+
+// isPrefixOf reports whether p is a proper prefix of other.
+func (p Pointer) isPrefixOf(other Pointer) bool {
+	if len(p) >= len(other) {
+		return false
+	}
+
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mutate walks the document along the pointer and calls
+// fn with the container holding the last token, result of
+// fn replaces the container. Document is modified in place,
+// the new document root is returned.
+func (p Pointer) mutate(doc interface{}, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(p) == 1 {
+		return fn(doc, p[0])
+	}
+
+	switch n := doc.(type) {
+	case map[string]interface{}:
+		child, ok := n[p[0]]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+		child, err := p[1:].mutate(child, fn)
+		if err != nil {
+			return nil, err
+		}
+		n[p[0]] = child
+		return n, nil
+	case []interface{}:
+		i, err := index(p[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := p[1:].mutate(n[i], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = child
+		return n, nil
+	default:
+		return nil, ErrPathNotFound
+	}
+}
+
+// index parses array index token
+// which must not be greater than max.
+func index(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrInvalidIndex
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, ErrInvalidIndex
+	}
+
+	return i, nil
+}
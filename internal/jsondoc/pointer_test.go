@@ -0,0 +1,50 @@
+package jsondoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Pointer_Get(t *testing.T) {
+	doc := map[string]interface{}{
+		"a/b": []interface{}{"x", map[string]interface{}{"~": 1.0}},
+		"":    "empty",
+	}
+
+	tests := []struct {
+		name    string
+		pointer string
+		wantErr error
+		expect  interface{}
+	}{
+		{name: "root", pointer: "", expect: doc},
+		{name: "empty key", pointer: "/", expect: "empty"},
+		{name: "escaped", pointer: "/a~1b/1/~0", expect: 1.0},
+		{name: "missing key", pointer: "/c", wantErr: ErrPathNotFound},
+		{name: "leading zero", pointer: "/a~1b/01", wantErr: ErrInvalidIndex},
+		{name: "out of range", pointer: "/a~1b/2", wantErr: ErrInvalidIndex},
+		{name: "scalar", pointer: "/a~1b/0/x", wantErr: ErrPathNotFound},
+		{name: "bad escape", pointer: "/a~2", wantErr: ErrInvalidPointer},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := ParsePointer(tt.pointer)
+			if err == nil {
+				assert.Equal(t, tt.pointer, p.String())
+
+				var got interface{}
+				got, err = p.Get(doc)
+				if tt.wantErr == nil {
+					assert.Equal(t, tt.expect, got)
+				}
+			}
+
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package keys
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+// NewPutHandler returns handler for put key requests.
+func NewPutHandler(srv Resource) http.HandlerFunc {
+	return handler(srv.Put)
+}
+
+// NewGetHandler returns handler for get key requests,
+// raw values are responded with their content type.
+func NewGetHandler(srv Resource) http.HandlerFunc {
+	return handler(srv.Get)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		if resp.Data != nil {
+			if blob, ok := resp.Data.Value.(storage.Blob); ok {
+				responses.Raw(w, blob.ContentType, blob.Data)
+				return
+			}
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string `json:"message"`
+	Data    *data  `json:"data,omitempty"`
+}
+
+type data struct {
+	Value interface{} `json:"value"`
+}
@@ -0,0 +1,71 @@
+package keys
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handler(t *testing.T) {
+	tests := []struct {
+		name        string
+		serveFunc   func(*http.Request, *response) error
+		code        int
+		contentType string
+	}{
+		{
+			name: "ok",
+			serveFunc: func(_ *http.Request, resp *response) error {
+				resp.Data = &data{Value: "value"}
+				return nil
+			},
+			code:        http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			name: "raw value",
+			serveFunc: func(_ *http.Request, resp *response) error {
+				resp.Data = &data{Value: storage.Blob{ContentType: "image/png", Data: []byte("png")}}
+				return nil
+			},
+			code:        http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name: "not found error",
+			serveFunc: func(*http.Request, *response) error {
+				return errors.Wrap(notFoundResponse{}, "load failed")
+			},
+			code:        http.StatusNotFound,
+			contentType: "application/problem+json",
+		},
+		{
+			name: "unexpected error",
+			serveFunc: func(*http.Request, *response) error {
+				return errors.New("mock error")
+			},
+			code:        http.StatusInternalServerError,
+			contentType: "application/problem+json",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "http://any-host/v1/keys/key", nil)
+			res := httptest.NewRecorder()
+
+			h := handler(tt.serveFunc)
+			h(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+			assert.Equal(t, tt.contentType, res.Header().Get("Content-Type"))
+		})
+	}
+}
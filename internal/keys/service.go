@@ -0,0 +1,329 @@
+package keys
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/codec"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	keySetMessage                  = "key set"
+	keyFoundMessage                = "key found"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "key not found"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+	rawSchemaMessage               = "raw values can't be validated by the schema of the key"
+)
+
+// Resource service for requests of the key resource.
+type Resource interface {
+	Put(r *http.Request, resp *response) error
+	Get(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service, keys and
+// values are validated with the rules and schemas.
+func NewService(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules, schemas *schema.Registry) Resource {
+	srv := muxMap{
+		decoder: bodyDecoder{},
+		validater: ozzoValidater{
+			rules:   rules,
+			schemas: schemas,
+		},
+		storer: &sStorer{
+			storage:     storage,
+			keyLiveTime: keyLiveTime,
+		},
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	storer
+}
+
+type request struct {
+	Key string
+	// Value is decoded by the codec of the content
+	// type, bodies of other types are kept raw in
+	// the storage.Blob.
+	Value interface{}
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+	DecodeKey(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(request) error
+	ValidateKey(request) error
+}
+
+type storer interface {
+	Store(string, interface{}) error
+	Load(string) (interface{}, error)
+}
+
+func (s muxMap) Put(r *http.Request, resp *response) error {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req); err != nil {
+		return errors.Wrap(err, "validation failed")
+	}
+
+	if err := s.storer.Store(req.Key, req.Value); err != nil {
+		return errors.Wrap(err, "store failed")
+	}
+
+	resp.Message = keySetMessage
+
+	return nil
+}
+
+func (s muxMap) Get(r *http.Request, resp *response) error {
+	var req request
+
+	if err := s.decoder.DecodeKey(r, &req); err != nil {
+		return errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.ValidateKey(req); err != nil {
+		return errors.Wrap(err, "validation failed")
+	}
+
+	value, err := s.storer.Load(req.Key)
+	if err != nil {
+		return errors.Wrap(err, "load failed")
+	}
+
+	resp.Message = keyFoundMessage
+	resp.Data = &data{
+		Value: value,
+	}
+
+	return nil
+}
+
+type bodyDecoder struct{}
+
+// Decode decodes body with the codec of its content
+// type, bodies of unknown types are read as raw blobs.
+func (d bodyDecoder) Decode(r *http.Request, req *request) error {
+	req.Key = mux.Vars(r)["key"]
+
+	contentType := r.Header.Get("Content-Type")
+	if _, ok := codec.Default.ForContentType(contentType); ok {
+		if err := decode.Request(r, &req.Value); err != nil {
+			return errors.Wrap(err, "unable to decode")
+		}
+
+		return nil
+	}
+
+	b, err := decode.Raw(r.Body)
+	if err != nil {
+		return errors.Wrap(err, "unable to read")
+	}
+
+	req.Value = storage.Blob{ContentType: contentType, Data: b}
+
+	return nil
+}
+
+func (d bodyDecoder) DecodeKey(r *http.Request, req *request) error {
+	req.Key = mux.Vars(r)["key"]
+	return nil
+}
+
+type ozzoValidater struct {
+	rules   validate.Rules
+	schemas *schema.Registry
+}
+
+func (v ozzoValidater) Validate(r request) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	if err := v.rules.Key(r.Key); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "key", Message: err.Error()},
+		)
+	}
+
+	if blob, ok := r.Value.(storage.Blob); ok {
+		if err := v.rules.Value(blob.Data); err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "value", Message: err.Error()},
+			)
+		}
+
+		if v.schemas.Covers(r.Key) {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "value", Message: rawSchemaMessage},
+			)
+		}
+	} else {
+		if err := v.rules.Value(r.Value); err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "value", Message: err.Error()},
+			)
+		}
+
+		violations, err := v.schemas.Validate(r.Key, r.Value)
+		if err != nil {
+			return errors.Wrap(err, "schema validation")
+		}
+
+		for _, violation := range violations {
+			field := "value"
+			if violation.Field != "" {
+				field += "." + violation.Field
+			}
+
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: violation.Message},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+func (v ozzoValidater) ValidateKey(r request) error {
+	if err := v.rules.Key(r.Key); err != nil {
+		return validationErrorResponse{
+			Message: validationErrorResponseMessage,
+			Errors: []validationError{
+				validationError{Field: "key", Message: err.Error()},
+			},
+		}
+	}
+
+	return nil
+}
+
+type sStorer struct {
+	storage     storage.Storage
+	keyLiveTime time.Duration
+}
+
+func (s *sStorer) Store(key string, value interface{}) error {
+	err := s.storage.TrySet(storage.Expire(s.keyLiveTime), key, value)
+
+	switch err {
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
+}
+
+// Load returns value of the key
+// without consuming it.
+func (s *sStorer) Load(key string) (interface{}, error) {
+	value, err := s.storage.View(key, false, func(value interface{}) (interface{}, error) {
+		return value, nil
+	})
+
+	switch err {
+	case nil:
+		return value, nil
+	case storage.ErrNotFound:
+		return nil, notFoundResponse{Message: notFoundMessage}
+	case storage.ErrWrongType:
+		return nil, wrongTypeResponse{Message: wrongTypeMessage}
+	default:
+		return nil, err
+	}
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -0,0 +1,362 @@
+package keys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderMock struct {
+	decodeFunc    func(*http.Request, *request) error
+	decodeKeyFunc func(*http.Request, *request) error
+}
+
+func (m decoderMock) Decode(r *http.Request, req *request) error {
+	return m.decodeFunc(r, req)
+}
+
+func (m decoderMock) DecodeKey(r *http.Request, req *request) error {
+	return m.decodeKeyFunc(r, req)
+}
+
+type validaterMock struct {
+	validateFunc    func(request) error
+	validateKeyFunc func(request) error
+}
+
+func (m validaterMock) Validate(r request) error {
+	return m.validateFunc(r)
+}
+
+func (m validaterMock) ValidateKey(r request) error {
+	return m.validateKeyFunc(r)
+}
+
+type storerMock struct {
+	storeFunc func(string, interface{}) error
+	loadFunc  func(string) (interface{}, error)
+}
+
+func (m storerMock) Store(key string, value interface{}) error {
+	return m.storeFunc(key, value)
+}
+
+func (m storerMock) Load(key string) (interface{}, error) {
+	return m.loadFunc(key)
+}
+
+func Test_muxMap_Put(t *testing.T) {
+	tests := []struct {
+		name         string
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request) error
+		wantErr      bool
+		expect       response
+	}{
+		{
+			name: "decoder error",
+			decodeFunc: func(*http.Request, *request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "validater error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			expect: response{
+				Message: "key set",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderMock{decodeFunc: tt.decodeFunc},
+				validater: validaterMock{validateFunc: tt.validateFunc},
+				storer: storerMock{
+					storeFunc: func(string, interface{}) error { return nil },
+				},
+			}
+
+			var got response
+			err := s.Put(nil, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_muxMap_Get(t *testing.T) {
+	tests := []struct {
+		name            string
+		validateKeyFunc func(request) error
+		loadFunc        func(string) (interface{}, error)
+		wantErr         bool
+		expect          response
+	}{
+		{
+			name: "validater error",
+			validateKeyFunc: func(request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "storer error",
+			validateKeyFunc: func(request) error {
+				return nil
+			},
+			loadFunc: func(string) (interface{}, error) {
+				return nil, errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok",
+			validateKeyFunc: func(request) error {
+				return nil
+			},
+			loadFunc: func(string) (interface{}, error) {
+				return "value", nil
+			},
+			expect: response{
+				Message: "key found",
+				Data:    &data{Value: "value"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder: decoderMock{
+					decodeKeyFunc: func(*http.Request, *request) error {
+						return nil
+					},
+				},
+				validater: validaterMock{validateKeyFunc: tt.validateKeyFunc},
+				storer:    storerMock{loadFunc: tt.loadFunc},
+			}
+
+			var got response
+			err := s.Get(nil, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_bodyDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+		expect      request
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"a": 1}`,
+			expect: request{
+				Key:   "key",
+				Value: map[string]interface{}{"a": 1.0},
+			},
+		},
+		{
+			name: "default",
+			body: `"value"`,
+			expect: request{
+				Key:   "key",
+				Value: "value",
+			},
+		},
+		{
+			name:        "octet stream",
+			contentType: "application/octet-stream",
+			body:        "\x00\x01",
+			expect: request{
+				Key:   "key",
+				Value: storage.Blob{ContentType: "application/octet-stream", Data: []byte{0, 1}},
+			},
+		},
+		{
+			name:        "other type",
+			contentType: "image/png",
+			body:        "png",
+			expect: request{
+				Key:   "key",
+				Value: storage.Blob{ContentType: "image/png", Data: []byte("png")},
+			},
+		},
+		{
+			name:        "malformed json",
+			contentType: "application/json",
+			body:        `{`,
+			wantErr:     true,
+		},
+		{
+			name:        "empty raw body",
+			contentType: "application/octet-stream",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("PUT", "http://any/v1/keys/key", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = mux.SetURLVars(req, map[string]string{"key": "key"})
+
+			var got request
+			err := bodyDecoder{}.Decode(req, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	schemas := schema.NewRegistry()
+	assert.Nil(t, schemas.Register("user:", map[string]interface{}{"type": "object"}))
+
+	validater := ozzoValidater{
+		rules:   validate.Rules{MaxValueSize: 4},
+		schemas: schemas,
+	}
+
+	tests := []struct {
+		name   string
+		req    request
+		expect error
+	}{
+		{
+			name: "raw value",
+			req:  request{Key: "key", Value: storage.Blob{Data: []byte("data")}},
+		},
+		{
+			name: "invalid raw value",
+			req:  request{Key: "user:1", Value: storage.Blob{Data: []byte("large")}},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "value", Message: "the size must be no more than 4 bytes"},
+					validationError{Field: "value", Message: "raw values can't be validated by the schema of the key"},
+				},
+			},
+		},
+		{
+			name: "invalid value",
+			req:  request{Key: "user:1", Value: "v"},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "value", Message: "Invalid type. Expected: object, given: string"},
+				},
+			},
+		},
+		{
+			name: "blank key",
+			req:  request{Value: 1.0},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "key", Message: "cannot be blank"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expect, validater.Validate(tt.req))
+		})
+	}
+}
+
+func Test_sStorer(t *testing.T) {
+	s := &sStorer{
+		storage:     storage.New(),
+		keyLiveTime: time.Minute,
+	}
+
+	blob := storage.Blob{ContentType: "image/png", Data: []byte("png")}
+	assert.Nil(t, s.Store("key", blob))
+	s.storage.SAdd(context.Background(), "set", "a")
+
+	for i := 0; i < 2; i++ {
+		value, err := s.Load("key")
+		assert.Nil(t, err)
+		assert.Equal(t, blob, value)
+	}
+
+	_, err := s.Load("not found")
+	assert.Equal(t, notFoundResponse{Message: "key not found"}, err)
+
+	_, err = s.Load("set")
+	assert.Equal(t, wrongTypeResponse{Message: "key holds the wrong kind of value"}, err)
+
+	s.storage.SetQuota(storage.Quota{MaxKeys: 2})
+	err = s.Store("other", blob)
+	assert.Equal(t, quotaExceededResponse{Message: "keys quota exceeded", code: responses.CodeKeysQuotaExceeded}, err)
+}
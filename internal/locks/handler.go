@@ -0,0 +1,48 @@
+package locks
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewAcquireHandler returns handler for lock acquire requests.
+func NewAcquireHandler(srv Locker) http.HandlerFunc {
+	return handler(srv.Acquire)
+}
+
+// NewRenewHandler returns handler for lock renew requests.
+func NewRenewHandler(srv Locker) http.HandlerFunc {
+	return handler(srv.Renew)
+}
+
+// NewReleaseHandler returns handler for lock release requests.
+func NewReleaseHandler(srv Locker) http.HandlerFunc {
+	return handler(srv.Release)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type lockData struct {
+	// Token fences off writes of previous
+	// holders, it increases with every lock.
+	Token     uint64    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
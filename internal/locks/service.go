@@ -0,0 +1,304 @@
+package locks
+
+import (
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	acquiredMessage                = "lock acquired"
+	renewedMessage                 = "lock renewed"
+	releasedMessage                = "lock released"
+	validationErrorResponseMessage = "you have validation errors"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+	ttlMessage                     = "must be a positive duration like 30s"
+)
+
+// Locker service for lock requests.
+type Locker interface {
+	Acquire(r *http.Request, resp *response) error
+	Renew(r *http.Request, resp *response) error
+	Release(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, rules validate.Rules) Locker {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		locker: &sLocker{
+			storage: storage,
+		},
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	locker
+}
+
+type request struct {
+	Key string `json:"-"`
+	// TTL is lease of the lock,
+	// a duration like "30s".
+	TTL   string `json:"ttl"`
+	Token uint64 `json:"token"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type locker interface {
+	Acquire(key string, ttl time.Duration) (uint64, error)
+	Renew(key string, token uint64, ttl time.Duration) error
+	Release(key string, token uint64) error
+}
+
+func (s muxMap) Acquire(r *http.Request, resp *response) error {
+	req, err := s.request(r, "ttl")
+	if err != nil {
+		return err
+	}
+
+	ttl, _ := time.ParseDuration(req.TTL)
+	token, err := s.locker.Acquire(req.Key, ttl)
+	if err != nil {
+		return errors.Wrap(err, "acquire failed")
+	}
+
+	resp.Message = acquiredMessage
+	resp.Data = lockData{Token: token, ExpiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (s muxMap) Renew(r *http.Request, resp *response) error {
+	req, err := s.request(r, "token", "ttl")
+	if err != nil {
+		return err
+	}
+
+	ttl, _ := time.ParseDuration(req.TTL)
+	if err := s.locker.Renew(req.Key, req.Token, ttl); err != nil {
+		return errors.Wrap(err, "renew failed")
+	}
+
+	resp.Message = renewedMessage
+	resp.Data = lockData{Token: req.Token, ExpiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (s muxMap) Release(r *http.Request, resp *response) error {
+	req, err := s.request(r, "token")
+	if err != nil {
+		return err
+	}
+
+	if err := s.locker.Release(req.Key, req.Token); err != nil {
+		return errors.Wrap(err, "release failed")
+	}
+
+	resp.Message = releasedMessage
+
+	return nil
+}
+
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	if err := decode.Request(r, req); err != nil {
+		return err
+	}
+
+	req.Key = mux.Vars(r)["key"]
+
+	return nil
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	for _, field := range append([]string{"key"}, required...) {
+		var err error
+		switch field {
+		case "key":
+			err = v.rules.Key(r.Key)
+		case "ttl":
+			if ttl, parseErr := time.ParseDuration(r.TTL); parseErr != nil || ttl <= 0 {
+				err = errors.New(ttlMessage)
+			}
+		case "token":
+			err = validation.Validate(r.Token, validation.Required)
+		}
+
+		if err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sLocker struct {
+	storage storage.Storage
+}
+
+func (s *sLocker) Acquire(key string, ttl time.Duration) (uint64, error) {
+	token, err := s.storage.Acquire(storage.Expire(ttl), key)
+	return token, storageError(err)
+}
+
+func (s *sLocker) Renew(key string, token uint64, ttl time.Duration) error {
+	return storageError(s.storage.Renew(storage.Expire(ttl), key, token))
+}
+
+func (s *sLocker) Release(key string, token uint64) error {
+	return storageError(s.storage.Release(key, token))
+}
+
+func storageError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case storage.ErrLocked:
+		return lockHeldResponse{Message: err.Error()}
+	case storage.ErrNotHolder:
+		return notHolderResponse{Message: err.Error()}
+	case storage.ErrWrongType:
+		return wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
+}
+
+type lockHeldResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r lockHeldResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r lockHeldResponse) Code() responses.Code {
+	return responses.CodeLockHeld
+}
+
+type notHolderResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notHolderResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notHolderResponse) Code() responses.Code {
+	return responses.CodeLockNotHeld
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -0,0 +1,106 @@
+package locks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+func Test_muxMap(t *testing.T) {
+	s := muxMap{
+		validater: ozzoValidater{rules: validate.Rules{}},
+		locker:    &sLocker{storage: storage.New()},
+	}
+
+	// serve calls fn of the service with
+	// request decoded as the passed one.
+	serve := func(fn func(muxMap) func(*http.Request, *response) error, req request) (response, error) {
+		s.decoder = decoderFunc(func(_ *http.Request, r *request) error {
+			*r = req
+			return nil
+		})
+
+		var resp response
+		err := fn(s)(httptest.NewRequest("POST", "http://any", nil), &resp)
+		return resp, err
+	}
+	acquire := func(s muxMap) func(*http.Request, *response) error { return s.Acquire }
+	renew := func(s muxMap) func(*http.Request, *response) error { return s.Renew }
+	release := func(s muxMap) func(*http.Request, *response) error { return s.Release }
+
+	resp, err := serve(acquire, request{Key: "lock", TTL: "1m"})
+	assert.Nil(t, err)
+	assert.Equal(t, "lock acquired", resp.Message)
+	token := resp.Data.(lockData).Token
+
+	_, err = serve(acquire, request{Key: "lock", TTL: "1m"})
+	assert.Equal(t, lockHeldResponse{Message: "lock is held"}, errors.Cause(err))
+
+	_, err = serve(renew, request{Key: "lock", TTL: "1m", Token: token + 1})
+	assert.Equal(t, notHolderResponse{Message: "lock is not held with the token"}, errors.Cause(err))
+
+	resp, err = serve(renew, request{Key: "lock", TTL: "1h", Token: token})
+	assert.Nil(t, err)
+	assert.Equal(t, token, resp.Data.(lockData).Token)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), resp.Data.(lockData).ExpiresAt, time.Minute)
+
+	_, err = serve(release, request{Key: "lock"})
+	assert.Equal(t, validationErrorResponse{
+		Message: "you have validation errors",
+		Errors: []validationError{
+			validationError{Field: "token", Message: "cannot be blank"},
+		},
+	}, errors.Cause(err))
+
+	resp, err = serve(release, request{Key: "lock", Token: token})
+	assert.Nil(t, err)
+	assert.Equal(t, response{Message: "lock released"}, resp)
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      request
+		required []string
+		expect   error
+	}{
+		{
+			name:     "valid",
+			req:      request{Key: "lock", TTL: "30s", Token: 1},
+			required: []string{"token", "ttl"},
+		},
+		{
+			name:     "invalid ttl",
+			req:      request{Key: "lock", TTL: "0s"},
+			required: []string{"ttl"},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "ttl", Message: "must be a positive duration like 30s"},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{rules: validate.Rules{}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, validater.Validate(tt.req, tt.required...))
+		})
+	}
+}
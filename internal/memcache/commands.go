@@ -0,0 +1,554 @@
+package memcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+const (
+	// maxKeyLength is the longest key
+	// memcached clients may send.
+	maxKeyLength = 250
+	// maxItemSize is the largest
+	// data block of a value.
+	maxItemSize = 1 << 20
+	// maxLineLength bounds command lines.
+	maxLineLength = 2048
+	// relativeExptime is the largest exptime
+	// counted from now, larger ones are unix times.
+	relativeExptime = 60 * 60 * 24 * 30
+
+	octetStream = "application/octet-stream"
+	// flagsParam is the content type parameter
+	// keeping nonzero flags of the value.
+	flagsParam = "memcached-flags"
+
+	errBadFormat  = "CLIENT_ERROR bad command line format"
+	errBadChunk   = "CLIENT_ERROR bad data chunk"
+	errTooLarge   = "SERVER_ERROR object too large for cache"
+	errNonNumeric = "CLIENT_ERROR cannot increment or decrement non-numeric value"
+	errBadDelta   = "CLIENT_ERROR invalid numeric delta argument"
+	errNoMemory   = "SERVER_ERROR out of memory storing object"
+)
+
+var errLineTooLong = errors.New("line too long")
+
+// session is state of the client connection.
+type session struct {
+	r    *bufio.Reader
+	w    *bufio.Writer
+	quit bool
+}
+
+// line returns the next command line
+// without its trailing CRLF or LF.
+func (sess *session) line() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := sess.r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxLineLength {
+			return nil, errLineTooLong
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line = bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+
+		return line, nil
+	}
+}
+
+// reply writes the line unless
+// the command asked for no reply.
+func (sess *session) reply(noreply bool, line string) {
+	if noreply {
+		return
+	}
+
+	sess.w.WriteString(line)
+	sess.w.WriteString("\r\n")
+}
+
+// command executes arguments of
+// the command, name is the first one.
+type command func(s *Server, sess *session, args []string) error
+
+var commands = map[string]command{
+	"get":     (*Server).get,
+	"gets":    (*Server).gets,
+	"set":     (*Server).store,
+	"add":     (*Server).store,
+	"replace": (*Server).store,
+	"cas":     (*Server).store,
+	"delete":  (*Server).delete,
+	"incr":    (*Server).incr,
+	"decr":    (*Server).incr,
+	"touch":   (*Server).touch,
+	"version": (*Server).version,
+	"quit":    (*Server).quit,
+}
+
+// execute replies to the command line of the session, the
+// error is returned only when connection can't be served.
+func (s *Server) execute(sess *session, line []byte) error {
+	args := fields(line)
+	if len(args) == 0 {
+		sess.reply(false, "ERROR")
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		sess.reply(false, "ERROR")
+		return nil
+	}
+
+	return cmd(s, sess, args)
+}
+
+// get replies with values of the keys, unlike
+// HTTP get it does not consume the keys.
+func (s *Server) get(sess *session, args []string) error {
+	return s.retrieve(sess, args, false)
+}
+
+// gets replies like get with CAS
+// tokens which are value versions.
+func (s *Server) gets(sess *session, args []string) error {
+	return s.retrieve(sess, args, true)
+}
+
+func (s *Server) retrieve(sess *session, args []string, cas bool) error {
+	if len(args) < 2 {
+		sess.reply(false, "ERROR")
+		return nil
+	}
+
+	for _, key := range args[1:] {
+		if len(key) > maxKeyLength {
+			sess.reply(false, errBadFormat)
+			return nil
+		}
+	}
+
+	for _, key := range args[1:] {
+		value, info, err := s.storage.Peek(key)
+		if err != nil {
+			continue
+		}
+
+		data, flags, err := encode(value)
+		if err != nil {
+			continue
+		}
+
+		if cas {
+			fmt.Fprintf(sess.w, "VALUE %s %d %d %d\r\n", key, flags, len(data), info.Version)
+		} else {
+			fmt.Fprintf(sess.w, "VALUE %s %d %d\r\n", key, flags, len(data))
+		}
+		sess.w.Write(data)
+		sess.w.WriteString("\r\n")
+	}
+
+	sess.reply(false, "END")
+
+	return nil
+}
+
+// store handles set, add, replace and cas
+// commands, the data block follows the line.
+func (s *Server) store(sess *session, args []string) error {
+	name := args[0]
+
+	n := 5
+	if name == "cas" {
+		n = 6
+	}
+	if len(args) != n && !(len(args) == n+1 && args[n] == "noreply") {
+		sess.reply(false, errBadFormat)
+		return nil
+	}
+	noreply := len(args) == n+1
+
+	key := args[1]
+	flags, err1 := strconv.ParseUint(args[2], 10, 32)
+	exptime, err2 := strconv.ParseInt(args[3], 10, 64)
+	size, err3 := strconv.Atoi(args[4])
+	if err1 != nil || err2 != nil || err3 != nil || size < 0 || len(key) > maxKeyLength {
+		sess.reply(false, errBadFormat)
+		return nil
+	}
+
+	var version uint64
+	if name == "cas" {
+		var err error
+		if version, err = strconv.ParseUint(args[5], 10, 64); err != nil {
+			sess.reply(false, errBadFormat)
+			return nil
+		}
+	}
+
+	if size > maxItemSize {
+		if _, err := io.CopyN(ioutil.Discard, sess.r, int64(size)+2); err != nil {
+			return err
+		}
+		sess.reply(false, errTooLarge)
+		return nil
+	}
+
+	block := make([]byte, size+2)
+	if _, err := io.ReadFull(sess.r, block); err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(block, []byte("\r\n")) {
+		// Rest of the oversized block
+		// is skipped up to line end.
+		if block[len(block)-1] != '\n' {
+			if _, err := sess.line(); err != nil && err != errLineTooLong {
+				return err
+			}
+		}
+		sess.reply(false, errBadChunk)
+		return nil
+	}
+
+	value := decode(block[:size], uint32(flags))
+	if message := s.validate(key, value); message != "" {
+		sess.reply(false, message)
+		return nil
+	}
+
+	lifetime, expired := s.lifetime(exptime)
+	ctx := storage.Expire(lifetime)
+
+	var reply string
+	switch name {
+	case "cas":
+		switch s.storage.CompareAndSet(ctx, key, value, version) {
+		case nil:
+			reply = "STORED"
+		case storage.ErrVersionMismatch:
+			reply = "EXISTS"
+		case storage.ErrNotFound:
+			reply = "NOT_FOUND"
+		default:
+			sess.reply(false, errNoMemory)
+			return nil
+		}
+	default:
+		cond := storage.Always
+		switch name {
+		case "add":
+			cond = storage.IfNotExists
+		case "replace":
+			cond = storage.IfExists
+		}
+
+		ok, err := s.storage.SetIf(ctx, key, value, cond)
+		if err != nil {
+			sess.reply(false, errNoMemory)
+			return nil
+		}
+
+		reply = "NOT_STORED"
+		if ok {
+			reply = "STORED"
+		}
+	}
+
+	// Value with exptime in the past is
+	// stored and expires immediately.
+	if expired && reply == "STORED" {
+		s.storage.Del(key)
+	}
+
+	sess.reply(noreply, reply)
+
+	return nil
+}
+
+// validate returns error reply for violation
+// of the rules or schemas, empty when valid.
+func (s *Server) validate(key string, value interface{}) string {
+	for _, r := range key {
+		if r <= ' ' || r == 0x7f {
+			return errBadFormat
+		}
+	}
+
+	if err := s.rules.Key(key); err != nil {
+		return "CLIENT_ERROR invalid key: " + err.Error()
+	}
+
+	if blob, ok := value.(storage.Blob); ok {
+		if err := s.rules.Value(blob.Data); err != nil {
+			return errTooLarge
+		}
+		if s.schemas.Covers(key) {
+			return "CLIENT_ERROR invalid value: raw values can't be validated by the schema of the key"
+		}
+		return ""
+	}
+
+	if err := s.rules.Value(value); err != nil {
+		return errTooLarge
+	}
+
+	violations, err := s.schemas.Validate(key, value)
+	if err != nil {
+		return "SERVER_ERROR " + err.Error()
+	}
+
+	if len(violations) > 0 {
+		v := violations[0]
+		if v.Field != "" {
+			return fmt.Sprintf("CLIENT_ERROR invalid value: %s: %s", v.Field, v.Message)
+		}
+		return "CLIENT_ERROR invalid value: " + v.Message
+	}
+
+	return ""
+}
+
+// lifetime returns lifetime of the exptime, zero lives
+// for keyLiveTime, up to 30 days is relative and larger
+// is unix time. Expired is true for negative exptime and
+// unix time in the past.
+func (s *Server) lifetime(exptime int64) (d time.Duration, expired bool) {
+	switch {
+	case exptime == 0:
+		return s.keyLiveTime, false
+	case exptime < 0:
+		return s.keyLiveTime, true
+	case exptime <= relativeExptime:
+		return time.Duration(exptime) * time.Second, false
+	default:
+		d := time.Until(time.Unix(exptime, 0))
+		if d <= 0 {
+			return s.keyLiveTime, true
+		}
+		return d, false
+	}
+}
+
+// delete removes the key.
+func (s *Server) delete(sess *session, args []string) error {
+	// Legacy clients send zero time before noreply.
+	if len(args) > 2 && args[2] == "0" {
+		args = append(args[:2], args[3:]...)
+	}
+	if len(args) != 2 && !(len(args) == 3 && args[2] == "noreply") {
+		sess.reply(false, "CLIENT_ERROR bad command line format.  Usage: delete <key> [noreply]")
+		return nil
+	}
+
+	if len(args[1]) > maxKeyLength {
+		sess.reply(false, errBadFormat)
+		return nil
+	}
+
+	reply := "NOT_FOUND"
+	if s.storage.Del(args[1]) > 0 {
+		reply = "DELETED"
+	}
+
+	sess.reply(len(args) == 3, reply)
+
+	return nil
+}
+
+// incr handles incr and decr commands, value must be a
+// decimal 64-bit unsigned integer. Incr wraps around on
+// overflow and decr does not go below zero.
+func (s *Server) incr(sess *session, args []string) error {
+	if len(args) != 3 && !(len(args) == 4 && args[3] == "noreply") {
+		sess.reply(false, "ERROR")
+		return nil
+	}
+	noreply := len(args) == 4
+
+	key := args[1]
+	if len(key) > maxKeyLength {
+		sess.reply(false, errBadFormat)
+		return nil
+	}
+
+	delta, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		sess.reply(false, errBadDelta)
+		return nil
+	}
+
+	var result uint64
+	_, err = s.storage.Update(key, func(value interface{}) (interface{}, error) {
+		data, _, err := encode(value)
+		if err != nil {
+			return nil, storage.ErrNotInteger
+		}
+
+		n, err := strconv.ParseUint(string(data), 10, 64)
+		if err != nil {
+			return nil, storage.ErrNotInteger
+		}
+
+		if args[0] == "incr" {
+			n += delta
+		} else if n < delta {
+			n = 0
+		} else {
+			n -= delta
+		}
+		result = n
+
+		digits := strconv.FormatUint(n, 10)
+		if blob, ok := value.(storage.Blob); ok {
+			return storage.Blob{ContentType: blob.ContentType, Data: []byte(digits)}, nil
+		}
+
+		return digits, nil
+	})
+
+	switch err {
+	case nil:
+		sess.reply(noreply, strconv.FormatUint(result, 10))
+	case storage.ErrNotFound:
+		sess.reply(noreply, "NOT_FOUND")
+	case storage.ErrNotInteger:
+		sess.reply(false, errNonNumeric)
+	default:
+		sess.reply(false, errNoMemory)
+	}
+
+	return nil
+}
+
+// touch updates exptime of the key.
+func (s *Server) touch(sess *session, args []string) error {
+	if len(args) != 3 && !(len(args) == 4 && args[3] == "noreply") {
+		sess.reply(false, "ERROR")
+		return nil
+	}
+	noreply := len(args) == 4
+
+	key := args[1]
+	exptime, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil || len(key) > maxKeyLength {
+		sess.reply(false, errBadFormat)
+		return nil
+	}
+
+	lifetime, expired := s.lifetime(exptime)
+	if expired {
+		reply := "NOT_FOUND"
+		if s.storage.Del(key) > 0 {
+			reply = "TOUCHED"
+		}
+		sess.reply(noreply, reply)
+		return nil
+	}
+
+	switch err := s.storage.Expire(storage.Expire(lifetime), key); err {
+	case nil:
+	case storage.ErrNotFound:
+		sess.reply(noreply, "NOT_FOUND")
+		return nil
+	default:
+		sess.reply(false, errNoMemory)
+		return nil
+	}
+
+	sess.reply(noreply, "TOUCHED")
+
+	return nil
+}
+
+// version replies with the server name.
+func (s *Server) version(sess *session, args []string) error {
+	sess.reply(false, "VERSION integral_db")
+	return nil
+}
+
+// quit closes the connection.
+func (s *Server) quit(sess *session, args []string) error {
+	sess.quit = true
+	return nil
+}
+
+// fields splits command line by spaces.
+func fields(line []byte) []string {
+	var args []string
+	for _, f := range bytes.Fields(line) {
+		args = append(args, string(f))
+	}
+
+	return args
+}
+
+// decode returns value stored for the data block, with
+// zero flags UTF-8 text is stored as string and other
+// bytes as raw application/octet-stream blob, so values
+// are shared with HTTP clients. Nonzero flags are kept
+// as parameter of the blob content type.
+func decode(data []byte, flags uint32) interface{} {
+	if flags == 0 {
+		if utf8.Valid(data) {
+			return string(data)
+		}
+		return storage.Blob{ContentType: octetStream, Data: data}
+	}
+
+	contentType := mime.FormatMediaType(octetStream, map[string]string{
+		flagsParam: strconv.FormatUint(uint64(flags), 10),
+	})
+
+	return storage.Blob{ContentType: contentType, Data: data}
+}
+
+// encode returns data block and flags of the value,
+// values set by HTTP clients are encoded as JSON.
+func encode(value interface{}) ([]byte, uint32, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), 0, nil
+	case []byte:
+		return v, 0, nil
+	case storage.Blob:
+		return v.Data, blobFlags(v.ContentType), nil
+	default:
+		b, err := json.Marshal(v)
+		return b, 0, err
+	}
+}
+
+// blobFlags returns flags kept in the content
+// type, zero when it has no such parameter.
+func blobFlags(contentType string) uint32 {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return 0
+	}
+
+	flags, err := strconv.ParseUint(params[flagsParam], 10, 32)
+	if err != nil {
+		return 0
+	}
+
+	return uint32(flags)
+}
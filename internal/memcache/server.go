@@ -0,0 +1,178 @@
+package memcache
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+// Server serves memcached clients over the ASCII
+// protocol, commands are applied to the shared storage.
+type Server struct {
+	// IdleTimeout limits wait for the next command
+	// and its read, connections are closed after
+	// it. Zero means no limit.
+	IdleTimeout time.Duration
+
+	storage     storage.Storage
+	keyLiveTime time.Duration
+	rules       validate.Rules
+	schemas     *schema.Registry
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+// NewServer returns server of the storage, keys set
+// with zero exptime live for keyLiveTime, keys and
+// values are validated with the rules and schemas.
+func NewServer(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules, schemas *schema.Registry) *Server {
+	return &Server{
+		storage:     storage,
+		keyLiveTime: keyLiveTime,
+		rules:       rules,
+		schemas:     schemas,
+		listeners:   make(map[net.Listener]struct{}),
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP
+// address and serves its connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections of the listener until
+// server is closed, then it returns nil.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, l)
+			s.mu.Unlock()
+
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		if !s.track(c) {
+			c.Close()
+			return nil
+		}
+
+		go s.serveConn(c)
+	}
+}
+
+// Close closes listeners and connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	var err error
+	for l := range s.listeners {
+		if e := l.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+
+	return err
+}
+
+// track registers connection,
+// unless server is closed.
+func (s *Server) track(c net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.conns[c] = struct{}{}
+
+	return true
+}
+
+// serveConn executes commands of the connection in
+// order, replies of pipelined commands are flushed
+// when no more input is buffered.
+func (s *Server) serveConn(c net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	sess := session{
+		r: bufio.NewReader(c),
+		w: bufio.NewWriter(c),
+	}
+
+	for {
+		if s.IdleTimeout > 0 {
+			c.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+
+		line, err := sess.line()
+		if err != nil {
+			if err == errLineTooLong {
+				sess.w.WriteString("CLIENT_ERROR line too long\r\n")
+				sess.w.Flush()
+			} else if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("memcache: read from %s: %v", c.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if err := s.execute(&sess, line); err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("memcache: read from %s: %v", c.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if sess.r.Buffered() > 0 && !sess.quit {
+			continue
+		}
+
+		if err := sess.w.Flush(); err != nil || sess.quit {
+			return
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package memcache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+// client sends commands to the server and reads replies.
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newClient(t *testing.T, s *Server) *client {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go s.Serve(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return &client{conn: conn, r: bufio.NewReader(conn)}
+}
+
+// reply reads reply of a command, values
+// of retrieval are read up to END line.
+func (c *client) reply(t *testing.T) string {
+	var b strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read reply: %v", err)
+		}
+		b.WriteString(line)
+
+		if !strings.HasPrefix(line, "VALUE ") {
+			return b.String()
+		}
+
+		size, err := strconv.Atoi(strings.Fields(line)[3])
+		if err != nil {
+			t.Fatalf("invalid value line %q", line)
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			t.Fatalf("read value: %v", err)
+		}
+		b.Write(data)
+	}
+}
+
+func (c *client) do(t *testing.T, request string) string {
+	if _, err := fmt.Fprint(c.conn, request); err != nil {
+		t.Fatalf("write request %q: %v", request, err)
+	}
+
+	return c.reply(t)
+}
+
+func newServer(rules validate.Rules) (*Server, storage.Storage) {
+	st := storage.New()
+	schemas := schema.NewRegistry()
+	schemas.Register("user:", map[string]interface{}{"type": "object"})
+
+	return NewServer(st, time.Minute, rules, schemas), st
+}
+
+func TestServer_commands(t *testing.T) {
+	s, _ := newServer(validate.Rules{MaxKeyLength: 8})
+	defer s.Close()
+	c := newClient(t, s)
+
+	tests := []struct {
+		request string
+		expect  string
+	}{
+		{request: "get k\r\n", expect: "END\r\n"},
+		{request: "set k 0 0 1\r\nv\r\n", expect: "STORED\r\n"},
+		{request: "get k\r\n", expect: "VALUE k 0 1\r\nv\r\nEND\r\n"},
+		{request: "get k\r\n", expect: "VALUE k 0 1\r\nv\r\nEND\r\n"},
+		{request: "add k 0 0 1\r\nx\r\n", expect: "NOT_STORED\r\n"},
+		{request: "replace n 0 0 1\r\nx\r\n", expect: "NOT_STORED\r\n"},
+		{request: "add n 5 0 2\r\nab\r\n", expect: "STORED\r\n"},
+		{request: "replace k 0 0 1\r\nx\r\n", expect: "STORED\r\n"},
+		{request: "get k n missing\r\n", expect: "VALUE k 0 1\r\nx\r\nVALUE n 5 2\r\nab\r\nEND\r\n"},
+		{request: "set c 0 0 2\r\n10\r\n", expect: "STORED\r\n"},
+		{request: "incr c 5\r\n", expect: "15\r\n"},
+		{request: "decr c 20\r\n", expect: "0\r\n"},
+		{request: "incr c 18446744073709551615\r\n", expect: "18446744073709551615\r\n"},
+		{request: "incr c 1\r\n", expect: "0\r\n"},
+		{request: "incr k 1\r\n", expect: "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n"},
+		{request: "incr c x\r\n", expect: "CLIENT_ERROR invalid numeric delta argument\r\n"},
+		{request: "incr missing 1\r\n", expect: "NOT_FOUND\r\n"},
+		{request: "touch k 10\r\n", expect: "TOUCHED\r\n"},
+		{request: "touch missing 10\r\n", expect: "NOT_FOUND\r\n"},
+		{request: "delete k\r\n", expect: "DELETED\r\n"},
+		{request: "delete k\r\n", expect: "NOT_FOUND\r\n"},
+		{request: "set k 0 -1 1\r\nv\r\n", expect: "STORED\r\n"},
+		{request: "get k\r\n", expect: "END\r\n"},
+		{request: "set k 0 0 1 noreply\r\nv\r\nget k\r\n", expect: "VALUE k 0 1\r\nv\r\nEND\r\n"},
+		{request: "touch k -1\r\n", expect: "TOUCHED\r\n"},
+		{request: "get k\r\n", expect: "END\r\n"},
+		{request: "set k 0 0 1\r\nvv\r\n", expect: "CLIENT_ERROR bad data chunk\r\n"},
+		{request: "set k 0 0\r\n", expect: "CLIENT_ERROR bad command line format\r\n"},
+		{request: "set k 0 0 2000000\r\n" + strings.Repeat("v", 2000000) + "\r\n", expect: "SERVER_ERROR object too large for cache\r\n"},
+		{request: "set long_key! 0 0 1\r\nv\r\n", expect: "CLIENT_ERROR invalid key: the length must be no more than 8\r\n"},
+		{request: "set user:1 0 0 1\r\nv\r\n", expect: "CLIENT_ERROR invalid value: Invalid type. Expected: object, given: string\r\n"},
+		{request: "version\r\n", expect: "VERSION integral_db\r\n"},
+		{request: "nope\r\n", expect: "ERROR\r\n"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, c.do(t, tt.request), tt.request)
+	}
+}
+
+func TestServer_cas(t *testing.T) {
+	s, st := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	assert.Equal(t, "NOT_FOUND\r\n", c.do(t, "cas k 0 0 1 1\r\nv\r\n"))
+	assert.Equal(t, "STORED\r\n", c.do(t, "set k 0 0 1\r\nv\r\n"))
+
+	_, info, err := st.Peek("k")
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("VALUE k 0 1 %d\r\nv\r\nEND\r\n", info.Version), c.do(t, "gets k\r\n"))
+
+	assert.Equal(t, "EXISTS\r\n", c.do(t, fmt.Sprintf("cas k 0 0 1 %d\r\nx\r\n", info.Version+1)))
+	assert.Equal(t, "STORED\r\n", c.do(t, fmt.Sprintf("cas k 0 0 1 %d\r\nx\r\n", info.Version)))
+	assert.Equal(t, "EXISTS\r\n", c.do(t, fmt.Sprintf("cas k 0 0 1 %d\r\ny\r\n", info.Version)))
+	assert.Equal(t, "VALUE k 0 1\r\nx\r\nEND\r\n", c.do(t, "get k\r\n"))
+}
+
+func TestServer_exptime(t *testing.T) {
+	s, st := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	tests := []struct {
+		exptime int64
+		expect  time.Duration
+	}{
+		{exptime: 0, expect: time.Minute},
+		{exptime: 100, expect: 100 * time.Second},
+		{exptime: relativeExptime, expect: relativeExptime * time.Second},
+		{exptime: time.Now().Add(time.Hour).Unix(), expect: time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, "STORED\r\n", c.do(t, fmt.Sprintf("set k 0 %d 1\r\nv\r\n", tt.exptime)))
+
+		info, err := st.Info("k")
+		assert.Nil(t, err)
+		assert.InDelta(t, float64(tt.expect), float64(time.Until(info.ExpiresAt)), float64(2*time.Second), "exptime %d", tt.exptime)
+	}
+
+	assert.Equal(t, "STORED\r\n", c.do(t, fmt.Sprintf("set k 0 %d 1\r\nv\r\n", time.Now().Add(-time.Hour).Unix())))
+	assert.Equal(t, "END\r\n", c.do(t, "get k\r\n"))
+}
+
+func TestServer_sharedStorage(t *testing.T) {
+	s, st := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	st.Set(storage.Expire(time.Minute), "doc", map[string]interface{}{"a": 1.0})
+	st.SAdd(storage.Expire(time.Minute), "set", "a")
+
+	assert.Equal(t, "VALUE doc 0 7\r\n{\"a\":1}\r\nEND\r\n", c.do(t, "get doc set\r\n"))
+
+	assert.Equal(t, "STORED\r\n", c.do(t, "set bin 0 0 2\r\n\xff\x00\r\n"))
+	value, _, err := st.Peek("bin")
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Blob{ContentType: "application/octet-stream", Data: []byte("\xff\x00")}, value)
+
+	assert.Equal(t, "STORED\r\n", c.do(t, "set flagged 42 0 1\r\n7\r\n"))
+	value, _, err = st.Peek("flagged")
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Blob{ContentType: "application/octet-stream; memcached-flags=42", Data: []byte("7")}, value)
+	assert.Equal(t, "8\r\n", c.do(t, "incr flagged 1\r\n"))
+	assert.Equal(t, "VALUE flagged 42 1\r\n8\r\nEND\r\n", c.do(t, "get flagged\r\n"))
+}
+
+func TestServer_pipelining(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	var b strings.Builder
+	for i := 0; i < 100; i++ {
+		v := fmt.Sprint(i)
+		fmt.Fprintf(&b, "set k%d 0 0 %d\r\n%s\r\nget k%d\r\n", i, len(v), v, i)
+	}
+	fmt.Fprint(c.conn, b.String())
+
+	for i := 0; i < 100; i++ {
+		v := fmt.Sprint(i)
+		assert.Equal(t, "STORED\r\n", c.reply(t))
+		assert.Equal(t, fmt.Sprintf("VALUE k%d 0 %d\r\n%s\r\nEND\r\n", i, len(v), v), c.reply(t))
+	}
+}
+
+func TestServer_idleTimeout(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+	s.IdleTimeout = 50 * time.Millisecond
+	defer s.Close()
+	c := newClient(t, s)
+
+	assert.Equal(t, "VERSION integral_db\r\n", c.do(t, "version\r\n"))
+
+	// Value which doesn't arrive in time closes the connection.
+	fmt.Fprint(c.conn, "set k 0 0 5\r\nva")
+
+	_, err := c.r.ReadString('\n')
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestServer_Close(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	c := &client{conn: conn, r: bufio.NewReader(conn)}
+	assert.Equal(t, "VERSION integral_db\r\n", c.do(t, "version\r\n"))
+
+	assert.Nil(t, s.Close())
+	assert.Nil(t, <-done)
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = c.r.ReadString('\n')
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package namespace
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+// Header selects namespace
+// of the request by header.
+const Header = "X-Namespace"
+
+// pathPrefix prefixes namespace
+// segment of the request path.
+const pathPrefix = "/ns/"
+
+// maxHandlers limits built handlers kept for reuse,
+// namespaces served without registry are unbounded.
+const maxHandlers = 1024
+
+// Source is where namespace of the request is taken from.
+type Source string
+
+// Namespace sources, requests without namespace
+// are served in the root storage.
+const (
+	// SourceHeader takes namespace from X-Namespace header.
+	SourceHeader Source = "header"
+	// SourcePath takes namespace from /ns/{namespace}
+	// prefix of the path, which is stripped.
+	SourcePath Source = "path"
+	// SourcePrincipal takes name of the
+	// authenticated principal as namespace.
+	SourcePrincipal Source = "principal"
+)
+
+// ParseSource returns source of the name.
+func ParseSource(name string) (Source, error) {
+	switch s := Source(name); s {
+	case SourceHeader, SourcePath, SourcePrincipal:
+		return s, nil
+	default:
+		return "", errors.Errorf("unknown namespace source %q", name)
+	}
+}
+
+// Build returns handler serving requests in
+// the storage of the namespace with its config.
+type Build func(s storage.Storage, c Config) http.Handler
+
+// NewDispatcher returns handler serving requests by handler
+// built for their namespace, root handler serves requests
+// without namespace. Principals not allowed to use the
+// namespace are forbidden. Built handlers are reused.
+func NewDispatcher(s storage.Storage, source Source, registry *Registry, root http.Handler, build Build) http.Handler {
+	return &dispatcher{
+		storage:  s,
+		source:   source,
+		registry: registry,
+		root:     root,
+		build:    build,
+		handlers: make(map[string]http.Handler),
+	}
+}
+
+type dispatcher struct {
+	storage  storage.Storage
+	source   Source
+	registry *Registry
+	root     http.Handler
+	build    Build
+
+	mu       sync.Mutex
+	handlers map[string]http.Handler
+}
+
+func (d *dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, r := d.selectNamespace(r)
+	if name == "" {
+		d.root.ServeHTTP(w, r)
+		return
+	}
+
+	c, ok := d.registry.Config(name)
+	if !ok {
+		responses.Error(w, r, notFoundResponse{Message: fmt.Sprintf("namespace %q is not served", name)})
+		return
+	}
+
+	principal, _ := auth.FromContext(r.Context())
+	if !c.Allows(principal.Name) {
+		responses.Error(w, r, forbiddenResponse{Message: fmt.Sprintf("namespace %q is not allowed", name)})
+		return
+	}
+
+	d.handler(name, c).ServeHTTP(w, r)
+}
+
+// selectNamespace returns namespace of the
+// request and request to serve in it.
+func (d *dispatcher) selectNamespace(r *http.Request) (string, *http.Request) {
+	switch d.source {
+	case SourceHeader:
+		return r.Header.Get(Header), r
+	case SourcePath:
+		if !strings.HasPrefix(r.URL.Path, pathPrefix) {
+			return "", r
+		}
+
+		rest := r.URL.Path[len(pathPrefix):]
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return rest, r
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = rest[i:]
+		r2.URL.RawPath = ""
+
+		return rest[:i], r2
+	case SourcePrincipal:
+		principal, _ := auth.FromContext(r.Context())
+		return principal.Name, r
+	default:
+		return "", r
+	}
+}
+
+// handler returns handler of the namespace, it is built
+// on the first request. Some handler is evicted when
+// too many of them are kept.
+func (d *dispatcher) handler(name string, c Config) http.Handler {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if h, ok := d.handlers[name]; ok {
+		return h
+	}
+
+	if len(d.handlers) >= maxHandlers {
+		for evicted := range d.handlers {
+			delete(d.handlers, evicted)
+			break
+		}
+	}
+
+	h := d.build(d.storage.Namespace(name), c)
+	d.handlers[name] = h
+
+	return h
+}
@@ -0,0 +1,113 @@
+package namespace
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	registry, err := NewRegistry(map[string]Config{
+		"tenant":  {},
+		"private": {Principals: []string{"owner"}},
+	})
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	// Handlers reply with the namespace
+	// and path they were asked to serve.
+	serve := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + " " + r.URL.Path))
+		})
+	}
+	build := func(s storage.Storage, c Config) http.Handler {
+		return serve("tenant")
+	}
+
+	tests := []struct {
+		name      string
+		source    Source
+		target    string
+		header    string
+		principal string
+		code      int
+		expect    string
+	}{
+		{name: "header", source: SourceHeader, target: "/get", header: "tenant", code: http.StatusOK, expect: "tenant /get"},
+		{name: "header root", source: SourceHeader, target: "/get", code: http.StatusOK, expect: "root /get"},
+		{name: "header not served", source: SourceHeader, target: "/get", header: "other", code: http.StatusNotFound},
+		{name: "header allowed", source: SourceHeader, target: "/get", header: "private", principal: "owner", code: http.StatusOK, expect: "tenant /get"},
+		{name: "header forbidden", source: SourceHeader, target: "/get", header: "private", principal: "app", code: http.StatusForbidden},
+		{name: "header anonymous", source: SourceHeader, target: "/get", header: "private", code: http.StatusForbidden},
+		{name: "path", source: SourcePath, target: "/ns/tenant/v1/keys/a", code: http.StatusOK, expect: "tenant /v1/keys/a"},
+		{name: "path root", source: SourcePath, target: "/v1/keys/a", code: http.StatusOK, expect: "root /v1/keys/a"},
+		{name: "path not served", source: SourcePath, target: "/ns/other/get", code: http.StatusNotFound},
+		{name: "path forbidden", source: SourcePath, target: "/ns/private/get", principal: "app", code: http.StatusForbidden},
+		{name: "principal", source: SourcePrincipal, target: "/get", principal: "tenant", code: http.StatusOK, expect: "tenant /get"},
+		{name: "anonymous", source: SourcePrincipal, target: "/get", code: http.StatusOK, expect: "root /get"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := storage.New()
+			d := NewDispatcher(s, tt.source, registry, serve("root"), build)
+
+			req := httptest.NewRequest("GET", "http://any-host"+tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(Header, tt.header)
+			}
+			if tt.principal != "" {
+				req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Name: tt.principal}))
+			}
+			res := httptest.NewRecorder()
+
+			d.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+			if tt.code == http.StatusOK {
+				assert.Equal(t, tt.expect, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestDispatcher_handlers(t *testing.T) {
+	registry, err := NewRegistry(nil)
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	var built int
+	build := func(s storage.Storage, c Config) http.Handler {
+		built++
+		return http.NotFoundHandler()
+	}
+
+	d := NewDispatcher(storage.New(), SourceHeader, registry, http.NotFoundHandler(), build).(*dispatcher)
+
+	serve := func(name string) {
+		req := httptest.NewRequest("GET", "http://any-host/get", nil)
+		req.Header.Set(Header, name)
+		d.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("tenant")
+	serve("tenant")
+	assert.Equal(t, 1, built)
+
+	for i := 0; i < 2*maxHandlers; i++ {
+		serve(fmt.Sprintf("tenant-%d", i))
+	}
+	assert.Equal(t, 1+2*maxHandlers, built)
+	assert.Len(t, d.handlers, maxHandlers)
+}
@@ -0,0 +1,44 @@
+package namespace
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewStatsHandler returns handler for namespace stats requests.
+func NewStatsHandler(srv Manager) http.HandlerFunc {
+	return handler(srv.Stats)
+}
+
+// NewFlushHandler returns handler for namespace flush requests.
+func NewFlushHandler(srv Manager) http.HandlerFunc {
+	return handler(srv.Flush)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type statsData struct {
+	Keys  int `json:"keys"`
+	Bytes int `json:"bytes"`
+}
+
+type flushData struct {
+	Deleted int `json:"deleted"`
+}
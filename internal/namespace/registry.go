@@ -0,0 +1,144 @@
+package namespace
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// namePattern matches valid namespace names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ValidName reports whether name is valid.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Config of the namespace, zero
+// fields take the server defaults.
+type Config struct {
+	// KeyLiveTime is lifetime of keys
+	// set without explicit one.
+	KeyLiveTime  time.Duration
+	MaxKeyLength int
+	MaxValueSize int
+
+	// Quota of the namespace keys,
+	// zero fields are not limited.
+	MaxKeys  int
+	MaxBytes int
+	MaxTTL   time.Duration
+
+	// Principals allowed to use the
+	// namespace, empty allows everyone.
+	Principals []string
+}
+
+// Allows reports whether the principal
+// is allowed to use the namespace.
+func (c Config) Allows(principal string) bool {
+	if len(c.Principals) == 0 {
+		return true
+	}
+
+	for _, p := range c.Principals {
+		if p == principal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Registry holds configs of the namespaces.
+type Registry struct {
+	configs map[string]Config
+}
+
+// NewRegistry returns registry of the configs by namespace
+// names, when it is empty any valid namespace is served.
+func NewRegistry(configs map[string]Config) (*Registry, error) {
+	for name, c := range configs {
+		if !ValidName(name) {
+			return nil, errors.Errorf("invalid namespace name %q", name)
+		}
+		if c.KeyLiveTime < 0 || c.MaxKeyLength < 0 || c.MaxValueSize < 0 ||
+			c.MaxKeys < 0 || c.MaxBytes < 0 || c.MaxTTL < 0 {
+			return nil, errors.Errorf("namespace %q: limits must not be negative", name)
+		}
+		if c.MaxTTL > 0 && c.KeyLiveTime > c.MaxTTL {
+			return nil, errors.Errorf("namespace %q: key live time exceeds max ttl", name)
+		}
+	}
+
+	return &Registry{configs: configs}, nil
+}
+
+// fileConfig is config of the namespace in the file.
+type fileConfig struct {
+	KeyLiveTime  string   `json:"key_live_time"`
+	MaxKeyLength int      `json:"max_key_length"`
+	MaxValueSize int      `json:"max_value_size"`
+	MaxKeys      int      `json:"max_keys"`
+	MaxBytes     int      `json:"max_bytes"`
+	MaxTTL       string   `json:"max_ttl"`
+	Principals   []string `json:"principals"`
+}
+
+// Load loads registry from the file with JSON
+// object of the configs by namespace names,
+// key_live_time and max_ttl are durations like "1m".
+func Load(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open config")
+	}
+	defer f.Close()
+
+	var files map[string]fileConfig
+	if err := json.NewDecoder(f).Decode(&files); err != nil {
+		return nil, errors.Wrap(err, "decode config")
+	}
+
+	configs := make(map[string]Config, len(files))
+	for name, fc := range files {
+		c := Config{
+			MaxKeyLength: fc.MaxKeyLength,
+			MaxValueSize: fc.MaxValueSize,
+			MaxKeys:      fc.MaxKeys,
+			MaxBytes:     fc.MaxBytes,
+			Principals:   fc.Principals,
+		}
+		if fc.KeyLiveTime != "" {
+			if c.KeyLiveTime, err = time.ParseDuration(fc.KeyLiveTime); err != nil {
+				return nil, errors.Wrapf(err, "namespace %q", name)
+			}
+		}
+		if fc.MaxTTL != "" {
+			if c.MaxTTL, err = time.ParseDuration(fc.MaxTTL); err != nil {
+				return nil, errors.Wrapf(err, "namespace %q", name)
+			}
+		}
+		configs[name] = c
+	}
+
+	return NewRegistry(configs)
+}
+
+// Config returns config of the namespace,
+// ok is false when it is not served.
+func (r *Registry) Config(name string) (c Config, ok bool) {
+	if !ValidName(name) {
+		return Config{}, false
+	}
+
+	if len(r.configs) == 0 {
+		return Config{}, true
+	}
+
+	c, ok = r.configs[name]
+	return c, ok
+}
@@ -0,0 +1,111 @@
+package namespace
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Config(t *testing.T) {
+	any, err := NewRegistry(nil)
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+	configured, err := NewRegistry(map[string]Config{
+		"tenant": {KeyLiveTime: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		registry *Registry
+		ns       string
+		expect   Config
+		ok       bool
+	}{
+		{name: "any served", registry: any, ns: "tenant", ok: true},
+		{name: "any invalid", registry: any, ns: "a/b"},
+		{name: "configured", registry: configured, ns: "tenant", expect: Config{KeyLiveTime: time.Minute}, ok: true},
+		{name: "not configured", registry: configured, ns: "other"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c, ok := tt.registry.Config(tt.ns)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expect, c)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "valid",
+			config: `{"tenant": {"key_live_time": "1m", "max_key_length": 32, "max_value_size": 1024, "max_keys": 100, "max_bytes": 4096, "max_ttl": "1h", "principals": ["app"]}}`,
+		},
+		{
+			name:   "invalid max ttl",
+			config: `{"tenant": {"max_ttl": "long"}}`,
+			err:    `namespace "tenant": time: invalid duration "long"`,
+		},
+		{
+			name:   "key live time over max ttl",
+			config: `{"tenant": {"key_live_time": "1h", "max_ttl": "1m"}}`,
+			err:    `namespace "tenant": key live time exceeds max ttl`,
+		},
+		{
+			name:   "invalid duration",
+			config: `{"tenant": {"key_live_time": "soon"}}`,
+			err:    `namespace "tenant": time: invalid duration "soon"`,
+		},
+		{
+			name:   "invalid name",
+			config: `{"a/b": {}}`,
+			err:    `invalid namespace name "a/b"`,
+		},
+		{
+			name:   "negative limit",
+			config: `{"tenant": {"max_keys": -1}}`,
+			err:    `namespace "tenant": limits must not be negative`,
+		},
+		{
+			name:   "malformed",
+			config: `[]`,
+			err:    "decode config: json: cannot unmarshal array into Go value of type map[string]namespace.fileConfig",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "namespaces")
+			if err != nil {
+				t.Fatalf("create temp file: %v", err)
+			}
+			defer os.Remove(f.Name())
+			f.WriteString(tt.config)
+			f.Close()
+
+			_, err = Load(f.Name())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package namespace
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+const (
+	statsFoundMessage              = "namespace stats found"
+	flushedMessage                 = "namespace flushed"
+	validationErrorResponseMessage = "you have validation errors"
+	invalidNameMessage             = "must be 1 to 64 letters, digits, '_', '.' or '-'"
+)
+
+// Manager service for namespace administration requests.
+type Manager interface {
+	Stats(r *http.Request, resp *response) error
+	Flush(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service.
+func NewService(storage storage.Storage) Manager {
+	srv := muxMap{
+		decoder:   varsDecoder{},
+		validater: nameValidater{},
+		storage:   storage,
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	storage storage.Storage
+}
+
+type request struct {
+	Namespace string
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(request) error
+}
+
+func (s muxMap) Stats(r *http.Request, resp *response) error {
+	req, err := s.request(r)
+	if err != nil {
+		return err
+	}
+
+	stats := s.storage.Namespace(req.Namespace).Stats()
+
+	resp.Message = statsFoundMessage
+	resp.Data = statsData{Keys: stats.Keys, Bytes: stats.Bytes}
+
+	return nil
+}
+
+func (s muxMap) Flush(r *http.Request, resp *response) error {
+	req, err := s.request(r)
+	if err != nil {
+		return err
+	}
+
+	deleted := s.storage.Namespace(req.Namespace).Flush()
+
+	resp.Message = flushedMessage
+	resp.Data = flushData{Deleted: deleted}
+
+	return nil
+}
+
+func (s muxMap) request(r *http.Request) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type varsDecoder struct{}
+
+func (d varsDecoder) Decode(r *http.Request, req *request) error {
+	req.Namespace = mux.Vars(r)["namespace"]
+	return nil
+}
+
+type nameValidater struct{}
+
+func (v nameValidater) Validate(r request) error {
+	if !ValidName(r.Namespace) {
+		return validationErrorResponse{
+			Message: validationErrorResponseMessage,
+			Errors: []validationError{
+				validationError{Field: "namespace", Message: invalidNameMessage},
+			},
+		}
+	}
+
+	return nil
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeNamespaceNotFound
+}
+
+type forbiddenResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r forbiddenResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r forbiddenResponse) Code() responses.Code {
+	return responses.CodeForbidden
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
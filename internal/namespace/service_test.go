@@ -0,0 +1,89 @@
+package namespace
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+func Test_muxMap(t *testing.T) {
+	s := storage.New()
+	s.Namespace("tenant").Set(storage.Expire(time.Minute), "a", "value")
+	s.Set(storage.Expire(time.Minute), "a", "value")
+
+	tests := []struct {
+		name      string
+		namespace string
+		serve     func(muxMap) func(*http.Request, *response) error
+		wantErr   bool
+		expectErr error
+		expect    response
+	}{
+		{
+			name:      "stats",
+			namespace: "tenant",
+			serve:     func(s muxMap) func(*http.Request, *response) error { return s.Stats },
+			expect: response{
+				Message: "namespace stats found",
+				Data:    statsData{Keys: 1, Bytes: 5},
+			},
+		},
+		{
+			name:      "invalid name",
+			namespace: "a/b",
+			serve:     func(s muxMap) func(*http.Request, *response) error { return s.Flush },
+			wantErr:   true,
+			expectErr: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "namespace", Message: "must be 1 to 64 letters, digits, '_', '.' or '-'"},
+				},
+			},
+		},
+		{
+			name:      "flush",
+			namespace: "tenant",
+			serve:     func(s muxMap) func(*http.Request, *response) error { return s.Flush },
+			expect: response{
+				Message: "namespace flushed",
+				Data:    flushData{Deleted: 1},
+			},
+		},
+	}
+
+	// Cases share the storage, so they run in order.
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := muxMap{
+				decoder: decoderFunc(func(_ *http.Request, req *request) error {
+					req.Namespace = tt.namespace
+					return nil
+				}),
+				validater: nameValidater{},
+				storage:   s,
+			}
+
+			var got response
+			err := tt.serve(srv)(nil, &got)
+			if tt.wantErr {
+				assert.Equal(t, tt.expectErr, errors.Cause(err))
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+
+	assert.Equal(t, 1, s.Stats().Keys)
+}
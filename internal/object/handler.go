@@ -0,0 +1,71 @@
+package object
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+// NewTypeHandler returns handler for key type requests.
+func NewTypeHandler(srv Inspector) http.HandlerFunc {
+	return handler(srv.Type)
+}
+
+// NewObjectHandler returns handler for key metadata requests.
+func NewObjectHandler(srv Inspector) http.HandlerFunc {
+	return handler(srv.Object)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type typeData struct {
+	Type string `json:"type"`
+}
+
+type objectData struct {
+	Type        string     `json:"type"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AccessedAt  time.Time  `json:"accessed_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	IdleSeconds int64      `json:"idle_seconds"`
+	// TTLSeconds is -1 when key
+	// lifetime is not bounded.
+	TTLSeconds int64 `json:"ttl_seconds"`
+	Size       int   `json:"size"`
+}
+
+func newObjectData(info storage.Info, now time.Time) objectData {
+	data := objectData{
+		Type:        string(info.Type),
+		CreatedAt:   info.CreatedAt,
+		AccessedAt:  info.AccessedAt,
+		IdleSeconds: int64(now.Sub(info.AccessedAt) / time.Second),
+		TTLSeconds:  -1,
+		Size:        info.Size,
+	}
+
+	if !info.ExpiresAt.IsZero() {
+		data.ExpiresAt = &info.ExpiresAt
+		data.TTLSeconds = int64(info.ExpiresAt.Sub(now) / time.Second)
+	}
+
+	return data
+}
@@ -0,0 +1,109 @@
+package object
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handler(t *testing.T) {
+	tests := []struct {
+		name      string
+		serveFunc func(*http.Request, *response) error
+		code      int
+	}{
+		{
+			name: "ok",
+			serveFunc: func(*http.Request, *response) error {
+				return nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "validation error",
+			serveFunc: func(*http.Request, *response) error {
+				return validationErrorResponse{}
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not found error",
+			serveFunc: func(*http.Request, *response) error {
+				return errors.Wrap(notFoundResponse{}, "info failed")
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "unexpected error",
+			serveFunc: func(*http.Request, *response) error {
+				return errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "http://any-host/object", nil)
+			res := httptest.NewRecorder()
+
+			h := handler(tt.serveFunc)
+			h(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+		})
+	}
+}
+
+func Test_newObjectData(t *testing.T) {
+	now := time.Now()
+	created := now.Add(-time.Minute)
+	expires := now.Add(time.Minute)
+
+	tests := []struct {
+		name   string
+		info   storage.Info
+		expect objectData
+	}{
+		{
+			name: "without expiration",
+			info: storage.Info{Type: storage.TypeSet, CreatedAt: created, AccessedAt: created, Size: 3},
+			expect: objectData{
+				Type:        "set",
+				CreatedAt:   created,
+				AccessedAt:  created,
+				IdleSeconds: 60,
+				TTLSeconds:  -1,
+				Size:        3,
+			},
+		},
+		{
+			name: "with expiration",
+			info: storage.Info{Type: storage.TypeString, CreatedAt: created, AccessedAt: now, ExpiresAt: expires},
+			expect: objectData{
+				Type:       "string",
+				CreatedAt:  created,
+				AccessedAt: now,
+				ExpiresAt:  &expires,
+				TTLSeconds: 60,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expect, newObjectData(tt.info, now))
+		})
+	}
+}
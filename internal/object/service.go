@@ -0,0 +1,184 @@
+package object
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	typeFoundMessage               = "type found"
+	objectFoundMessage             = "object found"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "key not found"
+)
+
+// Inspector service for key introspection requests.
+type Inspector interface {
+	Type(r *http.Request, resp *response) error
+	Object(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, rules validate.Rules) Inspector {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		inspector: &sInspector{
+			storage: storage,
+		},
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	inspector
+}
+
+type request struct {
+	Key string `json:"key"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(request) error
+}
+
+type inspector interface {
+	Info(key string) (storage.Info, error)
+}
+
+func (s muxMap) Type(r *http.Request, resp *response) error {
+	info, err := s.info(r)
+	if err != nil {
+		return err
+	}
+
+	resp.Message = typeFoundMessage
+	resp.Data = typeData{Type: string(info.Type)}
+
+	return nil
+}
+
+func (s muxMap) Object(r *http.Request, resp *response) error {
+	info, err := s.info(r)
+	if err != nil {
+		return err
+	}
+
+	resp.Message = objectFoundMessage
+	resp.Data = newObjectData(info, time.Now())
+
+	return nil
+}
+
+func (s muxMap) info(r *http.Request) (storage.Info, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return storage.Info{}, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req); err != nil {
+		return storage.Info{}, errors.Wrap(err, "validation failed")
+	}
+
+	info, err := s.inspector.Info(req.Key)
+	if err != nil {
+		return storage.Info{}, errors.Wrap(err, "info failed")
+	}
+
+	return info, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	return decode.Request(r, req)
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	if err := v.rules.Key(r.Key); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "key", Message: err.Error()},
+		)
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sInspector struct {
+	storage storage.Storage
+}
+
+func (i *sInspector) Info(key string) (storage.Info, error) {
+	info, err := i.storage.Info(key)
+	if err == storage.ErrNotFound {
+		return info, notFoundResponse{Message: notFoundMessage}
+	}
+
+	return info, err
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -0,0 +1,146 @@
+package object
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterFunc func(request) error
+
+func (f validaterFunc) Validate(r request) error {
+	return f(r)
+}
+
+type inspectorFunc func(string) (storage.Info, error)
+
+func (f inspectorFunc) Info(key string) (storage.Info, error) {
+	return f(key)
+}
+
+func Test_muxMap_Type(t *testing.T) {
+	tests := []struct {
+		name         string
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request) error
+		infoFunc     func(string) (storage.Info, error)
+		wantErr      bool
+		expect       response
+	}{
+		{
+			name: "decoder error",
+			decodeFunc: func(*http.Request, *request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "validater error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "inspector error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			infoFunc: func(string) (storage.Info, error) {
+				return storage.Info{}, errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			infoFunc: func(string) (storage.Info, error) {
+				return storage.Info{Type: storage.TypeHash}, nil
+			},
+			expect: response{
+				Message: "type found",
+				Data:    typeData{Type: "hash"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderFunc(tt.decodeFunc),
+				validater: validaterFunc(tt.validateFunc),
+				inspector: inspectorFunc(tt.infoFunc),
+			}
+
+			var got response
+			err := s.Type(nil, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	validater := ozzoValidater{}
+
+	assert.Nil(t, validater.Validate(request{Key: "key"}))
+	assert.Equal(t, validationErrorResponse{
+		Message: "you have validation errors",
+		Errors: []validationError{
+			validationError{
+				Field:   "key",
+				Message: "cannot be blank",
+			},
+		},
+	}, validater.Validate(request{}))
+}
+
+func Test_sInspector_Info(t *testing.T) {
+	i := &sInspector{
+		storage: storage.New(),
+	}
+
+	i.storage.Set(context.Background(), "key", "value")
+
+	info, err := i.Info("key")
+	assert.Nil(t, err)
+	assert.Equal(t, storage.TypeString, info.Type)
+
+	_, err = i.Info("not found")
+	assert.Equal(t, notFoundResponse{Message: "key not found"}, err)
+
+	value, err := i.storage.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", value)
+}
@@ -0,0 +1,30 @@
+package patch
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewHandler returns handler for patch key requests.
+func NewHandler(srv Patcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := srv.Patch(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string `json:"message"`
+	Data    data   `json:"data"`
+}
+
+type data struct {
+	Value interface{} `json:"value"`
+}
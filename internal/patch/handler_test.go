@@ -0,0 +1,89 @@
+package patch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		patchFunc func(*http.Request, *response) error
+		code      int
+	}{
+		{
+			name: "ok",
+			patchFunc: func(*http.Request, *response) error {
+				return nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "validation error",
+			patchFunc: func(*http.Request, *response) error {
+				return validationErrorResponse{}
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not found error",
+			patchFunc: func(*http.Request, *response) error {
+				return notFoundResponse{}
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "wrong type error",
+			patchFunc: func(*http.Request, *response) error {
+				return wrongTypeResponse{}
+			},
+			code: http.StatusConflict,
+		},
+		{
+			name: "unsupported media type error",
+			patchFunc: func(*http.Request, *response) error {
+				return errors.Wrap(unsupportedMediaTypeResponse{}, "decode failed")
+			},
+			code: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "unprocessable error",
+			patchFunc: func(*http.Request, *response) error {
+				return errors.Wrap(unprocessableResponse{}, "patch failed")
+			},
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "unexpected error",
+			patchFunc: func(*http.Request, *response) error {
+				return errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("PATCH", "http://any-host/v1/keys/key", nil)
+			res := httptest.NewRecorder()
+
+			h := NewHandler(PatcherFunc(tt.patchFunc))
+			h(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+		})
+	}
+}
+
+type PatcherFunc func(*http.Request, *response) error
+
+func (f PatcherFunc) Patch(r *http.Request, resp *response) error {
+	return f(r, resp)
+}
@@ -0,0 +1,331 @@
+package patch
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/jsondoc"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+
+	keyPatchedMessage              = "key patched"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "key not found"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+	unsupportedMediaTypeMessage    = "content type must be " + mergePatchContentType + " or " + jsonPatchContentType
+)
+
+// Patcher service for patch key requests.
+type Patcher interface {
+	Patch(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service, keys and patched
+// values are validated with the rules and schemas.
+func NewService(storage storage.Storage, rules validate.Rules, schemas *schema.Registry) Patcher {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules:   rules,
+			schemas: schemas,
+		},
+		patcher: &sPatcher{
+			storage: storage,
+		},
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	patcher
+}
+
+type request struct {
+	Key string
+	// Merge is true for RFC 7396 merge
+	// patch, otherwise Operations hold
+	// RFC 6902 JSON Patch.
+	Merge      bool
+	MergePatch interface{}
+	Operations []jsondoc.Operation
+}
+
+// apply returns value patched by the request,
+// raw values are not JSON documents to patch.
+func (r request) apply(value interface{}) (interface{}, error) {
+	if _, ok := value.(storage.Blob); ok {
+		return nil, storage.ErrWrongType
+	}
+
+	if r.Merge {
+		return jsondoc.MergePatch(value, r.MergePatch), nil
+	}
+
+	return jsondoc.Patch(value, r.Operations)
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(request) error
+	ValidateValue(key string, value interface{}) error
+}
+
+type patcher interface {
+	Patch(key string, fn func(interface{}) (interface{}, error)) (interface{}, error)
+}
+
+func (s muxMap) Patch(r *http.Request, resp *response) error {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req); err != nil {
+		return errors.Wrap(err, "validation failed")
+	}
+
+	value, err := s.patcher.Patch(req.Key, func(value interface{}) (interface{}, error) {
+		patched, err := req.apply(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.validater.ValidateValue(req.Key, patched); err != nil {
+			return nil, err
+		}
+
+		return patched, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "patch failed")
+	}
+
+	resp.Message = keyPatchedMessage
+	resp.Data = data{
+		Value: value,
+	}
+
+	return nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	req.Key = mux.Vars(r)["key"]
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var err error
+	switch contentType {
+	case mergePatchContentType:
+		req.Merge = true
+		err = decode.Strict(r.Body, &req.MergePatch)
+	case jsonPatchContentType:
+		err = decode.Strict(r.Body, &req.Operations)
+	default:
+		return unsupportedMediaTypeResponse{Message: unsupportedMediaTypeMessage}
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "unable to decode")
+	}
+
+	return nil
+}
+
+type ozzoValidater struct {
+	rules   validate.Rules
+	schemas *schema.Registry
+}
+
+func (v ozzoValidater) Validate(r request) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	if err := v.rules.Key(r.Key); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "key", Message: err.Error()},
+		)
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+// ValidateValue validates value of the key
+// produced by the patch before it is stored.
+func (v ozzoValidater) ValidateValue(key string, value interface{}) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	if err := v.rules.Value(value); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "value", Message: err.Error()},
+		)
+	}
+
+	violations, err := v.schemas.Validate(key, value)
+	if err != nil {
+		return errors.Wrap(err, "schema validation")
+	}
+
+	for _, violation := range violations {
+		field := "value"
+		if violation.Field != "" {
+			field += "." + violation.Field
+		}
+
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: field, Message: violation.Message},
+		)
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sPatcher struct {
+	storage storage.Storage
+}
+
+func (p *sPatcher) Patch(key string, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+	value, err := p.storage.Update(key, fn)
+	switch err := err.(type) {
+	case nil:
+		return value, nil
+	case *jsondoc.OperationError:
+		return nil, unprocessableResponse{Message: err.Error()}
+	}
+
+	switch err {
+	case storage.ErrNotFound:
+		return nil, notFoundResponse{Message: notFoundMessage}
+	case storage.ErrWrongType:
+		return nil, wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return nil, quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return nil, quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return nil, quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return nil, err
+	}
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type unsupportedMediaTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r unsupportedMediaTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r unsupportedMediaTypeResponse) Code() responses.Code {
+	return responses.CodeUnsupportedMediaType
+}
+
+type unprocessableResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r unprocessableResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r unprocessableResponse) Code() responses.Code {
+	return responses.CodePatchFailed
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
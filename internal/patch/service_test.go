@@ -0,0 +1,287 @@
+package patch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/jsondoc"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterMock struct {
+	validateFunc      func(request) error
+	validateValueFunc func(string, interface{}) error
+}
+
+func (m validaterMock) Validate(r request) error {
+	return m.validateFunc(r)
+}
+
+func (m validaterMock) ValidateValue(key string, value interface{}) error {
+	return m.validateValueFunc(key, value)
+}
+
+type patcherFunc func(string, func(interface{}) (interface{}, error)) (interface{}, error)
+
+func (f patcherFunc) Patch(key string, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+	return f(key, fn)
+}
+
+func Test_muxMap_Patch(t *testing.T) {
+	tests := []struct {
+		name              string
+		decodeFunc        func(*http.Request, *request) error
+		validateFunc      func(request) error
+		validateValueFunc func(string, interface{}) error
+		patchFunc         func(string, func(interface{}) (interface{}, error)) (interface{}, error)
+		wantErr           bool
+		expect            response
+	}{
+		{
+			name: "decoder error",
+			decodeFunc: func(*http.Request, *request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "validater error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "patcher error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			patchFunc: func(string, func(interface{}) (interface{}, error)) (interface{}, error) {
+				return nil, errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "patched value validation error",
+			decodeFunc: func(_ *http.Request, req *request) error {
+				req.Merge = true
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			validateValueFunc: func(string, interface{}) error {
+				return errors.New("mock error")
+			},
+			patchFunc: func(_ string, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+				return fn(map[string]interface{}{"a": 1.0})
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok",
+			decodeFunc: func(_ *http.Request, req *request) error {
+				req.Merge = true
+				req.MergePatch = map[string]interface{}{"b": 2.0}
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			validateValueFunc: func(string, interface{}) error {
+				return nil
+			},
+			patchFunc: func(_ string, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+				return fn(map[string]interface{}{"a": 1.0})
+			},
+			expect: response{
+				Message: "key patched",
+				Data: data{
+					Value: map[string]interface{}{"a": 1.0, "b": 2.0},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder: decoderFunc(tt.decodeFunc),
+				validater: validaterMock{
+					validateFunc:      tt.validateFunc,
+					validateValueFunc: tt.validateValueFunc,
+				},
+				patcher: patcherFunc(tt.patchFunc),
+			}
+
+			var got response
+			err := s.Patch(nil, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_jsonDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+		expect      request
+	}{
+		{
+			name:        "merge patch",
+			contentType: "application/merge-patch+json; charset=utf-8",
+			body:        `{"a": null}`,
+			expect: request{
+				Key:        "key",
+				Merge:      true,
+				MergePatch: map[string]interface{}{"a": nil},
+			},
+		},
+		{
+			name:        "json patch",
+			contentType: "application/json-patch+json",
+			body:        `[]`,
+			expect: request{
+				Key:        "key",
+				Operations: []jsondoc.Operation{},
+			},
+		},
+		{
+			name:        "unsupported media type",
+			contentType: "application/json",
+			body:        `{}`,
+			wantErr:     true,
+		},
+		{
+			name:        "malformed body",
+			contentType: "application/json-patch+json",
+			body:        `{`,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("PATCH", "http://any/v1/keys/key", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = mux.SetURLVars(req, map[string]string{"key": "key"})
+
+			var got request
+			err := jsonDecoder{}.Decode(req, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_sPatcher_Patch(t *testing.T) {
+	p := &sPatcher{
+		storage: storage.New(),
+	}
+
+	p.storage.Set(context.Background(), "key", map[string]interface{}{"a": 1.0})
+	p.storage.SAdd(context.Background(), "set", "a")
+	blob := storage.Blob{ContentType: "application/octet-stream", Data: []byte{0xff}}
+	p.storage.Set(context.Background(), "blob", blob)
+
+	fail := request{Operations: []jsondoc.Operation{{Op: "unknown"}}}
+	merge := request{Merge: true, MergePatch: map[string]interface{}{"a": 2.0}}
+
+	_, err := p.Patch("key", fail.apply)
+	assert.IsType(t, unprocessableResponse{}, err)
+
+	_, err = p.Patch("not found", fail.apply)
+	assert.Equal(t, notFoundResponse{Message: "key not found"}, err)
+
+	_, err = p.Patch("set", fail.apply)
+	assert.Equal(t, wrongTypeResponse{Message: "key holds the wrong kind of value"}, err)
+
+	_, err = p.Patch("blob", merge.apply)
+	assert.Equal(t, wrongTypeResponse{Message: "key holds the wrong kind of value"}, err)
+
+	value, _, err := p.storage.Peek("blob")
+	assert.Nil(t, err)
+	assert.Equal(t, blob, value)
+
+	value, err = p.storage.Get("key")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1.0}, value)
+}
+
+func Test_ozzoValidater_ValidateValue(t *testing.T) {
+	schemas := schema.NewRegistry()
+	assert.Nil(t, schemas.Register("user:", map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name"},
+	}))
+
+	validater := ozzoValidater{
+		rules:   validate.Rules{MaxValueSize: 16},
+		schemas: schemas,
+	}
+
+	assert.Nil(t, validater.ValidateValue("key", "value"))
+	assert.Equal(t, validationErrorResponse{
+		Message: "you have validation errors",
+		Errors: []validationError{
+			validationError{
+				Field:   "value",
+				Message: "the size must be no more than 16 bytes",
+			},
+		},
+	}, validater.ValidateValue("key", "very large value"))
+
+	assert.Nil(t, validater.ValidateValue("user:1", map[string]interface{}{"name": "a"}))
+	assert.Equal(t, validationErrorResponse{
+		Message: "you have validation errors",
+		Errors: []validationError{
+			validationError{
+				Field:   "value",
+				Message: "name is required",
+			},
+		},
+	}, validater.ValidateValue("user:1", map[string]interface{}{}))
+}
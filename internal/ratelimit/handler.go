@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewHandler returns handler for rate limit requests,
+// denied requests are responded with allowed false.
+func NewHandler(srv Throttler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := srv.Throttle(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type throttleData struct {
+	Allowed   bool `json:"allowed"`
+	Limit     int  `json:"limit"`
+	Remaining int  `json:"remaining"`
+	// ResetAt is time when all the
+	// burst would be allowed again.
+	ResetAt time.Time `json:"reset_at"`
+	// RetryAt is time when denied requests
+	// would be allowed, unless they never are.
+	RetryAt *time.Time `json:"retry_at,omitempty"`
+}
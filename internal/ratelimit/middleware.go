@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// Error describes limited request.
+type Error struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Code implements the responses.Coder interface.
+func (e *Error) Code() responses.Code {
+	return responses.CodeRateLimited
+}
+
+// Middleware returns middleware which responds 429
+// with Retry-After header to requests over the limit
+// of their remote address, X-RateLimit-* headers report
+// the limit of the rule. It precedes authentication, so
+// unauthenticated requests are limited too.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return l.limit(Addr, next)
+}
+
+// PrincipalMiddleware is like Middleware, but limits
+// requests of every authenticated principal, others
+// are passed through. It follows authentication.
+func (l *Limiter) PrincipalMiddleware(next http.Handler) http.Handler {
+	return l.limit(principal, next)
+}
+
+// limit limits requests of the client,
+// requests without client are not limited.
+func (l *Limiter) limit(client func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := client(r)
+		if c == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		res, ok := l.Allow(c, r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("X-RateLimit-Reset", seconds(res.Reset))
+
+		if !res.Allowed {
+			w.Header().Set("Retry-After", seconds(res.RetryAfter))
+			responses.Error(w, r, &Error{
+				Message: fmt.Sprintf("limit of %d requests exceeded", res.Limit),
+			})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// connKey is context key of the connection id.
+type connKey struct{}
+
+// connIDs counts identified connections.
+var connIDs uint64
+
+// ConnContext identifies connections of unix sockets in the
+// context, it is the http.Server ConnContext. Their clients
+// have no address, so they are limited per connection.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if _, ok := c.LocalAddr().(*net.UnixAddr); !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, connKey{}, atomic.AddUint64(&connIDs, 1))
+}
+
+// Addr returns remote address of the request,
+// or its connection for unix sockets.
+func Addr(r *http.Request) string {
+	if id, ok := r.Context().Value(connKey{}).(uint64); ok {
+		return "conn:" + strconv.FormatUint(id, 10)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "addr:" + host
+}
+
+// principal returns authenticated principal
+// of the request, empty for anonymous ones.
+func principal(r *http.Request) string {
+	p, ok := auth.FromContext(r.Context())
+	if !ok {
+		return ""
+	}
+
+	return "principal:" + p.Name
+}
+
+// seconds formats d in whole seconds rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
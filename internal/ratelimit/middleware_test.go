@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/auth"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Middleware(t *testing.T) {
+	start := time.Unix(0, 0).Add(1000 * time.Minute)
+	now = func() time.Time { return start.Add(45 * time.Second) }
+	defer func() { now = time.Now }()
+
+	l, err := New(storage.New(), []Rule{{Limit: 1, Window: time.Minute}})
+	if err != nil {
+		t.Fatalf("new limiter: %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := l.Middleware(ok)
+	principalHandler := l.PrincipalMiddleware(ok)
+
+	tests := []struct {
+		name      string
+		handler   http.Handler
+		addr      string
+		principal string
+		code      int
+		headers   map[string]string
+	}{
+		{
+			name: "allowed",
+			code: http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "1",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "15",
+				"Retry-After":           "",
+			},
+		},
+		{
+			name: "limited",
+			code: http.StatusTooManyRequests,
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "1",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "15",
+				"Retry-After":           "75",
+			},
+		},
+		{
+			name: "other address",
+			addr: "10.0.0.2:4321",
+			code: http.StatusOK,
+		},
+		{
+			name:      "principal",
+			handler:   principalHandler,
+			principal: "app",
+			code:      http.StatusOK,
+		},
+		{
+			name:      "principal limited",
+			handler:   principalHandler,
+			addr:      "10.0.0.3:4321",
+			principal: "app",
+			code:      http.StatusTooManyRequests,
+		},
+		{
+			name:    "anonymous not limited by principal",
+			handler: principalHandler,
+			code:    http.StatusOK,
+			headers: map[string]string{
+				"X-RateLimit-Limit": "",
+			},
+		},
+	}
+
+	// Cases share counters, so they run in order.
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "http://any-host/get", nil)
+		if tt.addr != "" {
+			req.RemoteAddr = tt.addr
+		}
+		if tt.principal != "" {
+			req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Name: tt.principal}))
+		}
+		res := httptest.NewRecorder()
+
+		h := tt.handler
+		if h == nil {
+			h = handler
+		}
+		h.ServeHTTP(res, req)
+
+		assert.Equal(t, tt.code, res.Code, tt.name)
+		for header, value := range tt.headers {
+			assert.Equal(t, value, res.Header().Get(header), tt.name+" "+header)
+		}
+	}
+}
+
+// unixConn is connection of unix socket.
+type unixConn struct {
+	net.Conn
+}
+
+func (unixConn) LocalAddr() net.Addr {
+	return &net.UnixAddr{Name: "/tmp/integral.sock", Net: "unix"}
+}
+
+func TestAddr(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://any-host/get", nil)
+	req.RemoteAddr = "10.0.0.1:4321"
+	assert.Equal(t, "addr:10.0.0.1", Addr(req))
+
+	req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Name: "app"}))
+	assert.Equal(t, "addr:10.0.0.1", Addr(req))
+	assert.Equal(t, "principal:app", principal(req))
+
+	// Connections of unix sockets are told apart.
+	first := req.WithContext(ConnContext(context.Background(), unixConn{}))
+	second := req.WithContext(ConnContext(context.Background(), unixConn{}))
+	assert.Regexp(t, "^conn:[0-9]+$", Addr(first))
+	assert.NotEqual(t, Addr(first), Addr(second))
+}
@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+// hitsField is field of the counter
+// hash holding number of requests.
+const hitsField = "hits"
+
+var now = time.Now
+
+// Rule limits requests with path prefix and method, empty
+// ones match any request. Every client has own counters.
+type Rule struct {
+	Path   string
+	Method string
+	// Limit is number of requests
+	// allowed in the window.
+	Limit  int
+	Window time.Duration
+}
+
+// Result of the rate limit check.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is time until the current window ends.
+	Reset time.Duration
+	// RetryAfter is time until a request
+	// could be allowed, zero when allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter checks requests against the first
+// matching rule, others are not limited.
+type Limiter struct {
+	rules   []Rule
+	storage storage.Storage
+}
+
+// New returns limiter of the rules keeping
+// counters in the storage.
+func New(s storage.Storage, rules []Rule) (*Limiter, error) {
+	for i, rule := range rules {
+		if rule.Limit <= 0 {
+			return nil, errors.Errorf("rule %d: limit must be positive", i)
+		}
+		if rule.Window <= 0 {
+			return nil, errors.Errorf("rule %d: window must be positive", i)
+		}
+	}
+
+	return &Limiter{rules: rules, storage: s}, nil
+}
+
+// fileRule is rule in the file.
+type fileRule struct {
+	Path   string `json:"path"`
+	Method string `json:"method"`
+	Limit  int    `json:"limit"`
+	Window string `json:"window"`
+}
+
+// Load loads rules from the file with JSON
+// array of rules, window is a duration
+// like "1m".
+func Load(path string) ([]Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open config")
+	}
+	defer f.Close()
+
+	var files []fileRule
+	if err := json.NewDecoder(f).Decode(&files); err != nil {
+		return nil, errors.Wrap(err, "decode config")
+	}
+
+	rules := make([]Rule, len(files))
+	for i, fr := range files {
+		window, err := time.ParseDuration(fr.Window)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %d", i)
+		}
+
+		rules[i] = Rule{
+			Path:   fr.Path,
+			Method: strings.ToUpper(fr.Method),
+			Limit:  fr.Limit,
+			Window: window,
+		}
+	}
+
+	return rules, nil
+}
+
+// Allow counts request of the client against the
+// first matching rule, denied requests are not
+// counted. Ok is false when no rule matches.
+func (l *Limiter) Allow(client string, r *http.Request) (res Result, ok bool) {
+	for i, rule := range l.rules {
+		if rule.matches(r) {
+			return l.allow(counter{rule: i, client: client}, rule), true
+		}
+	}
+
+	return Result{}, false
+}
+
+func (rule Rule) matches(r *http.Request) bool {
+	if rule.Method != "" && rule.Method != r.Method {
+		return false
+	}
+
+	return strings.HasPrefix(r.URL.Path, rule.Path)
+}
+
+// counter is key of the client
+// counter of the rule window.
+type counter struct {
+	rule   int
+	client string
+	window int64
+}
+
+// allow applies sliding window of the rule, requests
+// of the previous window are weighted by its part
+// overlapping with the sliding one.
+func (l *Limiter) allow(key counter, rule Rule) Result {
+	t := now()
+	window := int64(rule.Window)
+	key.window = t.UnixNano() / window
+	elapsed := time.Duration(t.UnixNano() % window)
+
+	prevKey := key
+	prevKey.window--
+	prev := l.hits(prevKey)
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+
+	// Counters live through the next window,
+	// where they are weighted as previous.
+	hits, err := l.storage.HIncrBy(storage.Expire(2*rule.Window-elapsed), key, hitsField, 1)
+	if err != nil {
+		// Counters are written only here,
+		// so the request is allowed.
+		return Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit}
+	}
+
+	res := Result{
+		Limit: rule.Limit,
+		Reset: rule.Window - elapsed,
+	}
+
+	count := float64(prev)*weight + float64(hits)
+	if count <= float64(rule.Limit) {
+		res.Allowed = true
+		res.Remaining = int(float64(rule.Limit) - count)
+		return res
+	}
+
+	l.storage.HIncrBy(storage.Expire(0), key, hitsField, -1)
+	res.RetryAfter = retryAfter(float64(prev), float64(hits-1), elapsed, rule)
+
+	return res
+}
+
+// hits returns number of requests counted by the counter.
+func (l *Limiter) hits(key counter) int64 {
+	value, err := l.storage.HGet(key, hitsField)
+	if err != nil {
+		return 0
+	}
+
+	hits, _ := value.(int64)
+	return hits
+}
+
+// retryAfter returns time until one more request
+// fits the limit with prev and curr requests
+// counted in the previous and current windows.
+func retryAfter(prev, curr float64, elapsed time.Duration, rule Rule) time.Duration {
+	window := float64(rule.Window)
+	limit := float64(rule.Limit)
+
+	// Weight of the previous window decreases
+	// until prev*weight+curr+1 fits the limit.
+	if curr+1 <= limit && prev > 0 {
+		wait := window*(1-(limit-curr-1)/prev) - float64(elapsed)
+		return time.Duration(math.Max(math.Ceil(wait), 1))
+	}
+
+	// Otherwise current window becomes the previous
+	// one and its weight must decrease in the next.
+	wait := float64(rule.Window-elapsed) + window*(1-(limit-1)/curr)
+	return time.Duration(math.Max(math.Ceil(wait), 1))
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	start := time.Unix(0, 0).Add(1000 * time.Minute)
+	defer func() { now = time.Now }()
+
+	l, err := New(storage.New(), []Rule{
+		{Path: "/set", Method: "POST", Limit: 2, Window: time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("new limiter: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		at     time.Duration
+		client string
+		target string
+		ok     bool
+		expect Result
+	}{
+		{
+			name:   "first",
+			at:     30 * time.Second,
+			client: "a",
+			target: "/set",
+			ok:     true,
+			expect: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 30 * time.Second},
+		},
+		{
+			name:   "second",
+			at:     30 * time.Second,
+			client: "a",
+			target: "/set",
+			ok:     true,
+			expect: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 30 * time.Second},
+		},
+		{
+			name:   "over limit",
+			at:     30 * time.Second,
+			client: "a",
+			target: "/set",
+			ok:     true,
+			expect: Result{Limit: 2, Reset: 30 * time.Second, RetryAfter: time.Minute},
+		},
+		{
+			name:   "other client",
+			at:     30 * time.Second,
+			client: "b",
+			target: "/set",
+			ok:     true,
+			expect: Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 30 * time.Second},
+		},
+		{
+			name:   "other route",
+			at:     30 * time.Second,
+			client: "a",
+			target: "/get",
+		},
+		{
+			name:   "weighted previous window",
+			at:     80 * time.Second,
+			client: "a",
+			target: "/set",
+			ok:     true,
+			expect: Result{Limit: 2, Reset: 40 * time.Second, RetryAfter: 10 * time.Second},
+		},
+		{
+			name:   "retried",
+			at:     90 * time.Second,
+			client: "a",
+			target: "/set",
+			ok:     true,
+			expect: Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 30 * time.Second},
+		},
+	}
+
+	// Cases share counters, so they run in order.
+	for _, tt := range tests {
+		now = func() time.Time { return start.Add(tt.at) }
+
+		method := "POST"
+		if tt.target == "/get" {
+			method = "GET"
+		}
+		req := httptest.NewRequest(method, "http://any-host"+tt.target, nil)
+
+		res, ok := l.Allow(tt.client, req)
+		assert.Equal(t, tt.ok, ok, tt.name)
+		assert.Equal(t, tt.expect, res, tt.name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		expect []Rule
+		err    string
+	}{
+		{
+			name:   "valid",
+			config: `[{"path": "/set", "method": "post", "limit": 10, "window": "1s"}]`,
+			expect: []Rule{{Path: "/set", Method: "POST", Limit: 10, Window: time.Second}},
+		},
+		{
+			name:   "invalid window",
+			config: `[{"limit": 10, "window": "often"}]`,
+			err:    `rule 0: time: invalid duration "often"`,
+		},
+		{
+			name:   "malformed",
+			config: `{}`,
+			err:    "decode config: json: cannot unmarshal object into Go value of type []ratelimit.fileRule",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := ioutil.TempFile("", "ratelimit")
+			if err != nil {
+				t.Fatalf("create temp file: %v", err)
+			}
+			defer os.Remove(f.Name())
+			f.WriteString(tt.config)
+			f.Close()
+
+			rules, err := Load(f.Name())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, rules)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(storage.New(), []Rule{{Limit: 0, Window: time.Second}})
+	assert.EqualError(t, err, "rule 0: limit must be positive")
+
+	_, err = New(storage.New(), []Rule{{Limit: 1}})
+	assert.EqualError(t, err, "rule 0: window must be positive")
+}
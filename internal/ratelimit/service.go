@@ -0,0 +1,265 @@
+package ratelimit
+
+import (
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	allowedMessage                 = "request allowed"
+	deniedMessage                  = "request denied"
+	validationErrorResponseMessage = "you have validation errors"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+	periodMessage                  = "must be a positive duration like 1s"
+	quantityMessage                = "must be between 0 and burst"
+	intervalMessage                = "must be at least 1ns per request"
+	burstSpanMessage               = "must not span over 100 years at the rate"
+)
+
+// Throttler service for rate limit requests.
+type Throttler interface {
+	Throttle(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, rules validate.Rules) Throttler {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		throttler: &sThrottler{
+			storage: storage,
+		},
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	throttler
+}
+
+type request struct {
+	Key    string `json:"-"`
+	Rate   int    `json:"rate"`
+	Period string `json:"period"`
+	Burst  int    `json:"burst"`
+	// Quantity of the requests,
+	// one when it is omitted.
+	Quantity *int `json:"quantity"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(request) error
+}
+
+type throttler interface {
+	Throttle(key string, rate storage.Rate, quantity int) (storage.ThrottleResult, error)
+}
+
+func (s muxMap) Throttle(r *http.Request, resp *response) error {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req); err != nil {
+		return errors.Wrap(err, "validation failed")
+	}
+
+	period, _ := time.ParseDuration(req.Period)
+	rate := storage.Rate{Count: req.Rate, Period: period, Burst: req.Burst}
+
+	res, err := s.throttler.Throttle(req.Key, rate, *req.Quantity)
+	if err != nil {
+		return errors.Wrap(err, "throttle failed")
+	}
+
+	t := time.Now()
+	data := throttleData{
+		Allowed:   res.Allowed,
+		Limit:     req.Burst,
+		Remaining: res.Remaining,
+		ResetAt:   t.Add(res.ResetAfter),
+	}
+
+	resp.Message = allowedMessage
+	if !res.Allowed {
+		resp.Message = deniedMessage
+		if res.RetryAfter > 0 {
+			retryAt := t.Add(res.RetryAfter)
+			data.RetryAt = &retryAt
+		}
+	}
+	resp.Data = data
+
+	return nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	if err := decode.Request(r, req); err != nil {
+		return err
+	}
+
+	req.Key = mux.Vars(r)["key"]
+	if req.Quantity == nil {
+		quantity := 1
+		req.Quantity = &quantity
+	}
+
+	return nil
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	if err := v.rules.Key(r.Key); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "key", Message: err.Error()},
+		)
+	}
+
+	if err := validation.Validate(r.Rate, validation.Required, validation.Min(1)); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "rate", Message: err.Error()},
+		)
+	}
+
+	period, err := time.ParseDuration(r.Period)
+	if err != nil || period <= 0 {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "period", Message: periodMessage},
+		)
+	}
+
+	if err := validation.Validate(r.Burst, validation.Required, validation.Min(1)); err != nil {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "burst", Message: err.Error()},
+		)
+	}
+
+	// Rate is checked as a whole once
+	// its fields are valid on their own.
+	if len(validatationError.Errors) == 0 {
+		rate := storage.Rate{Count: r.Rate, Period: period, Burst: r.Burst}
+		switch {
+		case period/time.Duration(r.Rate) == 0:
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "period", Message: intervalMessage},
+			)
+		case !rate.Valid():
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "burst", Message: burstSpanMessage},
+			)
+		}
+	}
+
+	if r.Quantity != nil && (*r.Quantity < 0 || *r.Quantity > r.Burst) {
+		validatationError.Errors = append(validatationError.Errors,
+			validationError{Field: "quantity", Message: quantityMessage},
+		)
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sThrottler struct {
+	storage storage.Storage
+}
+
+func (s *sThrottler) Throttle(key string, rate storage.Rate, quantity int) (storage.ThrottleResult, error) {
+	res, err := s.storage.Throttle(key, rate, quantity)
+	switch err {
+	case storage.ErrWrongType:
+		return res, wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return res, quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return res, quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return res, quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return res, err
+	}
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
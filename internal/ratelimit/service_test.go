@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterFunc func(request) error
+
+func (f validaterFunc) Validate(r request) error {
+	return f(r)
+}
+
+type throttlerFunc func(string, storage.Rate, int) (storage.ThrottleResult, error)
+
+func (f throttlerFunc) Throttle(key string, rate storage.Rate, quantity int) (storage.ThrottleResult, error) {
+	return f(key, rate, quantity)
+}
+
+func Test_muxMap_Throttle(t *testing.T) {
+	quantity := 2
+
+	tests := []struct {
+		name         string
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request) error
+		throttleFunc func(string, storage.Rate, int) (storage.ThrottleResult, error)
+		wantErr      bool
+		message      string
+		allowed      bool
+		retry        bool
+	}{
+		{
+			name: "decoder error",
+			decodeFunc: func(*http.Request, *request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "validater error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "throttler error",
+			decodeFunc: func(_ *http.Request, req *request) error {
+				req.Quantity = &quantity
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			throttleFunc: func(string, storage.Rate, int) (storage.ThrottleResult, error) {
+				return storage.ThrottleResult{}, errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "allowed",
+			decodeFunc: func(_ *http.Request, req *request) error {
+				*req = request{Key: "key", Rate: 1, Period: "1s", Burst: 5, Quantity: &quantity}
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			throttleFunc: func(key string, rate storage.Rate, n int) (storage.ThrottleResult, error) {
+				assert.Equal(t, "key", key)
+				assert.Equal(t, storage.Rate{Count: 1, Period: time.Second, Burst: 5}, rate)
+				assert.Equal(t, 2, n)
+				return storage.ThrottleResult{Allowed: true, Remaining: 3, ResetAfter: 2 * time.Second}, nil
+			},
+			message: "request allowed",
+			allowed: true,
+		},
+		{
+			name: "denied",
+			decodeFunc: func(_ *http.Request, req *request) error {
+				*req = request{Key: "key", Rate: 1, Period: "1s", Burst: 5, Quantity: &quantity}
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			throttleFunc: func(string, storage.Rate, int) (storage.ThrottleResult, error) {
+				return storage.ThrottleResult{ResetAfter: 5 * time.Second, RetryAfter: time.Second}, nil
+			},
+			message: "request denied",
+			retry:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderFunc(tt.decodeFunc),
+				validater: validaterFunc(tt.validateFunc),
+				throttler: throttlerFunc(tt.throttleFunc),
+			}
+
+			var got response
+			req := httptest.NewRequest("POST", "http://any", nil)
+			err := s.Throttle(req, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.message, got.Message)
+
+			data := got.Data.(throttleData)
+			assert.Equal(t, tt.allowed, data.Allowed)
+			assert.Equal(t, 5, data.Limit)
+			assert.Equal(t, tt.retry, data.RetryAt != nil)
+		})
+	}
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	quantity := 3
+
+	tests := []struct {
+		name   string
+		req    request
+		expect error
+	}{
+		{
+			name: "valid",
+			req:  request{Key: "key", Rate: 10, Period: "1s", Burst: 3, Quantity: &quantity},
+		},
+		{
+			name: "invalid",
+			req:  request{Key: "key", Period: "-1s", Burst: 2, Quantity: &quantity},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "rate", Message: "cannot be blank"},
+					validationError{Field: "period", Message: "must be a positive duration like 1s"},
+					validationError{Field: "quantity", Message: "must be between 0 and burst"},
+				},
+			},
+		},
+		{
+			name: "rate over period",
+			req:  request{Key: "key", Rate: 2, Period: "1ns", Burst: 1},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "period", Message: "must be at least 1ns per request"},
+				},
+			},
+		},
+		{
+			name: "burst overflow",
+			req:  request{Key: "key", Rate: 1, Period: "1h", Burst: 1e9},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "burst", Message: "must not span over 100 years at the rate"},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{rules: validate.Rules{}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, validater.Validate(tt.req))
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package resp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/romanyx/integral_db/internal/storage"
+)
+
+const (
+	serverName = "integral_db"
+
+	errSyntax        = "ERR syntax error"
+	errNotInteger    = "ERR value is not an integer or out of range"
+	errWrongType     = "WRONGTYPE Operation against a key holding the wrong kind of value"
+	errInvalidExpire = "ERR invalid expire time in '%s' command"
+)
+
+// session is state of the client connection.
+type session struct {
+	id   int64
+	w    *Writer
+	quit bool
+}
+
+// command executes arguments of the command,
+// name is the first one.
+type command struct {
+	// arity is number of arguments including
+	// the name, negative one is the minimum.
+	arity int
+	fn    func(s *Server, sess *session, args [][]byte)
+}
+
+var commands = map[string]command{
+	"PING":   {-1, (*Server).ping},
+	"HELLO":  {-1, (*Server).hello},
+	"QUIT":   {-1, (*Server).quit},
+	"GET":    {2, (*Server).get},
+	"GETDEL": {2, (*Server).getDel},
+	"SET":    {-3, (*Server).set},
+	"DEL":    {-2, (*Server).del},
+	"EXISTS": {-2, (*Server).exists},
+	"TTL":    {2, (*Server).ttl},
+	"EXPIRE": {3, (*Server).expire},
+	"INFO":   {-1, (*Server).info},
+}
+
+// execute replies to the command of the session.
+func (s *Server) execute(sess *session, args [][]byte) {
+	name := strings.ToUpper(string(args[0]))
+
+	cmd, ok := commands[name]
+	if !ok {
+		sess.w.Error(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		sess.w.Error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+		return
+	}
+
+	cmd.fn(s, sess, args)
+}
+
+// ping replies with PONG or the message.
+func (s *Server) ping(sess *session, args [][]byte) {
+	switch len(args) {
+	case 1:
+		sess.w.Simple("PONG")
+	case 2:
+		sess.w.Bulk(args[1])
+	default:
+		sess.w.Error("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+// hello switches protocol version and
+// replies with the server properties.
+func (s *Server) hello(sess *session, args [][]byte) {
+	proto := sess.w.Protocol()
+	if len(args) > 1 {
+		v, err := strconv.Atoi(string(args[1]))
+		if err != nil {
+			sess.w.Error("ERR Protocol version is not an integer or out of range")
+			return
+		}
+		if v != RESP2 && v != RESP3 {
+			sess.w.Error("NOPROTO unsupported protocol version")
+			return
+		}
+		proto = v
+	}
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "SETNAME":
+			if i+1 >= len(args) {
+				sess.w.Error(errSyntax)
+				return
+			}
+			i++
+		case "AUTH":
+			sess.w.Error("ERR AUTH is not supported")
+			return
+		default:
+			sess.w.Error(errSyntax)
+			return
+		}
+	}
+
+	sess.w.SetProtocol(proto)
+
+	sess.w.Map(6)
+	sess.w.Bulk([]byte("server"))
+	sess.w.Bulk([]byte(serverName))
+	sess.w.Bulk([]byte("proto"))
+	sess.w.Int(int64(proto))
+	sess.w.Bulk([]byte("id"))
+	sess.w.Int(sess.id)
+	sess.w.Bulk([]byte("mode"))
+	sess.w.Bulk([]byte("standalone"))
+	sess.w.Bulk([]byte("role"))
+	sess.w.Bulk([]byte("master"))
+	sess.w.Bulk([]byte("modules"))
+	sess.w.Array(0)
+}
+
+// quit replies OK and closes the connection.
+func (s *Server) quit(sess *session, args [][]byte) {
+	sess.w.Simple("OK")
+	sess.quit = true
+}
+
+// get replies with value of the key, unlike
+// HTTP get it does not consume the key.
+func (s *Server) get(sess *session, args [][]byte) {
+	s.view(sess, string(args[1]), false)
+}
+
+// getDel replies with value of the key and removes it.
+func (s *Server) getDel(sess *session, args [][]byte) {
+	s.view(sess, string(args[1]), true)
+}
+
+func (s *Server) view(sess *session, key string, consume bool) {
+	value, err := s.storage.View(key, consume, func(value interface{}) (interface{}, error) {
+		return value, nil
+	})
+
+	switch err {
+	case nil:
+	case storage.ErrNotFound:
+		sess.w.Null()
+		return
+	case storage.ErrWrongType:
+		sess.w.Error(errWrongType)
+		return
+	default:
+		sess.w.Error("ERR " + err.Error())
+		return
+	}
+
+	b, err := encode(value)
+	if err != nil {
+		sess.w.Error("ERR " + err.Error())
+		return
+	}
+
+	sess.w.Bulk(b)
+}
+
+// set stores value of the key, EX and PX options
+// bound its lifetime, NX and XX make it conditional.
+func (s *Server) set(sess *session, args [][]byte) {
+	var (
+		key      = string(args[1])
+		cond     = storage.Always
+		lifetime = s.keyLiveTime
+		expires  bool
+	)
+
+	for i := 3; i < len(args); i++ {
+		switch opt := strings.ToUpper(string(args[i])); opt {
+		case "NX", "XX":
+			if cond != storage.Always {
+				sess.w.Error(errSyntax)
+				return
+			}
+			cond = storage.IfNotExists
+			if opt == "XX" {
+				cond = storage.IfExists
+			}
+		case "EX", "PX":
+			if expires || i+1 >= len(args) {
+				sess.w.Error(errSyntax)
+				return
+			}
+			i++
+			n, err := strconv.ParseInt(string(args[i]), 10, 64)
+			if err != nil {
+				sess.w.Error(errNotInteger)
+				return
+			}
+			unit := time.Second
+			if opt == "PX" {
+				unit = time.Millisecond
+			}
+			if n <= 0 || n > math.MaxInt64/int64(unit) {
+				sess.w.Error(fmt.Sprintf(errInvalidExpire, "set"))
+				return
+			}
+			lifetime, expires = time.Duration(n)*unit, true
+		default:
+			sess.w.Error(errSyntax)
+			return
+		}
+	}
+
+	value := decode(args[2])
+	if message := s.validate(key, value); message != "" {
+		sess.w.Error("ERR " + message)
+		return
+	}
+
+	ok, err := s.storage.SetIf(storage.Expire(lifetime), key, value, cond)
+	if err != nil {
+		sess.w.Error("ERR " + err.Error())
+		return
+	}
+
+	if !ok {
+		sess.w.Null()
+		return
+	}
+
+	sess.w.Simple("OK")
+}
+
+// validate returns violation of the
+// rules or schemas, empty when valid.
+func (s *Server) validate(key string, value interface{}) string {
+	if err := s.rules.Key(key); err != nil {
+		return "invalid key: " + err.Error()
+	}
+
+	if blob, ok := value.(storage.Blob); ok {
+		if err := s.rules.Value(blob.Data); err != nil {
+			return "invalid value: " + err.Error()
+		}
+		if s.schemas.Covers(key) {
+			return "invalid value: raw values can't be validated by the schema of the key"
+		}
+		return ""
+	}
+
+	if err := s.rules.Value(value); err != nil {
+		return "invalid value: " + err.Error()
+	}
+
+	violations, err := s.schemas.Validate(key, value)
+	if err != nil {
+		return err.Error()
+	}
+
+	if len(violations) > 0 {
+		v := violations[0]
+		if v.Field != "" {
+			return fmt.Sprintf("invalid value: %s: %s", v.Field, v.Message)
+		}
+		return "invalid value: " + v.Message
+	}
+
+	return ""
+}
+
+// del removes keys and replies
+// with number of removed ones.
+func (s *Server) del(sess *session, args [][]byte) {
+	sess.w.Int(int64(s.storage.Del(keys(args[1:])...)))
+}
+
+// exists replies with number of existing keys,
+// repeated keys are counted each time.
+func (s *Server) exists(sess *session, args [][]byte) {
+	var n int64
+	for _, key := range args[1:] {
+		if _, err := s.storage.Info(string(key)); err == nil {
+			n++
+		}
+	}
+
+	sess.w.Int(n)
+}
+
+// ttl replies with remaining lifetime of the key in seconds,
+// -1 when it is not bounded and -2 when key does not exist.
+func (s *Server) ttl(sess *session, args [][]byte) {
+	info, err := s.storage.Info(string(args[1]))
+	switch {
+	case err != nil:
+		sess.w.Int(-2)
+	case info.ExpiresAt.IsZero():
+		sess.w.Int(-1)
+	default:
+		ttl := time.Until(info.ExpiresAt)
+		if ttl < 0 {
+			ttl = 0
+		}
+		sess.w.Int(int64((ttl + time.Second/2) / time.Second))
+	}
+}
+
+// expire bounds lifetime of the key, non positive
+// seconds remove it. Replies 1 when key exists.
+func (s *Server) expire(sess *session, args [][]byte) {
+	key := string(args[1])
+
+	seconds, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		sess.w.Error(errNotInteger)
+		return
+	}
+
+	if seconds > math.MaxInt64/int64(time.Second) {
+		sess.w.Error(fmt.Sprintf(errInvalidExpire, "expire"))
+		return
+	}
+
+	if seconds <= 0 {
+		sess.w.Int(int64(s.storage.Del(key)))
+		return
+	}
+
+	switch err := s.storage.Expire(storage.Expire(time.Duration(seconds)*time.Second), key); err {
+	case nil:
+	case storage.ErrNotFound:
+		sess.w.Int(0)
+		return
+	default:
+		sess.w.Error("ERR " + err.Error())
+		return
+	}
+
+	sess.w.Int(1)
+}
+
+// info replies with server, clients and keyspace sections,
+// or only with the requested ones.
+func (s *Server) info(sess *session, args [][]byte) {
+	sections := map[string]bool{}
+	for _, arg := range args[1:] {
+		sections[strings.ToLower(string(arg))] = true
+	}
+	all := len(sections) == 0 || sections["all"] || sections["default"] || sections["everything"]
+
+	var b bytes.Buffer
+	if all || sections["server"] {
+		uptime := time.Since(s.started)
+		fmt.Fprintf(&b, "# Server\r\n")
+		fmt.Fprintf(&b, "server_name:%s\r\n", serverName)
+		fmt.Fprintf(&b, "redis_mode:standalone\r\n")
+		fmt.Fprintf(&b, "process_id:%d\r\n", os.Getpid())
+		fmt.Fprintf(&b, "uptime_in_seconds:%d\r\n", int64(uptime/time.Second))
+		fmt.Fprintf(&b, "uptime_in_days:%d\r\n", int64(uptime/(24*time.Hour)))
+		fmt.Fprintf(&b, "\r\n")
+	}
+	if all || sections["clients"] {
+		fmt.Fprintf(&b, "# Clients\r\n")
+		fmt.Fprintf(&b, "connected_clients:%d\r\n", s.clients())
+		fmt.Fprintf(&b, "\r\n")
+	}
+	if all || sections["keyspace"] {
+		fmt.Fprintf(&b, "# Keyspace\r\n")
+		if n := s.storage.Len(); n > 0 {
+			fmt.Fprintf(&b, "db0:keys=%d\r\n", n)
+		}
+	}
+
+	sess.w.Bulk(bytes.TrimRight(b.Bytes(), "\r\n"))
+}
+
+func keys(args [][]byte) []interface{} {
+	keys := make([]interface{}, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg)
+	}
+
+	return keys
+}
+
+// decode returns value stored for the argument,
+// UTF-8 text is stored as string and other bytes
+// as raw application/octet-stream blob, so values
+// are shared with HTTP clients.
+func decode(b []byte) interface{} {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+
+	return storage.Blob{ContentType: "application/octet-stream", Data: b}
+}
+
+// encode returns bytes of the bulk string of the value,
+// values set by HTTP clients are encoded as JSON.
+func encode(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case storage.Blob:
+		return v.Data, nil
+	default:
+		return json.Marshal(v)
+	}
+}
@@ -0,0 +1,399 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxBulkLength limits length of bulk strings.
+	maxBulkLength = 512 << 20
+	// maxElements limits number of elements
+	// of arrays, sets, maps and pushes.
+	maxElements = 1 << 20
+	// maxInlineLength limits length of lines
+	// of simple values and inline commands.
+	maxInlineLength = 64 << 10
+	// maxDepth limits nesting of aggregates.
+	maxDepth = 32
+	// maxPrealloc limits space allocated for
+	// bulk strings and aggregates before their
+	// data arrives, they grow while it's read.
+	maxPrealloc = 64 << 10
+)
+
+// Protocol versions negotiated by HELLO.
+const (
+	RESP2 = 2
+	RESP3 = 3
+)
+
+// ProtocolError describes malformed input,
+// connection is closed after it is reported.
+type ProtocolError struct {
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ProtocolError) Error() string {
+	return "Protocol error: " + e.Message
+}
+
+// Error is a simple or blob error value.
+type Error string
+
+// Error implements the error interface.
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Reader reads RESP2 and RESP3 values.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns reader of r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Buffered reports whether buffered input holds more
+// values, so replies of pipelined commands are flushed
+// together.
+func (r *Reader) Buffered() bool {
+	return r.r.Buffered() > 0
+}
+
+// ReadCommand reads command as arguments of the array
+// of bulk strings, lines of other kinds are read as
+// inline commands.
+func (r *Reader) ReadCommand() ([][]byte, error) {
+	b, err := r.r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := r.line()
+	if err != nil {
+		return nil, err
+	}
+
+	if b[0] != '*' {
+		return bytes.Fields(line), nil
+	}
+
+	n, err := length(line[1:], maxElements)
+	if err != nil || n <= 0 {
+		return nil, err
+	}
+
+	args := make([][]byte, 0, prealloc(n))
+	for i := 0; i < n; i++ {
+		line, err := r.line()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) == 0 || line[0] != '$' {
+			return nil, &ProtocolError{Message: fmt.Sprintf("expected '$', got %q", line)}
+		}
+
+		size, err := length(line[1:], maxBulkLength)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, &ProtocolError{Message: "expected bulk string arguments"}
+		}
+
+		arg, err := r.bulk(size)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// ReadValue reads single value. Simple, bulk and verbatim
+// strings are []byte, numbers are int64 or float64, big
+// numbers are strings, errors are Error, aggregates
+// are []interface{} except maps which are
+// map[interface{}]interface{} with string keys
+// instead of []byte ones. Nulls are nil.
+func (r *Reader) ReadValue() (interface{}, error) {
+	return r.value(0)
+}
+
+// value reads single value nested
+// in depth aggregates.
+func (r *Reader) value(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, &ProtocolError{Message: "too deeply nested aggregate"}
+	}
+
+	line, err := r.line()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, &ProtocolError{Message: "empty line"}
+	}
+
+	kind, rest := line[0], line[1:]
+	switch kind {
+	case '+':
+		return rest, nil
+	case '-':
+		return Error(rest), nil
+	case ':':
+		return parseInt(rest)
+	case '_':
+		return nil, nil
+	case '#':
+		switch string(rest) {
+		case "t":
+			return true, nil
+		case "f":
+			return false, nil
+		}
+		return nil, &ProtocolError{Message: fmt.Sprintf("invalid boolean %q", rest)}
+	case ',':
+		f, err := strconv.ParseFloat(string(rest), 64)
+		if err != nil {
+			return nil, &ProtocolError{Message: fmt.Sprintf("invalid double %q", rest)}
+		}
+		return f, nil
+	case '(':
+		return string(rest), nil
+	case '$', '!', '=':
+		n, err := length(rest, maxBulkLength)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b, err := r.bulk(n)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case '!':
+			return Error(b), nil
+		case '=':
+			// Verbatim strings start with
+			// the format like "txt:".
+			if len(b) >= 4 && b[3] == ':' {
+				b = b[4:]
+			}
+		}
+		return b, nil
+	case '*', '~', '>':
+		n, err := length(rest, maxElements)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, 0, prealloc(n))
+		for i := 0; i < n; i++ {
+			value, err := r.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case '%', '|':
+		n, err := length(rest, maxElements)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make(map[interface{}]interface{}, prealloc(n))
+		for i := 0; i < n; i++ {
+			key, err := r.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if b, ok := key.([]byte); ok {
+				key = string(b)
+			}
+			if values[key], err = r.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		if kind == '|' {
+			// Attributes precede the value
+			// they describe, only value is
+			// returned.
+			return r.value(depth)
+		}
+		return values, nil
+	default:
+		return nil, &ProtocolError{Message: fmt.Sprintf("unknown type %q", kind)}
+	}
+}
+
+// line reads line without the CRLF terminator.
+func (r *Reader) line() ([]byte, error) {
+	var line []byte
+	for {
+		b, err := r.r.ReadSlice('\n')
+		line = append(line, b...)
+		if len(line) > maxInlineLength {
+			return nil, &ProtocolError{Message: "too big inline request"}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		break
+	}
+
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// bulk reads n bytes followed by CRLF, the
+// buffer grows with the data read.
+func (r *Reader) bulk(n int) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(prealloc(n + 2))
+
+	if _, err := io.CopyN(&buf, r.r, int64(n+2)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	b := buf.Bytes()
+	if b[n] != '\r' || b[n+1] != '\n' {
+		return nil, &ProtocolError{Message: "expected CRLF after bulk string"}
+	}
+
+	return b[:n], nil
+}
+
+// prealloc returns space allocated for
+// n bytes or elements before reading.
+func prealloc(n int) int {
+	if n > maxPrealloc {
+		return maxPrealloc
+	}
+
+	return n
+}
+
+// length parses length of bulk strings or aggregates,
+// -1 stands for RESP2 nulls.
+func length(b []byte, max int) (int, error) {
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < -1 || n > max {
+		return 0, &ProtocolError{Message: fmt.Sprintf("invalid length %q", b)}
+	}
+
+	return n, nil
+}
+
+func parseInt(b []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, &ProtocolError{Message: fmt.Sprintf("invalid integer %q", b)}
+	}
+
+	return n, nil
+}
+
+// Writer writes replies encoded for the protocol
+// version, RESP3 only types are downgraded for RESP2.
+type Writer struct {
+	w     *bufio.Writer
+	proto int
+}
+
+// NewWriter returns RESP2 writer of w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w), proto: RESP2}
+}
+
+// SetProtocol switches protocol version of replies.
+func (w *Writer) SetProtocol(proto int) {
+	w.proto = proto
+}
+
+// Protocol returns protocol version of replies.
+func (w *Writer) Protocol() int {
+	return w.proto
+}
+
+// Flush writes buffered replies.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Simple writes simple string.
+func (w *Writer) Simple(s string) {
+	w.w.WriteByte('+')
+	w.w.WriteString(s)
+	w.w.WriteString("\r\n")
+}
+
+// Error writes simple error, message starts
+// with the error code like ERR or WRONGTYPE.
+func (w *Writer) Error(message string) {
+	w.w.WriteByte('-')
+	w.w.WriteString(message)
+	w.w.WriteString("\r\n")
+}
+
+// Int writes integer.
+func (w *Writer) Int(n int64) {
+	w.w.WriteByte(':')
+	w.w.WriteString(strconv.FormatInt(n, 10))
+	w.w.WriteString("\r\n")
+}
+
+// Bulk writes bulk string.
+func (w *Writer) Bulk(b []byte) {
+	w.w.WriteByte('$')
+	w.w.WriteString(strconv.Itoa(len(b)))
+	w.w.WriteString("\r\n")
+	w.w.Write(b)
+	w.w.WriteString("\r\n")
+}
+
+// Null writes null, RESP2 null bulk string.
+func (w *Writer) Null() {
+	if w.proto == RESP3 {
+		w.w.WriteString("_\r\n")
+		return
+	}
+
+	w.w.WriteString("$-1\r\n")
+}
+
+// Array writes header of array of n values.
+func (w *Writer) Array(n int) {
+	w.w.WriteByte('*')
+	w.w.WriteString(strconv.Itoa(n))
+	w.w.WriteString("\r\n")
+}
+
+// Map writes header of map of n key value pairs,
+// RESP2 array of 2n values.
+func (w *Writer) Map(n int) {
+	if w.proto == RESP3 {
+		w.w.WriteByte('%')
+		w.w.WriteString(strconv.Itoa(n))
+		w.w.WriteString("\r\n")
+		return
+	}
+
+	w.Array(2 * n)
+}
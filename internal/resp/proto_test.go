@@ -0,0 +1,162 @@
+package resp
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader_ReadValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		expect  interface{}
+		wantErr bool
+	}{
+		{name: "simple string", input: "+OK\r\n", expect: []byte("OK")},
+		{name: "error", input: "-ERR bad\r\n", expect: Error("ERR bad")},
+		{name: "integer", input: ":-12\r\n", expect: int64(-12)},
+		{name: "bulk string", input: "$5\r\nhe\r\no\r\n", expect: []byte("he\r\no")},
+		{name: "null bulk string", input: "$-1\r\n", expect: nil},
+		{name: "null", input: "_\r\n", expect: nil},
+		{name: "boolean", input: "#t\r\n", expect: true},
+		{name: "double", input: ",1.5\r\n", expect: 1.5},
+		{name: "big number", input: "(123456789012345678901234567890\r\n", expect: "123456789012345678901234567890"},
+		{name: "blob error", input: "!7\r\nERR bad\r\n", expect: Error("ERR bad")},
+		{name: "verbatim string", input: "=8\r\ntxt:text\r\n", expect: []byte("text")},
+		{
+			name:   "array",
+			input:  "*2\r\n$1\r\na\r\n:1\r\n",
+			expect: []interface{}{[]byte("a"), int64(1)},
+		},
+		{
+			name:   "set",
+			input:  "~1\r\n+a\r\n",
+			expect: []interface{}{[]byte("a")},
+		},
+		{
+			name:   "map",
+			input:  "%1\r\n+proto\r\n:3\r\n",
+			expect: map[interface{}]interface{}{"proto": int64(3)},
+		},
+		{
+			name:   "attribute",
+			input:  "|1\r\n+ttl\r\n:10\r\n+OK\r\n",
+			expect: []byte("OK"),
+		},
+		{name: "unknown type", input: "?\r\n", wantErr: true},
+		{name: "invalid length", input: "$x\r\n", wantErr: true},
+		{name: "too long bulk string", input: "$536870913\r\n", wantErr: true},
+		{name: "missing CRLF", input: "$1\r\nab\r\n", wantErr: true},
+		{name: "truncated", input: "*2\r\n:1\r\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewReader(strings.NewReader(tt.input)).ReadValue()
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestReader_ReadCommand(t *testing.T) {
+	r := NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n$1\r\nk\r\nPING  hello\r\n*1\r\n+x\r\n"))
+
+	args, err := r.ReadCommand()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("GET"), []byte("k")}, args)
+	assert.True(t, r.Buffered())
+
+	args, err = r.ReadCommand()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("PING"), []byte("hello")}, args)
+
+	_, err = r.ReadCommand()
+	assert.IsType(t, &ProtocolError{}, err)
+
+	r = NewReader(strings.NewReader("*1\r\n$1\r\nx\r\n"))
+	args, err = r.ReadCommand()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("x")}, args)
+
+	_, err = r.ReadCommand()
+	assert.Equal(t, io.EOF, err)
+
+	for _, input := range []string{
+		"*1\r\n*0\r\n",
+		"*1\r\n:1\r\n",
+		"*1\r\n$-1\r\n",
+		"*2\r\n$3\r\nGET\r\n*1048576\r\n",
+	} {
+		_, err = NewReader(strings.NewReader(input)).ReadCommand()
+		assert.IsType(t, &ProtocolError{}, err, input)
+	}
+
+	// Announced length is not allocated before the data arrives.
+	_, err = NewReader(strings.NewReader("*1\r\n$536870912\r\nabc")).ReadCommand()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestReader_ReadValue_Nested(t *testing.T) {
+	_, err := NewReader(strings.NewReader(strings.Repeat("*1\r\n", maxDepth+2) + ":1\r\n")).ReadValue()
+	assert.IsType(t, &ProtocolError{}, err)
+
+	v, err := NewReader(strings.NewReader(strings.Repeat("*1\r\n", maxDepth) + ":1\r\n")).ReadValue()
+	assert.Nil(t, err)
+	assert.NotNil(t, v)
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		proto  int
+		expect string
+	}{
+		{
+			name:   "resp2",
+			proto:  RESP2,
+			expect: "+OK\r\n-ERR bad\r\n:7\r\n$2\r\nab\r\n$-1\r\n*1\r\n*2\r\n",
+		},
+		{
+			name:   "resp3",
+			proto:  RESP3,
+			expect: "+OK\r\n-ERR bad\r\n:7\r\n$2\r\nab\r\n_\r\n*1\r\n%1\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var b bytes.Buffer
+			w := NewWriter(&b)
+			w.SetProtocol(tt.proto)
+
+			w.Simple("OK")
+			w.Error("ERR bad")
+			w.Int(7)
+			w.Bulk([]byte("ab"))
+			w.Null()
+			w.Array(1)
+			w.Map(1)
+			assert.Nil(t, w.Flush())
+
+			assert.Equal(t, tt.expect, b.String())
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package resp
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+// Server serves Redis clients over RESP2 and RESP3,
+// commands are applied to the shared storage.
+type Server struct {
+	// IdleTimeout limits wait for the next command
+	// and its read, connections are closed after
+	// it. Zero means no limit.
+	IdleTimeout time.Duration
+
+	storage     storage.Storage
+	keyLiveTime time.Duration
+	rules       validate.Rules
+	schemas     *schema.Registry
+	started     time.Time
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	lastID    int64
+	closed    bool
+}
+
+// NewServer returns server of the storage, keys set
+// without expiration live for keyLiveTime, keys and
+// values are validated with the rules and schemas.
+func NewServer(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules, schemas *schema.Registry) *Server {
+	return &Server{
+		storage:     storage,
+		keyLiveTime: keyLiveTime,
+		rules:       rules,
+		schemas:     schemas,
+		started:     time.Now(),
+		listeners:   make(map[net.Listener]struct{}),
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP
+// address and serves its connections.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections of the listener until
+// server is closed, then it returns nil.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			delete(s.listeners, l)
+			s.mu.Unlock()
+
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		id, ok := s.track(c)
+		if !ok {
+			c.Close()
+			return nil
+		}
+
+		go s.serveConn(c, id)
+	}
+}
+
+// Close closes listeners and connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	var err error
+	for l := range s.listeners {
+		if e := l.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	for c := range s.conns {
+		c.Close()
+	}
+
+	return err
+}
+
+// track registers connection and
+// returns its id, unless server is closed.
+func (s *Server) track(c net.Conn) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, false
+	}
+
+	s.lastID++
+	s.conns[c] = struct{}{}
+
+	return s.lastID, true
+}
+
+// clients returns number of connected clients.
+func (s *Server) clients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+// serveConn executes commands of the connection in
+// order, replies of pipelined commands are flushed
+// when no more input is buffered.
+func (s *Server) serveConn(c net.Conn, id int64) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	r := NewReader(c)
+	w := NewWriter(c)
+	sess := session{id: id, w: w}
+
+	for {
+		if s.IdleTimeout > 0 {
+			c.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+
+		args, err := r.ReadCommand()
+		if err != nil {
+			if perr, ok := err.(*ProtocolError); ok {
+				w.Error("ERR " + perr.Error())
+				w.Flush()
+			} else if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("resp: read from %s: %v", c.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if len(args) > 0 {
+			s.execute(&sess, args)
+		}
+
+		if r.Buffered() && !sess.quit {
+			continue
+		}
+
+		if err := w.Flush(); err != nil || sess.quit {
+			return
+		}
+	}
+}
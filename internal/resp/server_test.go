@@ -0,0 +1,233 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+// client sends commands to the server and reads replies.
+type client struct {
+	conn net.Conn
+	r    *Reader
+	w    *bufio.Writer
+}
+
+func newClient(t *testing.T, s *Server) *client {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go s.Serve(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return &client{conn: conn, r: NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+// send writes commands without waiting for replies.
+func (c *client) send(commands ...[]string) {
+	for _, args := range commands {
+		fmt.Fprintf(c.w, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	c.w.Flush()
+}
+
+func (c *client) do(t *testing.T, args ...string) interface{} {
+	c.send(args)
+
+	reply, err := c.r.ReadValue()
+	if err != nil {
+		t.Fatalf("read reply of %v: %v", args, err)
+	}
+
+	return reply
+}
+
+func newServer(rules validate.Rules) (*Server, storage.Storage) {
+	st := storage.New()
+	schemas := schema.NewRegistry()
+	schemas.Register("user:", map[string]interface{}{"type": "object"})
+
+	return NewServer(st, time.Minute, rules, schemas), st
+}
+
+func TestServer_commands(t *testing.T) {
+	s, _ := newServer(validate.Rules{MaxKeyLength: 8})
+	defer s.Close()
+	c := newClient(t, s)
+
+	tests := []struct {
+		args   []string
+		expect interface{}
+	}{
+		{args: []string{"PING"}, expect: []byte("PONG")},
+		{args: []string{"ping", "hi"}, expect: []byte("hi")},
+		{args: []string{"GET", "k"}, expect: nil},
+		{args: []string{"SET", "k", "v"}, expect: []byte("OK")},
+		{args: []string{"GET", "k"}, expect: []byte("v")},
+		{args: []string{"GET", "k"}, expect: []byte("v")},
+		{args: []string{"TTL", "k"}, expect: int64(60)},
+		{args: []string{"SET", "k", "x", "NX"}, expect: nil},
+		{args: []string{"SET", "k", "x", "XX", "EX", "10"}, expect: []byte("OK")},
+		{args: []string{"TTL", "k"}, expect: int64(10)},
+		{args: []string{"SET", "n", "x", "XX"}, expect: nil},
+		{args: []string{"SET", "n", "y", "NX", "PX", "100000"}, expect: []byte("OK")},
+		{args: []string{"EXISTS", "k", "n", "k", "missing"}, expect: int64(3)},
+		{args: []string{"EXPIRE", "n", "20"}, expect: int64(1)},
+		{args: []string{"TTL", "n"}, expect: int64(20)},
+		{args: []string{"EXPIRE", "missing", "20"}, expect: int64(0)},
+		{args: []string{"TTL", "missing"}, expect: int64(-2)},
+		{args: []string{"GETDEL", "n"}, expect: []byte("y")},
+		{args: []string{"GETDEL", "n"}, expect: nil},
+		{args: []string{"DEL", "k", "n"}, expect: int64(1)},
+		{args: []string{"SET", "k", "v"}, expect: []byte("OK")},
+		{args: []string{"EXPIRE", "k", "0"}, expect: int64(1)},
+		{args: []string{"EXISTS", "k"}, expect: int64(0)},
+		{args: []string{"SET", "k", "v", "EX", "0"}, expect: Error("ERR invalid expire time in 'set' command")},
+		{args: []string{"SET", "k", "v", "EX", "a"}, expect: Error("ERR value is not an integer or out of range")},
+		{args: []string{"SET", "k", "v", "NX", "XX"}, expect: Error("ERR syntax error")},
+		{args: []string{"SET", "long key!", "v"}, expect: Error("ERR invalid key: the length must be no more than 8")},
+		{args: []string{"SET", "user:1", "v"}, expect: Error("ERR invalid value: Invalid type. Expected: object, given: string")},
+		{args: []string{"GET"}, expect: Error("ERR wrong number of arguments for 'get' command")},
+		{args: []string{"NOPE"}, expect: Error("ERR unknown command 'NOPE'")},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, c.do(t, tt.args...), strings.Join(tt.args, " "))
+	}
+}
+
+func TestServer_sharedStorage(t *testing.T) {
+	s, st := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	st.Set(storage.Expire(time.Minute), "doc", map[string]interface{}{"a": 1.0})
+	st.SAdd(storage.Expire(time.Minute), "set", "a")
+
+	assert.Equal(t, []byte(`{"a":1}`), c.do(t, "GET", "doc"))
+	assert.Equal(t, Error("WRONGTYPE Operation against a key holding the wrong kind of value"), c.do(t, "GET", "set"))
+
+	assert.Equal(t, []byte("OK"), c.do(t, "SET", "bin", "\xff\x00"))
+	value, err := st.View("bin", false, func(v interface{}) (interface{}, error) { return v, nil })
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Blob{ContentType: "application/octet-stream", Data: []byte("\xff\x00")}, value)
+	assert.Equal(t, []byte("\xff\x00"), c.do(t, "GET", "bin"))
+
+	info, ok := c.do(t, "INFO", "keyspace").([]byte)
+	assert.True(t, ok)
+	assert.Equal(t, "# Keyspace\r\ndb0:keys=3", string(info))
+}
+
+func TestServer_pipelining(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	var commands [][]string
+	for i := 0; i < 100; i++ {
+		commands = append(commands, []string{"SET", fmt.Sprintf("k%d", i), fmt.Sprint(i)})
+		commands = append(commands, []string{"GET", fmt.Sprintf("k%d", i)})
+	}
+	c.send(commands...)
+
+	for i := 0; i < 100; i++ {
+		reply, err := c.r.ReadValue()
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("OK"), reply)
+
+		reply, err = c.r.ReadValue()
+		assert.Nil(t, err)
+		assert.Equal(t, []byte(fmt.Sprint(i)), reply)
+	}
+}
+
+func TestServer_hello(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	assert.Equal(t, Error("NOPROTO unsupported protocol version"), c.do(t, "HELLO", "4"))
+
+	reply, ok := c.do(t, "HELLO", "3").(map[interface{}]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), reply["proto"])
+	assert.Equal(t, []byte("integral_db"), reply["server"])
+
+	_, err := fmt.Fprint(c.conn, "GET missing\r\n")
+	assert.Nil(t, err)
+	line, err := bufio.NewReader(c.conn).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "_\r\n", line)
+}
+
+func TestServer_protocolError(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+	defer s.Close()
+	c := newClient(t, s)
+
+	fmt.Fprint(c.conn, "*1\r\n$x\r\n")
+
+	reply, err := c.r.ReadValue()
+	assert.Nil(t, err)
+	assert.Equal(t, Error(`ERR Protocol error: invalid length "x"`), reply)
+
+	_, err = c.r.ReadValue()
+	assert.Error(t, err)
+}
+
+func TestServer_Close(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	c := &client{conn: conn, r: NewReader(conn), w: bufio.NewWriter(conn)}
+	assert.Equal(t, []byte("PONG"), c.do(t, "PING"))
+
+	assert.Nil(t, s.Close())
+	assert.Nil(t, <-done)
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, err = c.r.ReadValue()
+	assert.Error(t, err)
+}
+
+func TestServer_idleTimeout(t *testing.T) {
+	s, _ := newServer(validate.Rules{})
+	s.IdleTimeout = 50 * time.Millisecond
+	defer s.Close()
+	c := newClient(t, s)
+
+	assert.Equal(t, []byte("PONG"), c.do(t, "PING"))
+
+	// Command which doesn't arrive in time closes the connection.
+	fmt.Fprint(c.conn, "*1\r\n$4\r\nPI")
+
+	_, err := c.r.ReadValue()
+	assert.Equal(t, io.EOF, err)
+}
@@ -1,39 +1,196 @@
 package responses
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/codec"
 )
 
 const (
+	problemContentType     = "application/problem+json"
+	octetStreamContentType = "application/octet-stream"
+
+	// problemTypePrefix prefixes codes
+	// in the problem type URI references.
+	problemTypePrefix = "/problems/"
+
 	internalServerErrorMessage = "internal server error"
 )
 
-// OK response.
-func OK(w http.ResponseWriter, resp interface{}) {
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		InternalServerError(w)
+// Code is a stable machine readable error code.
+type Code string
+
+// Error codes reported to clients.
+const (
+	CodeValidationFailed     Code = "validation_failed"
+	CodeMalformedBody        Code = "malformed_body"
+	CodeBodyTooLarge         Code = "body_too_large"
+	CodeUnauthorized         Code = "unauthorized"
+	CodeForbidden            Code = "forbidden"
+	CodeKeyNotFound          Code = "key_not_found"
+	CodePathNotFound         Code = "path_not_found"
+	CodeSchemaNotFound       Code = "schema_not_found"
+	CodeNamespaceNotFound    Code = "namespace_not_found"
+	CodeWrongType            Code = "wrong_type"
+	CodeNotInteger           Code = "not_integer"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodePatchFailed          Code = "patch_failed"
+	CodeKeysQuotaExceeded    Code = "keys_quota_exceeded"
+	CodeBytesQuotaExceeded   Code = "bytes_quota_exceeded"
+	CodeTTLQuotaExceeded     Code = "ttl_quota_exceeded"
+	CodeRateLimited          Code = "rate_limited"
+	CodeLockHeld             Code = "lock_held"
+	CodeLockNotHeld          Code = "lock_not_held"
+	CodeNoPermits            Code = "no_permits"
+	CodePermitNotHeld        Code = "permit_not_held"
+	CodeRequestInFlight      Code = "request_in_flight"
+	CodeRequestMismatch      Code = "request_mismatch"
+	CodeRequestNotStarted    Code = "request_not_started"
+	CodeInternal             Code = "internal_error"
+)
+
+// problemKind describes problems of the code.
+type problemKind struct {
+	status int
+	title  string
+}
+
+var kinds = map[Code]problemKind{
+	CodeValidationFailed:     {http.StatusBadRequest, "Request validation failed"},
+	CodeMalformedBody:        {http.StatusBadRequest, "Malformed request body"},
+	CodeBodyTooLarge:         {http.StatusRequestEntityTooLarge, "Request body too large"},
+	CodeUnauthorized:         {http.StatusUnauthorized, "Authentication required"},
+	CodeForbidden:            {http.StatusForbidden, "Access denied"},
+	CodeKeyNotFound:          {http.StatusNotFound, "Key not found"},
+	CodePathNotFound:         {http.StatusNotFound, "Path not found"},
+	CodeSchemaNotFound:       {http.StatusNotFound, "Schema not found"},
+	CodeNamespaceNotFound:    {http.StatusNotFound, "Namespace not found"},
+	CodeWrongType:            {http.StatusConflict, "Wrong kind of value"},
+	CodeNotInteger:           {http.StatusConflict, "Value is not an integer"},
+	CodeUnsupportedMediaType: {http.StatusUnsupportedMediaType, "Unsupported media type"},
+	CodePatchFailed:          {http.StatusUnprocessableEntity, "Patch can't be applied"},
+	CodeKeysQuotaExceeded:    {http.StatusTooManyRequests, "Keys quota exceeded"},
+	CodeBytesQuotaExceeded:   {http.StatusInsufficientStorage, "Bytes quota exceeded"},
+	CodeTTLQuotaExceeded:     {http.StatusTooManyRequests, "Key lifetime quota exceeded"},
+	CodeRateLimited:          {http.StatusTooManyRequests, "Rate limit exceeded"},
+	CodeLockHeld:             {http.StatusConflict, "Lock is held"},
+	CodeLockNotHeld:          {http.StatusConflict, "Lock is not held"},
+	CodeNoPermits:            {http.StatusConflict, "No permits available"},
+	CodePermitNotHeld:        {http.StatusConflict, "Permit is not held"},
+	CodeRequestInFlight:      {http.StatusConflict, "Request is in progress"},
+	CodeRequestMismatch:      {http.StatusUnprocessableEntity, "Idempotency key reused"},
+	CodeRequestNotStarted:    {http.StatusConflict, "Request is not in progress"},
+	CodeInternal:             {http.StatusInternalServerError, "Internal server error"},
+}
+
+// Coder is implemented by errors reported to
+// clients, the code selects the problem kind.
+type Coder interface {
+	Code() Code
+}
+
+// Invalid is implemented by errors which
+// describe invalid fields of the request.
+type Invalid interface {
+	InvalidFields() interface{}
+}
+
+// Problem is a RFC 7807 problem details
+// body extended with the error code.
+type Problem struct {
+	Type   string      `json:"type"`
+	Title  string      `json:"title"`
+	Status int         `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+	Code   Code        `json:"code"`
+	Errors interface{} `json:"errors,omitempty"`
+}
+
+// NewProblem returns problem of the code
+// with error message as the detail.
+func NewProblem(code Code, detail string) Problem {
+	kind, ok := kinds[code]
+	if !ok {
+		code, kind = CodeInternal, kinds[CodeInternal]
+	}
+
+	return Problem{
+		Type:   problemTypePrefix + string(code),
+		Title:  kind.title,
+		Status: kind.status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// OK responds with resp encoded by the codec
+// negotiated from Accept header of the request.
+func OK(w http.ResponseWriter, r *http.Request, resp interface{}) {
+	c := codec.Default.ForAccept(r.Header.Get("Accept"))
+
+	b, err := c.Marshal(resp)
+	if err != nil {
+		InternalServerError(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", c.MediaType())
+	w.Write(b)
+}
+
+// Raw responds with data as is, content
+// type defaults to application/octet-stream.
+func Raw(w http.ResponseWriter, contentType string, data []byte) {
+	if contentType == "" {
+		contentType = octetStreamContentType
 	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
 }
 
-// BadRequest response.
-func BadRequest(w http.ResponseWriter, resp interface{}) {
-	w.WriteHeader(http.StatusBadRequest)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		InternalServerError(w)
+// Error responds with problem details of the error cause,
+// errors without known code are internal server errors.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	cause := errors.Cause(err)
+
+	coder, ok := cause.(Coder)
+	if !ok {
+		InternalServerError(w, r)
+		return
 	}
+
+	p := NewProblem(coder.Code(), cause.Error())
+	if invalid, ok := cause.(Invalid); ok {
+		p.Errors = invalid.InvalidFields()
+	}
+
+	WriteProblem(w, r, p)
 }
 
-// NotFound response.
-func NotFound(w http.ResponseWriter, resp interface{}) {
-	w.WriteHeader(http.StatusNotFound)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		InternalServerError(w)
+// WriteProblem responds with the problem details encoded by
+// the negotiated codec, JSON ones are problem+json documents.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	c := codec.Default.ForAccept(r.Header.Get("Accept"))
+
+	b, err := c.Marshal(p)
+	if err != nil {
+		c = codec.JSON
+		b, _ = c.Marshal(p)
+	}
+
+	contentType := c.MediaType()
+	if c == codec.JSON {
+		contentType = problemContentType
 	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(p.Status)
+	w.Write(b)
 }
 
 // InternalServerError response.
-func InternalServerError(w http.ResponseWriter) {
-	http.Error(w, fmt.Sprintf("{\"message\": \"%s\"}", internalServerErrorMessage), http.StatusInternalServerError)
+func InternalServerError(w http.ResponseWriter, r *http.Request) {
+	WriteProblem(w, r, NewProblem(CodeInternal, internalServerErrorMessage))
 }
@@ -0,0 +1,202 @@
+package responses
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type codedError struct {
+	code   Code
+	fields interface{}
+}
+
+func (e codedError) Error() string {
+	return "mock error"
+}
+
+func (e codedError) Code() Code {
+	return e.code
+}
+
+type invalidError struct {
+	codedError
+}
+
+func (e invalidError) InvalidFields() interface{} {
+	return e.fields
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect Problem
+	}{
+		{
+			name: "coded",
+			err:  errors.Wrap(codedError{code: CodeKeyNotFound}, "get failed"),
+			expect: Problem{
+				Type:   "/problems/key_not_found",
+				Title:  "Key not found",
+				Status: http.StatusNotFound,
+				Detail: "mock error",
+				Code:   CodeKeyNotFound,
+			},
+		},
+		{
+			name: "invalid",
+			err:  invalidError{codedError{code: CodeValidationFailed, fields: []interface{}{"key"}}},
+			expect: Problem{
+				Type:   "/problems/validation_failed",
+				Title:  "Request validation failed",
+				Status: http.StatusBadRequest,
+				Detail: "mock error",
+				Code:   CodeValidationFailed,
+				Errors: []interface{}{"key"},
+			},
+		},
+		{
+			name: "unknown code",
+			err:  codedError{code: "unknown"},
+			expect: Problem{
+				Type:   "/problems/internal_error",
+				Title:  "Internal server error",
+				Status: http.StatusInternalServerError,
+				Detail: "mock error",
+				Code:   CodeInternal,
+			},
+		},
+		{
+			name: "unexpected",
+			err:  errors.New("mock error"),
+			expect: Problem{
+				Type:   "/problems/internal_error",
+				Title:  "Internal server error",
+				Status: http.StatusInternalServerError,
+				Detail: "internal server error",
+				Code:   CodeInternal,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "http://any-host/get", nil)
+			res := httptest.NewRecorder()
+			Error(res, req, tt.err)
+
+			assert.Equal(t, tt.expect.Status, res.Code)
+			assert.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+
+			var got Problem
+			assert.Nil(t, json.NewDecoder(res.Body).Decode(&got))
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestError_negotiated(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://any-host/get", nil)
+	req.Header.Set("Accept", "application/msgpack")
+	res := httptest.NewRecorder()
+
+	Error(res, req, codedError{code: CodeKeyNotFound})
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Equal(t, "application/msgpack", res.Header().Get("Content-Type"))
+
+	var got Problem
+	assert.Nil(t, codec.MessagePack.Unmarshal(res.Body.Bytes(), &got))
+	assert.Equal(t, CodeKeyNotFound, got.Code)
+}
+
+func TestOK(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		codec       codec.Codec
+	}{
+		{
+			name:        "default",
+			contentType: "application/json",
+			codec:       codec.JSON,
+		},
+		{
+			name:        "msgpack",
+			accept:      "application/json;q=0.5, application/msgpack",
+			contentType: "application/msgpack",
+			codec:       codec.MessagePack,
+		},
+		{
+			name:        "cbor",
+			accept:      "application/cbor",
+			contentType: "application/cbor",
+			codec:       codec.CBOR,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("GET", "http://any-host/get", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			res := httptest.NewRecorder()
+
+			OK(res, req, map[string]string{"message": "ok"})
+
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, tt.contentType, res.Header().Get("Content-Type"))
+
+			var got map[string]string
+			assert.Nil(t, tt.codec.Unmarshal(res.Body.Bytes(), &got))
+			assert.Equal(t, map[string]string{"message": "ok"}, got)
+		})
+	}
+}
+
+func TestRaw(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		expect      string
+	}{
+		{
+			name:        "content type",
+			contentType: "image/png",
+			expect:      "image/png",
+		},
+		{
+			name:   "default",
+			expect: "application/octet-stream",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := httptest.NewRecorder()
+
+			Raw(res, tt.contentType, []byte{0, 1, 2})
+
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, tt.expect, res.Header().Get("Content-Type"))
+			assert.Equal(t, []byte{0, 1, 2}, res.Body.Bytes())
+		})
+	}
+}
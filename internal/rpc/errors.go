@@ -0,0 +1,199 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/rpc/integralpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// errorDomain is domain of the
+	// ErrorInfo status details.
+	errorDomain = "integral_db"
+
+	internalErrorMessage           = "internal server error"
+	validationErrorResponseMessage = "you have validation errors"
+	operationRequiredMessage       = "cannot be blank"
+	keysRequiredMessage            = "cannot be blank"
+)
+
+// grpcCodes maps response codes to gRPC status codes.
+var grpcCodes = map[responses.Code]codes.Code{
+	responses.CodeValidationFailed:     codes.InvalidArgument,
+	responses.CodeMalformedBody:        codes.InvalidArgument,
+	responses.CodeBodyTooLarge:         codes.ResourceExhausted,
+	responses.CodeUnauthorized:         codes.Unauthenticated,
+	responses.CodeForbidden:            codes.PermissionDenied,
+	responses.CodeKeyNotFound:          codes.NotFound,
+	responses.CodePathNotFound:         codes.NotFound,
+	responses.CodeSchemaNotFound:       codes.NotFound,
+	responses.CodeNamespaceNotFound:    codes.NotFound,
+	responses.CodeWrongType:            codes.FailedPrecondition,
+	responses.CodeNotInteger:           codes.FailedPrecondition,
+	responses.CodeUnsupportedMediaType: codes.InvalidArgument,
+	responses.CodePatchFailed:          codes.FailedPrecondition,
+	responses.CodeKeysQuotaExceeded:    codes.ResourceExhausted,
+	responses.CodeBytesQuotaExceeded:   codes.ResourceExhausted,
+	responses.CodeTTLQuotaExceeded:     codes.ResourceExhausted,
+	responses.CodeRateLimited:          codes.ResourceExhausted,
+	responses.CodeLockHeld:             codes.FailedPrecondition,
+	responses.CodeLockNotHeld:          codes.FailedPrecondition,
+	responses.CodeNoPermits:            codes.FailedPrecondition,
+	responses.CodePermitNotHeld:        codes.FailedPrecondition,
+	responses.CodeRequestInFlight:      codes.FailedPrecondition,
+	responses.CodeRequestMismatch:      codes.FailedPrecondition,
+	responses.CodeRequestNotStarted:    codes.FailedPrecondition,
+	responses.CodeInternal:             codes.Internal,
+}
+
+// statusError converts error of the services to the status
+// error, its details hold the response code and invalid
+// fields. Errors without known code are internal errors.
+func statusError(err error) error {
+	cause := errors.Cause(err)
+
+	coder, ok := cause.(responses.Coder)
+	if !ok {
+		return status.Error(codes.Internal, internalErrorMessage)
+	}
+
+	code, ok := grpcCodes[coder.Code()]
+	if !ok {
+		return status.Error(codes.Internal, internalErrorMessage)
+	}
+
+	st := status.New(code, cause.Error())
+
+	info := errdetails.ErrorInfo{
+		Reason: string(coder.Code()),
+		Domain: errorDomain,
+	}
+
+	var badRequest errdetails.BadRequest
+	if invalid, ok := cause.(responses.Invalid); ok {
+		for _, field := range invalidFields(invalid) {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+	}
+
+	detailed, err := st.WithDetails(&info)
+	if err == nil && len(badRequest.FieldViolations) > 0 {
+		detailed, err = detailed.WithDetails(&badRequest)
+	}
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// resultError converts error of the
+// batch operation to the result error.
+func resultError(err error) *integralpb.Error {
+	cause := errors.Cause(err)
+
+	coder, ok := cause.(responses.Coder)
+	if !ok {
+		return &integralpb.Error{
+			Code:    string(responses.CodeInternal),
+			Message: internalErrorMessage,
+		}
+	}
+
+	message := cause.Error()
+	if invalid, ok := cause.(responses.Invalid); ok {
+		for _, field := range invalidFields(invalid) {
+			message += fmt.Sprintf("; %s: %s", field.Field, field.Message)
+		}
+	}
+
+	return &integralpb.Error{
+		Code:    string(coder.Code()),
+		Message: message,
+	}
+}
+
+// invalidFields returns fields described by
+// the error, services encode them with field
+// and message JSON keys.
+func invalidFields(invalid responses.Invalid) []validationError {
+	b, err := json.Marshal(invalid.InvalidFields())
+	if err != nil {
+		return nil
+	}
+
+	var fields []validationError
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil
+	}
+
+	return fields
+}
+
+// validateKeys validates keys with the rules, field
+// names of multiple keys are suffixed with indexes.
+func (s *Server) validateKeys(field string, keys ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	for i, key := range keys {
+		if err := s.rules.Key(key); err != nil {
+			name := field
+			if len(keys) > 1 || field == "keys" {
+				name = fmt.Sprintf("%s.%d", field, i)
+			}
+
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: name, Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+func invalidArgument(field, message string) error {
+	return validationErrorResponse{
+		Message: validationErrorResponseMessage,
+		Errors: []validationError{
+			validationError{Field: field, Message: message},
+		},
+	}
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// codeError is error of the code.
+type codeError responses.Code
+
+func (e codeError) Error() string {
+	return string(e)
+}
+
+func (e codeError) Code() responses.Code {
+	return responses.Code(e)
+}
+
+func Test_statusError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect codes.Code
+	}{
+		{name: "quota", err: codeError(responses.CodeBytesQuotaExceeded), expect: codes.ResourceExhausted},
+		{name: "rate limited", err: codeError(responses.CodeRateLimited), expect: codes.ResourceExhausted},
+		{name: "forbidden", err: codeError(responses.CodeForbidden), expect: codes.PermissionDenied},
+		{name: "lock held", err: codeError(responses.CodeLockHeld), expect: codes.FailedPrecondition},
+		{name: "no permits", err: codeError(responses.CodeNoPermits), expect: codes.FailedPrecondition},
+		{name: "request in flight", err: codeError(responses.CodeRequestInFlight), expect: codes.FailedPrecondition},
+		{name: "namespace not found", err: codeError(responses.CodeNamespaceNotFound), expect: codes.NotFound},
+		{name: "wrapped", err: errors.Wrap(codeError(responses.CodeKeyNotFound), "get failed"), expect: codes.NotFound},
+		{name: "unknown code", err: codeError("unknown"), expect: codes.Internal},
+		{name: "without code", err: errors.New("mock error"), expect: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, status.Code(statusError(tt.err)))
+		})
+	}
+}
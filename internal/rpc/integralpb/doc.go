@@ -0,0 +1,5 @@
+// Package integralpb holds protobuf messages and
+// the gRPC service of the API.
+package integralpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative integral.proto
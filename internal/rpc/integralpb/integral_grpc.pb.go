@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: integral.proto
+
+package integralpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// IntegralClient is the client API for Integral service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IntegralClient interface {
+	// Set sets value of the key, the key lives for the
+	// configured key live time.
+	Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error)
+	// Get returns value of the key or its fragment selected
+	// by path, the key is consumed unless peek is set.
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	// Delete removes keys of any kind.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Watch streams changes of the key until the client
+	// cancels the call.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Integral_WatchClient, error)
+	// Batch applies operations in order, each one
+	// succeeds or fails independently.
+	Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+}
+
+type integralClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIntegralClient(cc grpc.ClientConnInterface) IntegralClient {
+	return &integralClient{cc}
+}
+
+func (c *integralClient) Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error) {
+	out := new(SetResponse)
+	err := c.cc.Invoke(ctx, "/integral.v1.Integral/Set", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *integralClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/integral.v1.Integral/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *integralClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, "/integral.v1.Integral/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *integralClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Integral_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Integral_ServiceDesc.Streams[0], "/integral.v1.Integral/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &integralWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Integral_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type integralWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *integralWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *integralClient) Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/integral.v1.Integral/Batch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IntegralServer is the server API for Integral service.
+// All implementations must embed UnimplementedIntegralServer
+// for forward compatibility
+type IntegralServer interface {
+	// Set sets value of the key, the key lives for the
+	// configured key live time.
+	Set(context.Context, *SetRequest) (*SetResponse, error)
+	// Get returns value of the key or its fragment selected
+	// by path, the key is consumed unless peek is set.
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	// Delete removes keys of any kind.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Watch streams changes of the key until the client
+	// cancels the call.
+	Watch(*WatchRequest, Integral_WatchServer) error
+	// Batch applies operations in order, each one
+	// succeeds or fails independently.
+	Batch(context.Context, *BatchRequest) (*BatchResponse, error)
+	mustEmbedUnimplementedIntegralServer()
+}
+
+// UnimplementedIntegralServer must be embedded to have forward compatible implementations.
+type UnimplementedIntegralServer struct {
+}
+
+func (UnimplementedIntegralServer) Set(context.Context, *SetRequest) (*SetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Set not implemented")
+}
+func (UnimplementedIntegralServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedIntegralServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedIntegralServer) Watch(*WatchRequest, Integral_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedIntegralServer) Batch(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedIntegralServer) mustEmbedUnimplementedIntegralServer() {}
+
+// UnsafeIntegralServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IntegralServer will
+// result in compilation errors.
+type UnsafeIntegralServer interface {
+	mustEmbedUnimplementedIntegralServer()
+}
+
+func RegisterIntegralServer(s grpc.ServiceRegistrar, srv IntegralServer) {
+	s.RegisterService(&Integral_ServiceDesc, srv)
+}
+
+func _Integral_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntegralServer).Set(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/integral.v1.Integral/Set",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntegralServer).Set(ctx, req.(*SetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Integral_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntegralServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/integral.v1.Integral/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntegralServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Integral_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntegralServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/integral.v1.Integral/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntegralServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Integral_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IntegralServer).Watch(m, &integralWatchServer{stream})
+}
+
+type Integral_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type integralWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *integralWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Integral_Batch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntegralServer).Batch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/integral.v1.Integral/Batch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntegralServer).Batch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Integral_ServiceDesc is the grpc.ServiceDesc for Integral service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Integral_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "integral.v1.Integral",
+	HandlerType: (*IntegralServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Set",
+			Handler:    _Integral_Set_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _Integral_Get_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Integral_Delete_Handler,
+		},
+		{
+			MethodName: "Batch",
+			Handler:    _Integral_Batch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Integral_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "integral.proto",
+}
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/get"
+	"github.com/romanyx/integral_db/internal/rpc/integralpb"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/set"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const watcherLaggedMessage = "watcher fell behind, changes were lost"
+
+// Server implements the Integral gRPC service with
+// the set and get services and the storage shared
+// with the HTTP API.
+type Server struct {
+	integralpb.UnimplementedIntegralServer
+
+	setter  set.Setter
+	getter  get.Getter
+	storage storage.Storage
+	rules   validate.Rules
+}
+
+// NewServer returns initialized server, keys and values
+// are validated with the rules and values against schemas
+// of key prefixes.
+func NewServer(s storage.Storage, keyLiveTime time.Duration, rules validate.Rules, schemas *schema.Registry) *Server {
+	return &Server{
+		setter:  set.NewService(s, keyLiveTime, rules, schemas),
+		getter:  get.NewService(s, rules),
+		storage: s,
+		rules:   rules,
+	}
+}
+
+// Set implements the integralpb.IntegralServer interface.
+func (s *Server) Set(ctx context.Context, req *integralpb.SetRequest) (*integralpb.SetResponse, error) {
+	resp, err := s.set(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return resp, nil
+}
+
+// Get implements the integralpb.IntegralServer interface.
+func (s *Server) Get(ctx context.Context, req *integralpb.GetRequest) (*integralpb.GetResponse, error) {
+	resp, err := s.get(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return resp, nil
+}
+
+// Delete implements the integralpb.IntegralServer interface.
+func (s *Server) Delete(ctx context.Context, req *integralpb.DeleteRequest) (*integralpb.DeleteResponse, error) {
+	resp, err := s.delete(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return resp, nil
+}
+
+// Watch implements the integralpb.IntegralServer interface.
+func (s *Server) Watch(req *integralpb.WatchRequest, stream integralpb.Integral_WatchServer) error {
+	if err := s.validateKeys("key", req.GetKey()); err != nil {
+		return statusError(err)
+	}
+
+	ctx := stream.Context()
+	for e := range s.storage.Watch(ctx, req.GetKey()) {
+		event := integralpb.WatchEvent{
+			Key:  req.GetKey(),
+			Type: integralpb.WatchEvent_TYPE_DELETE,
+		}
+
+		if e.Op == storage.OpSet {
+			value, err := fromValue(e.Value)
+			if err != nil {
+				return statusError(err)
+			}
+			event.Type, event.Value = integralpb.WatchEvent_TYPE_SET, value
+		}
+
+		if err := stream.Send(&event); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	return status.Error(codes.ResourceExhausted, watcherLaggedMessage)
+}
+
+// Batch implements the integralpb.IntegralServer interface.
+func (s *Server) Batch(ctx context.Context, req *integralpb.BatchRequest) (*integralpb.BatchResponse, error) {
+	resp := integralpb.BatchResponse{
+		Results: make([]*integralpb.Result, len(req.GetOperations())),
+	}
+
+	for i, op := range req.GetOperations() {
+		resp.Results[i] = s.apply(op)
+	}
+
+	return &resp, nil
+}
+
+// apply returns result of the batch operation.
+func (s *Server) apply(op *integralpb.Operation) *integralpb.Result {
+	var (
+		result integralpb.Result
+		err    error
+	)
+
+	switch op := op.GetOp().(type) {
+	case *integralpb.Operation_Set:
+		var resp *integralpb.SetResponse
+		if resp, err = s.set(op.Set); err == nil {
+			result.Result = &integralpb.Result_Set{Set: resp}
+		}
+	case *integralpb.Operation_Get:
+		var resp *integralpb.GetResponse
+		if resp, err = s.get(op.Get); err == nil {
+			result.Result = &integralpb.Result_Get{Get: resp}
+		}
+	case *integralpb.Operation_Delete:
+		var resp *integralpb.DeleteResponse
+		if resp, err = s.delete(op.Delete); err == nil {
+			result.Result = &integralpb.Result_Delete{Delete: resp}
+		}
+	default:
+		err = invalidArgument("operation", operationRequiredMessage)
+	}
+
+	if err != nil {
+		result.Result = &integralpb.Result_Error{Error: resultError(err)}
+	}
+
+	return &result
+}
+
+func (s *Server) set(req *integralpb.SetRequest) (*integralpb.SetResponse, error) {
+	value, err := toValue(req.GetValue())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setter.SetKey(req.GetKey(), value); err != nil {
+		return nil, err
+	}
+
+	return &integralpb.SetResponse{}, nil
+}
+
+func (s *Server) get(req *integralpb.GetRequest) (*integralpb.GetResponse, error) {
+	value, err := s.getter.GetKey(req.GetKey(), req.GetPath(), req.GetPeek())
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := fromValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &integralpb.GetResponse{Value: v}, nil
+}
+
+func (s *Server) delete(req *integralpb.DeleteRequest) (*integralpb.DeleteResponse, error) {
+	if len(req.GetKeys()) == 0 {
+		return nil, invalidArgument("keys", keysRequiredMessage)
+	}
+
+	if err := s.validateKeys("keys", req.GetKeys()...); err != nil {
+		return nil, err
+	}
+
+	keys := make([]interface{}, len(req.GetKeys()))
+	for i, key := range req.GetKeys() {
+		keys[i] = key
+	}
+
+	return &integralpb.DeleteResponse{Deleted: int64(s.storage.Del(keys...))}, nil
+}
@@ -0,0 +1,192 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/rpc/integralpb"
+	"github.com/romanyx/integral_db/internal/schema"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newClient(t *testing.T, s storage.Storage) integralpb.IntegralClient {
+	l := bufconn.Listen(1 << 20)
+
+	schemas := schema.NewRegistry()
+	schemas.Register("user:", map[string]interface{}{"type": "object"})
+
+	srv := grpc.NewServer()
+	integralpb.RegisterIntegralServer(srv, NewServer(s, time.Minute, validate.Rules{MaxKeyLength: 8}, schemas))
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return l.Dial() }),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return integralpb.NewIntegralClient(conn)
+}
+
+func jsonValue(t *testing.T, v interface{}) *integralpb.Value {
+	value, err := structpb.NewValue(v)
+	if err != nil {
+		t.Fatalf("new value: %v", err)
+	}
+
+	return &integralpb.Value{Kind: &integralpb.Value_Json{Json: value}}
+}
+
+func TestServer_SetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, storage.New())
+
+	_, err := c.Set(ctx, &integralpb.SetRequest{Key: "doc", Value: jsonValue(t, map[string]interface{}{"a": 1.0})})
+	assert.Nil(t, err)
+
+	resp, err := c.Get(ctx, &integralpb.GetRequest{Key: "doc", Path: "/a", Peek: true})
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, resp.GetValue().GetJson().GetNumberValue())
+
+	resp, err = c.Get(ctx, &integralpb.GetRequest{Key: "doc"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1.0}, resp.GetValue().GetJson().AsInterface())
+
+	_, err = c.Get(ctx, &integralpb.GetRequest{Key: "doc"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	raw := &integralpb.Value{Kind: &integralpb.Value_Raw{Raw: &integralpb.Blob{ContentType: "image/png", Data: []byte{0, 1}}}}
+	_, err = c.Set(ctx, &integralpb.SetRequest{Key: "img", Value: raw})
+	assert.Nil(t, err)
+
+	resp, err = c.Get(ctx, &integralpb.GetRequest{Key: "img", Peek: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "image/png", resp.GetValue().GetRaw().GetContentType())
+	assert.Equal(t, []byte{0, 1}, resp.GetValue().GetRaw().GetData())
+
+	deleted, err := c.Delete(ctx, &integralpb.DeleteRequest{Keys: []string{"img", "doc"}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted.GetDeleted())
+}
+
+func TestServer_errors(t *testing.T) {
+	ctx := context.Background()
+	s := storage.New()
+	s.SAdd(context.Background(), "set", "a")
+	c := newClient(t, s)
+
+	_, err := c.Set(ctx, &integralpb.SetRequest{Key: "long key!", Value: jsonValue(t, "v")})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "you have validation errors", st.Message())
+
+	var (
+		info       *errdetails.ErrorInfo
+		badRequest *errdetails.BadRequest
+	)
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			badRequest = d
+		}
+	}
+	if assert.NotNil(t, info) && assert.NotNil(t, badRequest) {
+		assert.Equal(t, "validation_failed", info.GetReason())
+		assert.Equal(t, "key", badRequest.GetFieldViolations()[0].GetField())
+	}
+
+	_, err = c.Set(ctx, &integralpb.SetRequest{Key: "user:1", Value: jsonValue(t, "v")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = c.Get(ctx, &integralpb.GetRequest{Key: "set"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = c.Delete(ctx, &integralpb.DeleteRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_Batch(t *testing.T) {
+	c := newClient(t, storage.New())
+
+	resp, err := c.Batch(context.Background(), &integralpb.BatchRequest{
+		Operations: []*integralpb.Operation{
+			{Op: &integralpb.Operation_Set{Set: &integralpb.SetRequest{Key: "a", Value: jsonValue(t, "v")}}},
+			{Op: &integralpb.Operation_Get{Get: &integralpb.GetRequest{Key: "a", Peek: true}}},
+			{Op: &integralpb.Operation_Get{Get: &integralpb.GetRequest{Key: "missing"}}},
+			{Op: &integralpb.Operation_Delete{Delete: &integralpb.DeleteRequest{Keys: []string{"a"}}}},
+			{},
+		},
+	})
+	assert.Nil(t, err)
+
+	results := resp.GetResults()
+	if assert.Len(t, results, 5) {
+		assert.NotNil(t, results[0].GetSet())
+		assert.Equal(t, "v", results[1].GetGet().GetValue().GetJson().GetStringValue())
+		assert.Equal(t, "key_not_found", results[2].GetError().GetCode())
+		assert.Equal(t, int64(1), results[3].GetDelete().GetDeleted())
+		assert.Equal(t, "validation_failed", results[4].GetError().GetCode())
+		assert.Equal(t, "you have validation errors; operation: cannot be blank", results[4].GetError().GetMessage())
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	s := storage.New()
+	c := newClient(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.Watch(ctx, &integralpb.WatchRequest{Key: "w"})
+	assert.Nil(t, err)
+
+	// Watcher is registered when the first event can
+	// be received, so the key is set until it is.
+	events := make(chan *integralpb.WatchEvent)
+	go func() {
+		for {
+			e, err := stream.Recv()
+			if err != nil {
+				close(events)
+				return
+			}
+			events <- e
+		}
+	}()
+
+	var e *integralpb.WatchEvent
+	for e == nil {
+		s.Set(context.Background(), "w", "v")
+		select {
+		case e = <-events:
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	assert.Equal(t, integralpb.WatchEvent_TYPE_SET, e.GetType())
+	assert.Equal(t, "v", e.GetValue().GetJson().GetStringValue())
+
+	s.Del("w")
+	for e = range events {
+		if e.GetType() == integralpb.WatchEvent_TYPE_DELETE {
+			break
+		}
+	}
+	assert.Equal(t, "w", e.GetKey())
+	assert.Equal(t, integralpb.WatchEvent_TYPE_DELETE, e.GetType())
+}
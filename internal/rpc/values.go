@@ -0,0 +1,50 @@
+package rpc
+
+import (
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/rpc/integralpb"
+	"github.com/romanyx/integral_db/internal/storage"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const octetStreamContentType = "application/octet-stream"
+
+// toValue returns value stored for the message, raw
+// values are stored as blobs like HTTP raw bodies.
+func toValue(v *integralpb.Value) (interface{}, error) {
+	switch kind := v.GetKind().(type) {
+	case nil:
+		return nil, nil
+	case *integralpb.Value_Json:
+		return kind.Json.AsInterface(), nil
+	case *integralpb.Value_Raw:
+		contentType := kind.Raw.GetContentType()
+		if contentType == "" {
+			contentType = octetStreamContentType
+		}
+		return storage.Blob{ContentType: contentType, Data: kind.Raw.GetData()}, nil
+	default:
+		return nil, errors.Errorf("unknown value kind %T", kind)
+	}
+}
+
+// fromValue returns message of the stored value.
+func fromValue(value interface{}) (*integralpb.Value, error) {
+	switch v := value.(type) {
+	case storage.Blob:
+		return &integralpb.Value{
+			Kind: &integralpb.Value_Raw{Raw: &integralpb.Blob{ContentType: v.ContentType, Data: v.Data}},
+		}, nil
+	case []byte:
+		return &integralpb.Value{
+			Kind: &integralpb.Value_Raw{Raw: &integralpb.Blob{ContentType: octetStreamContentType, Data: v}},
+		}, nil
+	}
+
+	v, err := structpb.NewValue(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "convert value")
+	}
+
+	return &integralpb.Value{Kind: &integralpb.Value_Json{Json: v}}, nil
+}
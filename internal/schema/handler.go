@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewListHandler returns handler for registered schemas requests.
+func NewListHandler(srv Manager) http.HandlerFunc {
+	return handler(srv.List)
+}
+
+// NewRegisterHandler returns handler for schema register requests.
+func NewRegisterHandler(srv Manager) http.HandlerFunc {
+	return handler(srv.Register)
+}
+
+// NewDeleteHandler returns handler for schema delete requests.
+func NewDeleteHandler(srv Manager) http.HandlerFunc {
+	return handler(srv.Delete)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type schemasData struct {
+	Schemas map[string]interface{} `json:"schemas"`
+}
@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handler(t *testing.T) {
+	tests := []struct {
+		name      string
+		serveFunc func(*http.Request, *response) error
+		code      int
+	}{
+		{
+			name: "ok",
+			serveFunc: func(*http.Request, *response) error {
+				return nil
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "validation error",
+			serveFunc: func(*http.Request, *response) error {
+				return errors.Wrap(validationErrorResponse{}, "validation failed")
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not found error",
+			serveFunc: func(*http.Request, *response) error {
+				return notFoundResponse{}
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "unexpected error",
+			serveFunc: func(*http.Request, *response) error {
+				return errors.New("mock error")
+			},
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("POST", "http://any-host/admin/schemas", nil)
+			res := httptest.NewRecorder()
+
+			h := handler(tt.serveFunc)
+			h(res, req)
+
+			assert.Equal(t, tt.code, res.Code)
+		})
+	}
+}
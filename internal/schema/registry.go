@@ -0,0 +1,170 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// rootField is the field name gojsonschema
+// uses for violations of the whole document.
+const rootField = "(root)"
+
+// Violation describes value field
+// which does not conform the schema.
+type Violation struct {
+	Field   string
+	Message string
+}
+
+// Registry holds JSON Schemas registered for
+// key prefixes. Values of keys are validated
+// against the schema of the longest matching
+// prefix. Nil registry accepts any value.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]entry
+}
+
+type entry struct {
+	source interface{}
+	schema *gojsonschema.Schema
+}
+
+// NewRegistry returns empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: make(map[string]entry),
+	}
+}
+
+// Load returns registry with schemas from the JSON
+// config file, which maps key prefixes to schemas.
+func Load(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "open config")
+	}
+	defer f.Close()
+
+	var schemas map[string]interface{}
+	if err := json.NewDecoder(f).Decode(&schemas); err != nil {
+		return nil, errors.Wrap(err, "decode config")
+	}
+
+	r := NewRegistry()
+	for prefix, source := range schemas {
+		if err := r.Register(prefix, source); err != nil {
+			return nil, errors.Wrapf(err, "prefix %q", prefix)
+		}
+	}
+
+	return r, nil
+}
+
+// Register compiles the schema and registers it
+// for the prefix, replacing the previous one.
+func (r *Registry) Register(prefix string, source interface{}) error {
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(source))
+	if err != nil {
+		return errors.Wrap(err, "compile schema")
+	}
+
+	r.mu.Lock()
+	r.schemas[prefix] = entry{source: source, schema: schema}
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Delete removes schema of the prefix and
+// reports whether it was registered.
+func (r *Registry) Delete(prefix string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.schemas[prefix]
+	delete(r.schemas, prefix)
+
+	return ok
+}
+
+// Schemas returns registered schema sources by prefixes.
+func (r *Registry) Schemas() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schemas := make(map[string]interface{}, len(r.schemas))
+	for prefix, e := range r.schemas {
+		schemas[prefix] = e.source
+	}
+
+	return schemas
+}
+
+// Validate validates value of the key against the schema
+// of the longest matching prefix and returns violations
+// sorted by field names.
+func (r *Registry) Validate(key string, value interface{}) ([]Violation, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	schema := r.match(key)
+	if schema == nil {
+		return nil, nil
+	}
+
+	result, err := schema.Validate(gojsonschema.NewGoLoader(value))
+	if err != nil {
+		return nil, errors.Wrap(err, "validate")
+	}
+
+	var violations []Violation
+	for _, e := range result.Errors() {
+		field := e.Field()
+		if field == rootField {
+			field = ""
+		}
+		violations = append(violations, Violation{Field: field, Message: e.Description()})
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Field < violations[j].Field
+	})
+
+	return violations, nil
+}
+
+// Covers reports whether values of the
+// key are validated by some schema.
+func (r *Registry) Covers(key string) bool {
+	if r == nil {
+		return false
+	}
+
+	return r.match(key) != nil
+}
+
+func (r *Registry) match(key string) *gojsonschema.Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		longest = -1
+		schema  *gojsonschema.Schema
+	)
+
+	for prefix, e := range r.schemas {
+		if strings.HasPrefix(key, prefix) && len(prefix) > longest {
+			longest, schema = len(prefix), e.schema
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	assert.Error(t, r.Register("bad:", map[string]interface{}{"type": 1}))
+
+	assert.Nil(t, r.Register("user:", map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name"},
+	}))
+	assert.Nil(t, r.Register("user:admin:", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"level": map[string]interface{}{"type": "integer", "minimum": 1},
+		},
+	}))
+
+	tests := []struct {
+		name   string
+		key    string
+		value  interface{}
+		expect []Violation
+	}{
+		{
+			name:  "no schema",
+			key:   "any",
+			value: "value",
+		},
+		{
+			name:  "valid",
+			key:   "user:1",
+			value: map[string]interface{}{"name": "name"},
+		},
+		{
+			name:  "root violation",
+			key:   "user:1",
+			value: "value",
+			expect: []Violation{
+				Violation{Field: "", Message: "Invalid type. Expected: object, given: string"},
+			},
+		},
+		{
+			name:  "longest prefix",
+			key:   "user:admin:1",
+			value: map[string]interface{}{"level": 0},
+			expect: []Violation{
+				Violation{Field: "level", Message: "Must be greater than or equal to 1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := r.Validate(tt.key, tt.value)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestRegistry_Delete(t *testing.T) {
+	r := NewRegistry()
+	assert.Nil(t, r.Register("user:", map[string]interface{}{"type": "object"}))
+	assert.Equal(t, map[string]interface{}{
+		"user:": map[string]interface{}{"type": "object"},
+	}, r.Schemas())
+
+	assert.True(t, r.Covers("user:1"))
+	assert.False(t, r.Covers("other"))
+
+	assert.True(t, r.Delete("user:"))
+	assert.False(t, r.Covers("user:1"))
+	assert.False(t, r.Delete("user:"))
+	assert.Empty(t, r.Schemas())
+
+	violations, err := r.Validate("user:1", "value")
+	assert.Nil(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestRegistry_Validate_nil(t *testing.T) {
+	var r *Registry
+
+	violations, err := r.Validate("key", "value")
+	assert.Nil(t, err)
+	assert.Empty(t, violations)
+	assert.False(t, r.Covers("key"))
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	valid := filepath.Join(dir, "valid.json")
+	ioutil.WriteFile(valid, []byte(`{"user:": {"type": "object"}}`), 0600)
+	invalid := filepath.Join(dir, "invalid.json")
+	ioutil.WriteFile(invalid, []byte(`{"user:": {"type": 1}}`), 0600)
+
+	r, err := Load(valid)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"user:": map[string]interface{}{"type": "object"},
+	}, r.Schemas())
+
+	_, err = Load(invalid)
+	assert.Error(t, err)
+
+	_, err = Load(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
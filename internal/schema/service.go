@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+const (
+	schemasFoundMessage            = "schemas found"
+	schemaRegisteredMessage        = "schema registered"
+	schemaDeletedMessage           = "schema deleted"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "schema not found"
+)
+
+// Manager service for schema administration requests.
+type Manager interface {
+	List(r *http.Request, resp *response) error
+	Register(r *http.Request, resp *response) error
+	Delete(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service.
+func NewService(registry *Registry) Manager {
+	srv := muxMap{
+		decoder:   jsonDecoder{},
+		validater: ozzoValidater{},
+		registry:  registry,
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	registry
+}
+
+type request struct {
+	Prefix string      `json:"prefix"`
+	Schema interface{} `json:"schema"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type registry interface {
+	Register(prefix string, source interface{}) error
+	Delete(prefix string) bool
+	Schemas() map[string]interface{}
+}
+
+func (s muxMap) List(r *http.Request, resp *response) error {
+	resp.Message = schemasFoundMessage
+	resp.Data = schemasData{Schemas: s.registry.Schemas()}
+
+	return nil
+}
+
+func (s muxMap) Register(r *http.Request, resp *response) error {
+	req, err := s.request(r, "schema")
+	if err != nil {
+		return err
+	}
+
+	if err := s.registry.Register(req.Prefix, req.Schema); err != nil {
+		return validationErrorResponse{
+			Message: validationErrorResponseMessage,
+			Errors: []validationError{
+				validationError{Field: "schema", Message: errors.Cause(err).Error()},
+			},
+		}
+	}
+
+	resp.Message = schemaRegisteredMessage
+
+	return nil
+}
+
+func (s muxMap) Delete(r *http.Request, resp *response) error {
+	req, err := s.request(r)
+	if err != nil {
+		return err
+	}
+
+	if !s.registry.Delete(req.Prefix) {
+		return notFoundResponse{Message: notFoundMessage}
+	}
+
+	resp.Message = schemaDeletedMessage
+
+	return nil
+}
+
+// request decodes and validates request, prefix
+// is always required in addition to passed fields.
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	return decode.Request(r, req)
+}
+
+type ozzoValidater struct{}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	values := map[string]interface{}{
+		"prefix": r.Prefix,
+		"schema": r.Schema,
+	}
+
+	for _, field := range append([]string{"prefix"}, required...) {
+		if err := validation.Validate(values[field], validation.Required); err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeSchemaNotFound
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
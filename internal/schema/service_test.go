@@ -0,0 +1,222 @@
+package schema
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterFunc func(request, ...string) error
+
+func (f validaterFunc) Validate(r request, required ...string) error {
+	return f(r, required...)
+}
+
+type registryMock struct {
+	registerFunc func(string, interface{}) error
+	deleteFunc   func(string) bool
+	schemasFunc  func() map[string]interface{}
+}
+
+func (m registryMock) Register(prefix string, source interface{}) error {
+	return m.registerFunc(prefix, source)
+}
+
+func (m registryMock) Delete(prefix string) bool {
+	return m.deleteFunc(prefix)
+}
+
+func (m registryMock) Schemas() map[string]interface{} {
+	return m.schemasFunc()
+}
+
+func Test_muxMap_List(t *testing.T) {
+	s := muxMap{
+		registry: registryMock{
+			schemasFunc: func() map[string]interface{} {
+				return map[string]interface{}{"user:": true}
+			},
+		},
+	}
+
+	var got response
+	assert.Nil(t, s.List(nil, &got))
+	assert.Equal(t, response{
+		Message: "schemas found",
+		Data:    schemasData{Schemas: map[string]interface{}{"user:": true}},
+	}, got)
+}
+
+func Test_muxMap_Register(t *testing.T) {
+	tests := []struct {
+		name         string
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request, ...string) error
+		registerFunc func(string, interface{}) error
+		wantErr      bool
+		expectErr    error
+		expect       response
+	}{
+		{
+			name: "decoder error",
+			decodeFunc: func(*http.Request, *request) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "validater error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request, ...string) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
+		{
+			name: "compile error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request, ...string) error {
+				return nil
+			},
+			registerFunc: func(string, interface{}) error {
+				return errors.Wrap(errors.New("invalid type"), "compile schema")
+			},
+			wantErr: true,
+			expectErr: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "schema", Message: "invalid type"},
+				},
+			},
+		},
+		{
+			name: "ok",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request, ...string) error {
+				return nil
+			},
+			registerFunc: func(string, interface{}) error {
+				return nil
+			},
+			expect: response{
+				Message: "schema registered",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderFunc(tt.decodeFunc),
+				validater: validaterFunc(tt.validateFunc),
+				registry:  registryMock{registerFunc: tt.registerFunc},
+			}
+
+			var got response
+			err := s.Register(nil, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.expectErr != nil {
+					assert.Equal(t, tt.expectErr, errors.Cause(err))
+				}
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_muxMap_Delete(t *testing.T) {
+	tests := []struct {
+		name       string
+		deleteFunc func(string) bool
+		wantErr    bool
+		expect     response
+	}{
+		{
+			name: "not found",
+			deleteFunc: func(string) bool {
+				return false
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok",
+			deleteFunc: func(string) bool {
+				return true
+			},
+			expect: response{
+				Message: "schema deleted",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder: decoderFunc(func(*http.Request, *request) error {
+					return nil
+				}),
+				validater: validaterFunc(func(request, ...string) error {
+					return nil
+				}),
+				registry: registryMock{deleteFunc: tt.deleteFunc},
+			}
+
+			var got response
+			err := s.Delete(nil, &got)
+
+			if tt.wantErr {
+				assert.Equal(t, notFoundResponse{Message: "schema not found"}, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	validater := ozzoValidater{}
+
+	assert.Nil(t, validater.Validate(request{Prefix: "user:"}))
+	assert.Equal(t, validationErrorResponse{
+		Message: "you have validation errors",
+		Errors: []validationError{
+			validationError{
+				Field:   "prefix",
+				Message: "cannot be blank",
+			},
+			validationError{
+				Field:   "schema",
+				Message: "cannot be blank",
+			},
+		},
+	}, validater.Validate(request{}, "schema"))
+}
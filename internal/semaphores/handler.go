@@ -0,0 +1,41 @@
+package semaphores
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewAcquireHandler returns handler for permit acquire requests.
+func NewAcquireHandler(srv Semaphore) http.HandlerFunc {
+	return handler(srv.Acquire)
+}
+
+// NewReleaseHandler returns handler for permit release requests.
+func NewReleaseHandler(srv Semaphore) http.HandlerFunc {
+	return handler(srv.Release)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type permitData struct {
+	Permit    uint64    `json:"permit"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,309 @@
+package semaphores
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+// maxTimeout limits wait for a permit,
+// so requests finish before the server
+// write timeout.
+const maxTimeout = 10 * time.Second
+
+const (
+	acquiredMessage                = "permit acquired"
+	releasedMessage                = "permit released"
+	validationErrorResponseMessage = "you have validation errors"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+	ttlMessage                     = "must be a positive duration like 30s"
+	timeoutMessage                 = "must be a duration up to 10s"
+)
+
+// Semaphore service for semaphore requests.
+type Semaphore interface {
+	Acquire(r *http.Request, resp *response) error
+	Release(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, rules validate.Rules) Semaphore {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		permitter: &sPermitter{
+			storage: storage,
+		},
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	permitter
+}
+
+type request struct {
+	Key   string `json:"-"`
+	Limit int    `json:"limit"`
+	// TTL is lifetime of the permit
+	// and Timeout is wait for it,
+	// durations like "30s".
+	TTL     string `json:"ttl"`
+	Timeout string `json:"timeout"`
+	Permit  uint64 `json:"permit"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type permitter interface {
+	Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (uint64, error)
+	Release(key string, permit uint64) error
+}
+
+func (s muxMap) Acquire(r *http.Request, resp *response) error {
+	req, err := s.request(r, "limit", "ttl", "timeout")
+	if err != nil {
+		return err
+	}
+
+	ttl, _ := time.ParseDuration(req.TTL)
+	var timeout time.Duration
+	if req.Timeout != "" {
+		timeout, _ = time.ParseDuration(req.Timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	permit, err := s.permitter.Acquire(ctx, req.Key, req.Limit, ttl)
+	if err != nil {
+		return errors.Wrap(err, "acquire failed")
+	}
+
+	resp.Message = acquiredMessage
+	resp.Data = permitData{Permit: permit, ExpiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+func (s muxMap) Release(r *http.Request, resp *response) error {
+	req, err := s.request(r, "permit")
+	if err != nil {
+		return err
+	}
+
+	if err := s.permitter.Release(req.Key, req.Permit); err != nil {
+		return errors.Wrap(err, "release failed")
+	}
+
+	resp.Message = releasedMessage
+
+	return nil
+}
+
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	if err := decode.Request(r, req); err != nil {
+		return err
+	}
+
+	req.Key = mux.Vars(r)["key"]
+
+	return nil
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	for _, field := range append([]string{"key"}, required...) {
+		var err error
+		switch field {
+		case "key":
+			err = v.rules.Key(r.Key)
+		case "limit":
+			err = validation.Validate(r.Limit, validation.Required, validation.Min(1))
+		case "ttl":
+			if ttl, parseErr := time.ParseDuration(r.TTL); parseErr != nil || ttl <= 0 {
+				err = errors.New(ttlMessage)
+			}
+		case "timeout":
+			// Timeout is optional, without
+			// it acquire doesn't wait.
+			if r.Timeout == "" {
+				break
+			}
+			if timeout, parseErr := time.ParseDuration(r.Timeout); parseErr != nil || timeout < 0 || timeout > maxTimeout {
+				err = errors.New(timeoutMessage)
+			}
+		case "permit":
+			err = validation.Validate(r.Permit, validation.Required)
+		}
+
+		if err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sPermitter struct {
+	storage storage.Storage
+}
+
+func (s *sPermitter) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (uint64, error) {
+	permit, err := s.storage.AcquirePermit(ctx, key, limit, ttl)
+	return permit, storageError(err)
+}
+
+func (s *sPermitter) Release(key string, permit uint64) error {
+	return storageError(s.storage.ReleasePermit(key, permit))
+}
+
+func storageError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case storage.ErrNoPermits:
+		return noPermitsResponse{Message: err.Error()}
+	case storage.ErrPermitNotHeld:
+		return notHeldResponse{Message: err.Error()}
+	case storage.ErrWrongType:
+		return wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
+}
+
+type noPermitsResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r noPermitsResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r noPermitsResponse) Code() responses.Code {
+	return responses.CodeNoPermits
+}
+
+type notHeldResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notHeldResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notHeldResponse) Code() responses.Code {
+	return responses.CodePermitNotHeld
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
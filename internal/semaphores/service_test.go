@@ -0,0 +1,155 @@
+package semaphores
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type permitterMock struct {
+	acquireFunc func(context.Context, string, int, time.Duration) (uint64, error)
+	releaseFunc func(string, uint64) error
+}
+
+func (m permitterMock) Acquire(ctx context.Context, key string, limit int, ttl time.Duration) (uint64, error) {
+	return m.acquireFunc(ctx, key, limit, ttl)
+}
+
+func (m permitterMock) Release(key string, permit uint64) error {
+	return m.releaseFunc(key, permit)
+}
+
+func Test_muxMap_Acquire(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         request
+		acquireFunc func(context.Context, string, int, time.Duration) (uint64, error)
+		wantErr     bool
+		expect      uint64
+	}{
+		{
+			name:    "validation error",
+			req:     request{Key: "sem", TTL: "1m"},
+			wantErr: true,
+		},
+		{
+			name: "permitter error",
+			req:  request{Key: "sem", Limit: 1, TTL: "1m"},
+			acquireFunc: func(context.Context, string, int, time.Duration) (uint64, error) {
+				return 0, noPermitsResponse{Message: "no permits available"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "ok",
+			req:  request{Key: "sem", Limit: 5, TTL: "1m", Timeout: "2s"},
+			acquireFunc: func(ctx context.Context, key string, limit int, ttl time.Duration) (uint64, error) {
+				deadline, ok := ctx.Deadline()
+				assert.True(t, ok)
+				assert.WithinDuration(t, time.Now().Add(2*time.Second), deadline, time.Second)
+				assert.Equal(t, 5, limit)
+				assert.Equal(t, time.Minute, ttl)
+				return 7, nil
+			},
+			expect: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder: decoderFunc(func(_ *http.Request, req *request) error {
+					*req = tt.req
+					return nil
+				}),
+				validater: ozzoValidater{rules: validate.Rules{}},
+				permitter: permitterMock{acquireFunc: tt.acquireFunc},
+			}
+
+			var got response
+			err := s.Acquire(httptest.NewRequest("POST", "http://any", nil), &got)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "permit acquired", got.Message)
+			assert.Equal(t, tt.expect, got.Data.(permitData).Permit)
+		})
+	}
+}
+
+func Test_muxMap_Release(t *testing.T) {
+	s := muxMap{
+		decoder: decoderFunc(func(_ *http.Request, req *request) error {
+			*req = request{Key: "sem", Permit: 7}
+			return nil
+		}),
+		validater: ozzoValidater{rules: validate.Rules{}},
+		permitter: permitterMock{releaseFunc: func(key string, permit uint64) error {
+			if permit != 7 {
+				return errors.New("mock error")
+			}
+			return nil
+		}},
+	}
+
+	var got response
+	assert.Nil(t, s.Release(httptest.NewRequest("POST", "http://any", nil), &got))
+	assert.Equal(t, response{Message: "permit released"}, got)
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      request
+		required []string
+		expect   error
+	}{
+		{
+			name:     "valid",
+			req:      request{Key: "sem", Limit: 1, TTL: "30s"},
+			required: []string{"limit", "ttl", "timeout"},
+		},
+		{
+			name:     "invalid",
+			req:      request{Key: "sem", TTL: "never", Timeout: "1m"},
+			required: []string{"limit", "ttl", "timeout", "permit"},
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{Field: "limit", Message: "cannot be blank"},
+					validationError{Field: "ttl", Message: "must be a positive duration like 30s"},
+					validationError{Field: "timeout", Message: "must be a duration up to 10s"},
+					validationError{Field: "permit", Message: "cannot be blank"},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{rules: validate.Rules{}}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expect, validater.Validate(tt.req, tt.required...))
+		})
+	}
+}
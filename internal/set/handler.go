@@ -3,7 +3,6 @@ package set
 import (
 	"net/http"
 
-	"github.com/pkg/errors"
 	"github.com/romanyx/integral_db/internal/responses"
 )
 
@@ -13,16 +12,11 @@ func NewHandler(srv Setter) http.HandlerFunc {
 		var resp response
 
 		if err := srv.Set(r, &resp); err != nil {
-			switch resp := errors.Cause(err).(type) {
-			case validationErrorResponse:
-				responses.BadRequest(w, resp)
-			default:
-				responses.InternalServerError(w)
-			}
+			responses.Error(w, r, err)
 			return
 		}
 
-		responses.OK(w, resp)
+		responses.OK(w, r, resp)
 	}
 }
 
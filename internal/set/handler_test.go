@@ -59,3 +59,7 @@ type SetterFunc func(*http.Request, *response) error
 func (f SetterFunc) Set(r *http.Request, resp *response) error {
 	return f(r, resp)
 }
+
+func (f SetterFunc) SetKey(string, interface{}) error {
+	return nil
+}
@@ -2,30 +2,41 @@ package set
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
-	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/schema"
 	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
 )
 
 const (
 	keySetMessage                  = "key set"
 	validationErrorResponseMessage = "you have validation errors"
+	rawSchemaMessage               = "raw values can't be validated by the schema of the key"
 )
 
 // Setter service for set key requests.
 type Setter interface {
 	Set(r *http.Request, resp *response) error
+	// SetKey validates and sets value of the key
+	// for transports other than HTTP.
+	SetKey(key string, value interface{}) error
 }
 
-// NewService returns initialized service.
-func NewService(storage storage.Storage, keyLiveTime time.Duration) Setter {
+// NewService returns initialized service, keys and values
+// are validated with the rules and values against schemas
+// of key prefixes.
+func NewService(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules, schemas *schema.Registry) Setter {
 	srv := muxMap{
-		decoder:   jsonDecoder{},
-		validater: ozzoValidater{},
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules:   rules,
+			schemas: schemas,
+		},
 		setter: &sSetter{
 			storage: storage,
 		},
@@ -56,7 +67,7 @@ type validater interface {
 }
 
 type setter interface {
-	Set(ctx context.Context, key string, value interface{})
+	Set(ctx context.Context, key string, value interface{}) error
 }
 
 func (s muxMap) Set(r *http.Request, resp *response) error {
@@ -66,14 +77,25 @@ func (s muxMap) Set(r *http.Request, resp *response) error {
 		return errors.Wrap(err, "decode failed")
 	}
 
+	if err := s.SetKey(req.Key, req.Value); err != nil {
+		return err
+	}
+
+	resp.Message = keySetMessage
+
+	return nil
+}
+
+func (s muxMap) SetKey(key string, value interface{}) error {
+	req := request{Key: key, Value: value}
+
 	if err := s.validater.Validate(req); err != nil {
 		return errors.Wrap(err, "validation failed")
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), s.keyLiveTime)
-	s.setter.Set(ctx, req.Key, req.Value)
-
-	resp.Message = keySetMessage
+	if err := s.setter.Set(storage.Expire(s.keyLiveTime), req.Key, req.Value); err != nil {
+		return errors.Wrap(err, "set failed")
+	}
 
 	return nil
 }
@@ -81,24 +103,59 @@ func (s muxMap) Set(r *http.Request, resp *response) error {
 type jsonDecoder struct{}
 
 func (d jsonDecoder) Decode(r *http.Request, req *request) error {
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		errors.Wrap(err, "unable to decode")
-	}
-	return nil
+	return decode.Request(r, req)
 }
 
-type ozzoValidater struct{}
+type ozzoValidater struct {
+	rules   validate.Rules
+	schemas *schema.Registry
+}
 
 func (v ozzoValidater) Validate(r request) error {
 	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
 
-	if err := validation.Validate(r.Key, validation.Required); err != nil {
+	if err := v.rules.Key(r.Key); err != nil {
 		validatationError.Errors = append(validatationError.Errors,
 			validationError{Field: "key", Message: err.Error()},
 		)
 	}
 
+	if blob, ok := r.Value.(storage.Blob); ok {
+		if err := v.rules.Value(blob.Data); err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "value", Message: err.Error()},
+			)
+		}
+
+		if v.schemas.Covers(r.Key) {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "value", Message: rawSchemaMessage},
+			)
+		}
+	} else {
+		if err := v.rules.Value(r.Value); err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: "value", Message: err.Error()},
+			)
+		}
+
+		violations, err := v.schemas.Validate(r.Key, r.Value)
+		if err != nil {
+			return errors.Wrap(err, "schema validation")
+		}
+
+		for _, violation := range violations {
+			field := "value"
+			if violation.Field != "" {
+				field += "." + violation.Field
+			}
+
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: violation.Message},
+			)
+		}
+	}
+
 	if len(validatationError.Errors) > 0 {
 		return validatationError
 	}
@@ -110,8 +167,19 @@ type sSetter struct {
 	storage storage.Storage
 }
 
-func (s *sSetter) Set(ctx context.Context, key string, value interface{}) {
-	s.storage.Set(ctx, key, value)
+func (s *sSetter) Set(ctx context.Context, key string, value interface{}) error {
+	err := s.storage.TrySet(ctx, key, value)
+
+	switch err {
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
 }
 
 type notFoundResponse struct {
@@ -122,6 +190,25 @@ func (r notFoundResponse) Error() string {
 	return r.Message
 }
 
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
 type validationErrorResponse struct {
 	Message string            `json:"message"`
 	Errors  []validationError `json:"errors"`
@@ -135,3 +222,13 @@ type validationError struct {
 func (r validationErrorResponse) Error() string {
 	return r.Message
 }
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -5,9 +5,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/schema"
 	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,10 +27,10 @@ func (f validaterFunc) Validate(r request) error {
 	return f(r)
 }
 
-type setterFunc func(context.Context, string, interface{})
+type setterFunc func(context.Context, string, interface{}) error
 
-func (f setterFunc) Set(ctx context.Context, key string, value interface{}) {
-	f(ctx, key, value)
+func (f setterFunc) Set(ctx context.Context, key string, value interface{}) error {
+	return f(ctx, key, value)
 }
 
 func Test_muxMap_Set(t *testing.T) {
@@ -34,7 +38,7 @@ func Test_muxMap_Set(t *testing.T) {
 		name         string
 		decodeFunc   func(*http.Request, *request) error
 		validateFunc func(request) error
-		setFunc      func(ctx context.Context, key string, value interface{})
+		setFunc      func(ctx context.Context, key string, value interface{}) error
 		wantErr      bool
 		expect       response
 	}{
@@ -55,6 +59,19 @@ func Test_muxMap_Set(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "setter error",
+			decodeFunc: func(*http.Request, *request) error {
+				return nil
+			},
+			validateFunc: func(request) error {
+				return nil
+			},
+			setFunc: func(ctx context.Context, key string, value interface{}) error {
+				return errors.New("mock error")
+			},
+			wantErr: true,
+		},
 		{
 			name: "ok",
 			decodeFunc: func(*http.Request, *request) error {
@@ -63,7 +80,7 @@ func Test_muxMap_Set(t *testing.T) {
 			validateFunc: func(request) error {
 				return nil
 			},
-			setFunc: func(ctx context.Context, key string, value interface{}) {},
+			setFunc: func(ctx context.Context, key string, value interface{}) error { return nil },
 			expect: response{
 				Message: "key set",
 			},
@@ -97,6 +114,29 @@ func Test_muxMap_Set(t *testing.T) {
 	}
 }
 
+func Test_muxMap_SetKey(t *testing.T) {
+	var stored interface{}
+
+	s := muxMap{
+		validater: validaterFunc(func(r request) error {
+			if r.Key == "" {
+				return validationErrorResponse{}
+			}
+			return nil
+		}),
+		setter: setterFunc(func(_ context.Context, _ string, value interface{}) error {
+			stored = value
+			return nil
+		}),
+	}
+
+	assert.Error(t, s.SetKey("", "value"))
+	assert.Nil(t, stored)
+
+	assert.Nil(t, s.SetKey("key", "value"))
+	assert.Equal(t, "value", stored)
+}
+
 func Test_ozzoValidater_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -111,6 +151,68 @@ func Test_ozzoValidater_Validate(t *testing.T) {
 				Value: 0,
 			},
 		},
+		{
+			name: "valid schema",
+			req: request{
+				Key:   "user:1",
+				Value: map[string]interface{}{"name": "name", "age": 1},
+			},
+		},
+		{
+			name: "invalid schema",
+			req: request{
+				Key:   "user:1",
+				Value: map[string]interface{}{"age": "1"},
+			},
+			wantErr: true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "value",
+						Message: "name is required",
+					},
+					validationError{
+						Field:   "value.age",
+						Message: "Invalid type. Expected: integer, given: string",
+					},
+				},
+			},
+		},
+		{
+			name: "raw value",
+			req: request{
+				Key:   "user:1",
+				Value: storage.Blob{ContentType: "image/png", Data: []byte("png")},
+			},
+			wantErr: true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "value",
+						Message: "raw values can't be validated by the schema of the key",
+					},
+				},
+			},
+		},
+		{
+			name: "too long key",
+			req: request{
+				Key:   "long key name",
+				Value: 0,
+			},
+			wantErr: true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "key",
+						Message: "the length must be no more than 8",
+					},
+				},
+			},
+		},
 		{
 			name: "invalid key",
 			req: request{
@@ -130,7 +232,19 @@ func Test_ozzoValidater_Validate(t *testing.T) {
 		},
 	}
 
-	validater := ozzoValidater{}
+	schemas := schema.NewRegistry()
+	schemas.Register("user:", map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"name"},
+		"properties": map[string]interface{}{
+			"age": map[string]interface{}{"type": "integer"},
+		},
+	})
+
+	validater := ozzoValidater{
+		rules:   validate.Rules{MaxKeyLength: 8},
+		schemas: schemas,
+	}
 
 	for _, tt := range tests {
 		tt := tt
@@ -156,18 +270,32 @@ func Test_ozzoValidater_Validate(t *testing.T) {
 func Test_sSetter_Set(t *testing.T) {
 	tests := []struct {
 		name  string
-		key   string
+		quota storage.Quota
 		value interface{}
+		code  responses.Code
 	}{
 		{
 			name:  "ok",
-			key:   "key",
 			value: 1,
 		},
-	}
-
-	getter := &sSetter{
-		storage: storage.New(),
+		{
+			name:  "keys quota",
+			quota: storage.Quota{MaxKeys: 1},
+			value: 1,
+			code:  responses.CodeKeysQuotaExceeded,
+		},
+		{
+			name:  "bytes quota",
+			quota: storage.Quota{MaxBytes: 4},
+			value: "value",
+			code:  responses.CodeBytesQuotaExceeded,
+		},
+		{
+			name:  "ttl quota",
+			quota: storage.Quota{MaxTTL: time.Second},
+			value: 1,
+			code:  responses.CodeTTLQuotaExceeded,
+		},
 	}
 
 	for _, tt := range tests {
@@ -175,7 +303,21 @@ func Test_sSetter_Set(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			getter.Set(context.Background(), tt.key, tt.value)
+			s := storage.New()
+			s.SetQuota(tt.quota)
+			s.Set(context.Background(), "other", 1)
+
+			setter := &sSetter{
+				storage: s,
+			}
+
+			err := setter.Set(storage.Expire(time.Minute), "key", tt.value)
+			if tt.code == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.Equal(t, tt.code, err.(responses.Coder).Code())
 		})
 	}
 }
@@ -0,0 +1,71 @@
+package sets
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewSAddHandler returns handler for set members add requests.
+func NewSAddHandler(srv Setter) http.HandlerFunc {
+	return handler(srv.SAdd)
+}
+
+// NewSRemHandler returns handler for set members remove requests.
+func NewSRemHandler(srv Setter) http.HandlerFunc {
+	return handler(srv.SRem)
+}
+
+// NewSIsMemberHandler returns handler for set membership requests.
+func NewSIsMemberHandler(srv Setter) http.HandlerFunc {
+	return handler(srv.SIsMember)
+}
+
+// NewSMembersHandler returns handler for set members requests.
+func NewSMembersHandler(srv Setter) http.HandlerFunc {
+	return handler(srv.SMembers)
+}
+
+// NewSInterHandler returns handler for sets intersection requests.
+func NewSInterHandler(srv Setter) http.HandlerFunc {
+	return handler(srv.SInter)
+}
+
+// NewSUnionHandler returns handler for sets union requests.
+func NewSUnionHandler(srv Setter) http.HandlerFunc {
+	return handler(srv.SUnion)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type addedData struct {
+	Added int `json:"added"`
+}
+
+type removedData struct {
+	Removed int `json:"removed"`
+}
+
+type isMemberData struct {
+	IsMember bool `json:"is_member"`
+}
+
+type membersData struct {
+	Members []string `json:"members"`
+}
@@ -0,0 +1,382 @@
+package sets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	membersAddedMessage            = "members added"
+	membersRemovedMessage          = "members removed"
+	membershipCheckedMessage       = "membership checked"
+	membersFoundMessage            = "members found"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "key not found"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+)
+
+// Setter service for set requests.
+type Setter interface {
+	SAdd(r *http.Request, resp *response) error
+	SRem(r *http.Request, resp *response) error
+	SIsMember(r *http.Request, resp *response) error
+	SMembers(r *http.Request, resp *response) error
+	SInter(r *http.Request, resp *response) error
+	SUnion(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules) Setter {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		setter: &sSetter{
+			storage: storage,
+		},
+		keyLiveTime: keyLiveTime,
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	setter
+	keyLiveTime time.Duration
+}
+
+type request struct {
+	Key     string   `json:"key"`
+	Keys    []string `json:"keys"`
+	Member  string   `json:"member"`
+	Members []string `json:"members"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type setter interface {
+	SAdd(ctx context.Context, key string, members ...string) (int, error)
+	SRem(key string, members ...string) (int, error)
+	SIsMember(key, member string) (bool, error)
+	SMembers(key string) ([]string, error)
+	SInter(keys ...string) ([]string, error)
+	SUnion(keys ...string) ([]string, error)
+}
+
+func (s muxMap) SAdd(r *http.Request, resp *response) error {
+	req, err := s.request(r, "key", "members")
+	if err != nil {
+		return err
+	}
+
+	added, err := s.setter.SAdd(storage.Expire(s.keyLiveTime), req.Key, req.Members...)
+	if err != nil {
+		return errors.Wrap(err, "sadd failed")
+	}
+
+	resp.Message = membersAddedMessage
+	resp.Data = addedData{Added: added}
+
+	return nil
+}
+
+func (s muxMap) SRem(r *http.Request, resp *response) error {
+	req, err := s.request(r, "key", "members")
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.setter.SRem(req.Key, req.Members...)
+	if err != nil {
+		return errors.Wrap(err, "srem failed")
+	}
+
+	resp.Message = membersRemovedMessage
+	resp.Data = removedData{Removed: removed}
+
+	return nil
+}
+
+func (s muxMap) SIsMember(r *http.Request, resp *response) error {
+	req, err := s.request(r, "key", "member")
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.setter.SIsMember(req.Key, req.Member)
+	if err != nil {
+		return errors.Wrap(err, "sismember failed")
+	}
+
+	resp.Message = membershipCheckedMessage
+	resp.Data = isMemberData{IsMember: ok}
+
+	return nil
+}
+
+func (s muxMap) SMembers(r *http.Request, resp *response) error {
+	req, err := s.request(r, "key")
+	if err != nil {
+		return err
+	}
+
+	members, err := s.setter.SMembers(req.Key)
+	if err != nil {
+		return errors.Wrap(err, "smembers failed")
+	}
+
+	resp.Message = membersFoundMessage
+	resp.Data = membersData{Members: members}
+
+	return nil
+}
+
+func (s muxMap) SInter(r *http.Request, resp *response) error {
+	req, err := s.request(r, "keys")
+	if err != nil {
+		return err
+	}
+
+	members, err := s.setter.SInter(req.Keys...)
+	if err != nil {
+		return errors.Wrap(err, "sinter failed")
+	}
+
+	resp.Message = membersFoundMessage
+	resp.Data = membersData{Members: members}
+
+	return nil
+}
+
+func (s muxMap) SUnion(r *http.Request, resp *response) error {
+	req, err := s.request(r, "keys")
+	if err != nil {
+		return err
+	}
+
+	members, err := s.setter.SUnion(req.Keys...)
+	if err != nil {
+		return errors.Wrap(err, "sunion failed")
+	}
+
+	resp.Message = membersFoundMessage
+	resp.Data = membersData{Members: members}
+
+	return nil
+}
+
+// request decodes and validates request.
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	var req request
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	return decode.Request(r, req)
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	values := map[string]interface{}{
+		"keys":    r.Keys,
+		"member":  r.Member,
+		"members": r.Members,
+	}
+
+	for _, field := range required {
+		var err error
+		switch field {
+		case "key":
+			err = v.rules.Key(r.Key)
+		default:
+			err = validation.Validate(values[field], validation.Required)
+		}
+
+		if err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	for i, key := range r.Keys {
+		if err := v.rules.Key(key); err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: fmt.Sprintf("keys.%d", i), Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sSetter struct {
+	storage storage.Storage
+}
+
+func (s *sSetter) SAdd(ctx context.Context, key string, members ...string) (int, error) {
+	added, err := s.storage.SAdd(ctx, key, members...)
+	return added, storageError(err)
+}
+
+func (s *sSetter) SRem(key string, members ...string) (int, error) {
+	removed, err := s.storage.SRem(key, members...)
+	return removed, storageError(err)
+}
+
+func (s *sSetter) SIsMember(key, member string) (bool, error) {
+	ok, err := s.storage.SIsMember(key, member)
+	return ok, storageError(err)
+}
+
+func (s *sSetter) SMembers(key string) ([]string, error) {
+	members, err := s.storage.SMembers(key)
+	return members, storageError(err)
+}
+
+func (s *sSetter) SInter(keys ...string) ([]string, error) {
+	members, err := s.storage.SInter(storageKeys(keys)...)
+	return members, storageError(err)
+}
+
+func (s *sSetter) SUnion(keys ...string) ([]string, error) {
+	members, err := s.storage.SUnion(storageKeys(keys)...)
+	return members, storageError(err)
+}
+
+func storageKeys(keys []string) []interface{} {
+	converted := make([]interface{}, len(keys))
+	for i, key := range keys {
+		converted[i] = key
+	}
+
+	return converted
+}
+
+// storageError converts storage errors
+// to the response errors.
+func storageError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case storage.ErrNotFound:
+		return notFoundResponse{Message: notFoundMessage}
+	case storage.ErrWrongType:
+		return wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
@@ -0,0 +1,254 @@
+package sets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterFunc func(request, ...string) error
+
+func (f validaterFunc) Validate(r request, required ...string) error {
+	return f(r, required...)
+}
+
+type setterMock struct {
+	err error
+}
+
+func (s setterMock) SAdd(context.Context, string, ...string) (int, error) {
+	return 2, s.err
+}
+
+func (s setterMock) SRem(string, ...string) (int, error) {
+	return 1, s.err
+}
+
+func (s setterMock) SIsMember(string, string) (bool, error) {
+	return true, s.err
+}
+
+func (s setterMock) SMembers(string) ([]string, error) {
+	return []string{"a"}, s.err
+}
+
+func (s setterMock) SInter(...string) ([]string, error) {
+	return []string{"b"}, s.err
+}
+
+func (s setterMock) SUnion(...string) ([]string, error) {
+	return []string{"a", "b"}, s.err
+}
+
+func Test_muxMap(t *testing.T) {
+	ok := func(*http.Request, *request) error { return nil }
+	valid := func(request, ...string) error { return nil }
+	mockErr := errors.New("mock error")
+
+	tests := []struct {
+		name         string
+		call         func(Setter, *http.Request, *response) error
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request, ...string) error
+		setterErr    error
+		wantErr      bool
+		expect       response
+	}{
+		{
+			name: "decoder error",
+			call: Setter.SAdd,
+			decodeFunc: func(*http.Request, *request) error {
+				return mockErr
+			},
+			wantErr: true,
+		},
+		{
+			name:       "validater error",
+			call:       Setter.SInter,
+			decodeFunc: ok,
+			validateFunc: func(request, ...string) error {
+				return mockErr
+			},
+			wantErr: true,
+		},
+		{
+			name:         "setter error",
+			call:         Setter.SMembers,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			setterErr:    mockErr,
+			wantErr:      true,
+		},
+		{
+			name:         "sadd",
+			call:         Setter.SAdd,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members added", Data: addedData{Added: 2}},
+		},
+		{
+			name:         "srem",
+			call:         Setter.SRem,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members removed", Data: removedData{Removed: 1}},
+		},
+		{
+			name:         "sismember",
+			call:         Setter.SIsMember,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "membership checked", Data: isMemberData{IsMember: true}},
+		},
+		{
+			name:         "smembers",
+			call:         Setter.SMembers,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members found", Data: membersData{Members: []string{"a"}}},
+		},
+		{
+			name:         "sinter",
+			call:         Setter.SInter,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members found", Data: membersData{Members: []string{"b"}}},
+		},
+		{
+			name:         "sunion",
+			call:         Setter.SUnion,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members found", Data: membersData{Members: []string{"a", "b"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderFunc(tt.decodeFunc),
+				validater: validaterFunc(tt.validateFunc),
+				setter:    setterMock{err: tt.setterErr},
+			}
+
+			var got response
+			req := httptest.NewRequest("POST", "http://any", nil)
+			err := tt.call(s, req, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      request
+		required []string
+		wantErr  bool
+		expect   validationErrorResponse
+	}{
+		{
+			name:     "valid",
+			req:      request{Key: "key", Members: []string{"a"}},
+			required: []string{"key", "members"},
+		},
+		{
+			name:     "invalid keys",
+			req:      request{Key: "key"},
+			required: []string{"keys"},
+			wantErr:  true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "keys",
+						Message: "cannot be blank",
+					},
+				},
+			},
+		},
+		{
+			name:     "reserved keys",
+			req:      request{Keys: []string{"key", "__key"}},
+			required: []string{"keys"},
+			wantErr:  true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "keys.1",
+						Message: `must not start with reserved prefix "__"`,
+					},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{
+		rules: validate.Rules{ReservedPrefixes: []string{"__"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validater.Validate(tt.req, tt.required...)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				got, ok := err.(validationErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expect, got)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_sSetter(t *testing.T) {
+	s := &sSetter{
+		storage: storage.New(),
+	}
+
+	s.storage.Set(context.Background(), "string", "value")
+
+	_, err := s.SMembers("set")
+	assert.Equal(t, notFoundResponse{Message: "key not found"}, err)
+
+	_, err = s.SAdd(context.Background(), "string", "a")
+	assert.Equal(t, wrongTypeResponse{Message: "key holds the wrong kind of value"}, err)
+
+	_, err = s.SAdd(context.Background(), "set", "a")
+	assert.Nil(t, err)
+
+	members, err := s.SUnion("set", "missing")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a"}, members)
+}
@@ -0,0 +1,8 @@
+package storage
+
+// Blob is a raw binary value stored
+// with its original content type.
+type Blob struct {
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"math"
+)
+
+// hash is a value type which maps
+// fields to values under a single key.
+type hash map[string]interface{}
+
+// HSet sets field of the hash stored at key. When
+// key does not exist new hash is created and ctx
+// bounds its lifetime, otherwise ctx is ignored and
+// the hash keeps its original lifetime.
+func (m *muxMap) HSet(ctx context.Context, key interface{}, field string, value interface{}) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, created, err := m.lookupOrPut(ctx, key, TypeHash, hash{})
+	if err != nil {
+		return false, err
+	}
+
+	h := d.value.(hash)
+	old, ok := h[field]
+	size := d.info.Size + len(field) + sizeOf(value)
+	if ok {
+		size = d.info.Size - sizeOf(old) + sizeOf(value)
+	}
+
+	if err := m.grow(key, d, size); err != nil {
+		if created {
+			m.stop(key)
+		}
+		return false, err
+	}
+
+	h[field] = value
+
+	return !ok, nil
+}
+
+// HGet returns value of the hash field.
+func (m *muxMap) HGet(key interface{}, field string) (interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := d.value.(hash)[field]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return value, nil
+}
+
+// HDel removes fields from the hash and returns
+// the number of removed fields. Hash without
+// fields is removed from the storage.
+func (m *muxMap) HDel(key interface{}, fields ...string) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeHash)
+	if err != nil {
+		return 0, err
+	}
+
+	h := d.value.(hash)
+
+	var deleted int
+	for _, field := range fields {
+		if value, ok := h[field]; ok {
+			delete(h, field)
+			m.resize(key, d, d.info.Size-(len(field)+sizeOf(value)))
+			deleted++
+		}
+	}
+
+	if len(h) == 0 {
+		m.drop(key)
+	}
+
+	return deleted, nil
+}
+
+// HGetAll returns copy of all fields of the hash.
+func (m *muxMap) HGetAll(key interface{}) (map[string]interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	h := d.value.(hash)
+	fields := make(map[string]interface{}, len(h))
+	for field, value := range h {
+		fields[field] = value
+	}
+
+	return fields, nil
+}
+
+// HIncrBy increments integer value of the hash
+// field by incr. Missing field is treated as 0.
+func (m *muxMap) HIncrBy(ctx context.Context, key interface{}, field string, incr int64) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, created, err := m.lookupOrPut(ctx, key, TypeHash, hash{})
+	if err != nil {
+		return 0, err
+	}
+
+	h := d.value.(hash)
+
+	var value int64
+	v, ok := h[field]
+	size := d.info.Size + len(field)
+	if ok {
+		if value, ok = toInt64(v); !ok {
+			return 0, ErrNotInteger
+		}
+		size = d.info.Size - sizeOf(v)
+	}
+
+	if (incr > 0 && value > math.MaxInt64-incr) || (incr < 0 && value < math.MinInt64-incr) {
+		return 0, ErrOverflow
+	}
+
+	value += incr
+	if err := m.grow(key, d, size+sizeOf(value)); err != nil {
+		if created {
+			m.stop(key)
+		}
+		return 0, err
+	}
+
+	h[field] = value
+
+	return value, nil
+}
+
+// toInt64 converts numeric value to int64, values decoded
+// from JSON are float64 and from MessagePack or CBOR are
+// integers of any size.
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return uintToInt64(uint64(n))
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return uintToInt64(n)
+	case float32:
+		return floatToInt64(float64(n))
+	case float64:
+		return floatToInt64(n)
+	default:
+		return 0, false
+	}
+}
+
+func uintToInt64(n uint64) (int64, bool) {
+	if n > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
+
+func floatToInt64(n float64) (int64, bool) {
+	// Bounds are powers of two, so they are exact.
+	if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Hash(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When hash is not defined, should return not found error.")
+		{
+			value, err := s.HGet("hash0", "field")
+			assert.Equal(t, ErrNotFound, err)
+			assert.Nil(t, value)
+
+			fields, err := s.HGetAll("hash0")
+			assert.Equal(t, ErrNotFound, err)
+			assert.Nil(t, fields)
+		}
+
+		t.Log("\t Test: 1\t When field is set, should create hash and return field value.")
+		{
+			k := "hash1"
+
+			created, err := s.HSet(context.Background(), k, "a", "value")
+			assert.Nil(t, err)
+			assert.True(t, created)
+
+			created, err = s.HSet(context.Background(), k, "a", "other")
+			assert.Nil(t, err)
+			assert.False(t, created)
+
+			value, err := s.HGet(k, "a")
+			assert.Nil(t, err)
+			assert.Equal(t, "other", value)
+
+			value, err = s.HGet(k, "b")
+			assert.Equal(t, ErrNotFound, err)
+			assert.Nil(t, value)
+
+			fields, err := s.HGetAll(k)
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{"a": "other"}, fields)
+		}
+
+		t.Log("\t Test: 2\t When all fields are deleted, should remove hash.")
+		{
+			k := "hash2"
+			s.HSet(context.Background(), k, "a", 1)
+			s.HSet(context.Background(), k, "b", 2)
+
+			deleted, err := s.HDel(k, "a", "c")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, deleted)
+
+			deleted, err = s.HDel(k, "b")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, deleted)
+
+			_, err = s.HGetAll(k)
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 3\t When field is incremented, should treat missing and JSON numbers as integers.")
+		{
+			k := "hash3"
+
+			value, err := s.HIncrBy(context.Background(), k, "n", 5)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(5), value)
+
+			s.HSet(context.Background(), k, "f", float64(10))
+			value, err = s.HIncrBy(context.Background(), k, "f", -3)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(7), value)
+
+			s.HSet(context.Background(), k, "u", uint64(10))
+			value, err = s.HIncrBy(context.Background(), k, "u", 1)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(11), value)
+
+			s.HSet(context.Background(), k, "i", int8(-1))
+			value, err = s.HIncrBy(context.Background(), k, "i", 1)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(0), value)
+
+			s.HSet(context.Background(), k, "s", "string")
+			_, err = s.HIncrBy(context.Background(), k, "s", 1)
+			assert.Equal(t, ErrNotInteger, err)
+
+			s.HSet(context.Background(), k, "big", uint64(1<<63))
+			_, err = s.HIncrBy(context.Background(), k, "big", 1)
+			assert.Equal(t, ErrNotInteger, err)
+
+			s.HSet(context.Background(), k, "huge", float64(1<<63))
+			_, err = s.HIncrBy(context.Background(), k, "huge", 1)
+			assert.Equal(t, ErrNotInteger, err)
+
+			s.HSet(context.Background(), k, "max", int64(math.MaxInt64))
+			_, err = s.HIncrBy(context.Background(), k, "max", 1)
+			assert.Equal(t, ErrOverflow, err)
+
+			value, err = s.HIncrBy(context.Background(), k, "max", -1)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(math.MaxInt64-1), value)
+
+			s.HSet(context.Background(), k, "min", int64(math.MinInt64))
+			_, err = s.HIncrBy(context.Background(), k, "min", -1)
+			assert.Equal(t, ErrOverflow, err)
+
+			field, err := s.HGet(k, "min")
+			assert.Nil(t, err)
+			assert.Equal(t, int64(math.MinInt64), field)
+		}
+
+		t.Log("\t Test: 4\t When key holds other kind of value, should return wrong type error.")
+		{
+			k := "hash4"
+			s.Set(context.Background(), k, "value")
+
+			_, err := s.HSet(context.Background(), k, "a", 1)
+			assert.Equal(t, ErrWrongType, err)
+
+			s.Set(context.Background(), k, "value")
+			s.HDel(k, "a")
+
+			value, err := s.Get(k)
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+
+			s.HSet(context.Background(), k, "a", 1)
+			_, err = s.Get(k)
+			assert.Equal(t, ErrWrongType, err)
+		}
+
+		t.Log("\t Test: 5\t When context of the first field set is done, should delete whole hash.")
+		{
+			d := make(chan struct{})
+			ctxDoneCall = func() {
+				d <- struct{}{}
+			}
+			k := "hash5"
+			ctx, cancel := context.WithCancel(context.Background())
+			s.HSet(ctx, k, "a", 1)
+			s.HSet(context.Background(), k, "b", 2)
+			cancel()
+			<-d
+			ctxDoneCall = func() {}
+
+			_, err := s.HGetAll(k)
+			assert.Equal(t, ErrNotFound, err)
+		}
+	}
+}
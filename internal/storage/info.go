@@ -0,0 +1,98 @@
+package storage
+
+import "time"
+
+// Type is a kind of value stored under the key.
+type Type string
+
+const (
+	// TypeString is a plain value set by Set.
+	TypeString Type = "string"
+	// TypeHash is a field to value map.
+	TypeHash Type = "hash"
+	// TypeSet is an unordered set of members.
+	TypeSet Type = "set"
+	// TypeZSet is a set of members ordered by score.
+	TypeZSet Type = "zset"
+	// TypeThrottle is a state of the rate
+	// limit checked by Throttle.
+	TypeThrottle Type = "throttle"
+	// TypeLock is a lock holding its fencing token.
+	TypeLock Type = "lock"
+	// TypeSemaphore is a set of permits
+	// expiring at their own time.
+	TypeSemaphore Type = "semaphore"
+)
+
+// Info describes entry stored under the key.
+type Info struct {
+	Type       Type
+	CreatedAt  time.Time
+	AccessedAt time.Time
+	// ExpiresAt is zero when key
+	// lifetime is not bounded.
+	ExpiresAt time.Time
+	// Size is approximate size of
+	// the value in bytes.
+	Size int
+	// Version changes whenever the
+	// value is set or updated.
+	Version uint64
+}
+
+var now = time.Now
+
+// Info returns metadata of the entry stored
+// under the key, it does not mark key accessed.
+func (m *muxMap) Info(key interface{}) (Info, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, ok := m.storage[key]
+	if !ok {
+		return Info{}, ErrNotFound
+	}
+
+	return d.info, nil
+}
+
+// Sizer is implemented by values of other
+// types which report their size in bytes.
+type Sizer interface {
+	Size() int
+}
+
+// sizeOf returns approximate size of the
+// value in bytes, numbers are counted as
+// 8 bytes and containers as sum of their
+// keys and elements.
+func sizeOf(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case Sizer:
+		return v.Size()
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	case Blob:
+		return len(v.ContentType) + len(v.Data)
+	case bool:
+		return 1
+	case map[string]interface{}:
+		var size int
+		for key, value := range v {
+			size += len(key) + sizeOf(value)
+		}
+		return size
+	case []interface{}:
+		var size int
+		for _, value := range v {
+			size += sizeOf(value)
+		}
+		return size
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Info(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key is not defined, should return not found error.")
+		{
+			_, err := s.Info("info0")
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 1\t When key is set, should describe its type, size and expiration.")
+		{
+			k := "info1"
+			ctx := Expire(time.Minute)
+			deadline, _ := ctx.Deadline()
+			s.Set(ctx, k, map[string]interface{}{"name": "value", "n": float64(1)})
+
+			info, err := s.Info(k)
+			assert.Nil(t, err)
+			assert.Equal(t, TypeString, info.Type)
+			assert.Equal(t, 18, info.Size)
+			assert.Equal(t, deadline, info.ExpiresAt)
+			assert.Equal(t, info.CreatedAt, info.AccessedAt)
+		}
+
+		t.Log("\t Test: 2\t When container is modified, should track its size and access time.")
+		{
+			k := "info2"
+			s.HSet(context.Background(), k, "field", "value")
+			s.HSet(context.Background(), k, "other", "value")
+			s.HSet(context.Background(), k, "field", "v")
+			s.HDel(k, "other")
+
+			created, _ := s.Info(k)
+
+			accessed := created.CreatedAt.Add(time.Second)
+			now = func() time.Time { return accessed }
+			s.HGet(k, "field")
+			now = time.Now
+
+			info, err := s.Info(k)
+			assert.Nil(t, err)
+			assert.Equal(t, TypeHash, info.Type)
+			assert.Equal(t, 6, info.Size)
+			assert.True(t, info.ExpiresAt.IsZero())
+			assert.Equal(t, accessed, info.AccessedAt)
+
+			s.SAdd(context.Background(), "info2set", "a", "bc")
+			info, _ = s.Info("info2set")
+			assert.Equal(t, TypeSet, info.Type)
+			assert.Equal(t, 3, info.Size)
+
+			s.ZAdd(context.Background(), "info2zset", ZMember{Member: "a", Score: 1})
+			info, _ = s.Info("info2zset")
+			assert.Equal(t, TypeZSet, info.Type)
+			assert.Equal(t, 9, info.Size)
+		}
+
+		t.Log("\t Test: 3\t When blob is set, should count its content type and data.")
+		{
+			k := "info3"
+			s.Set(context.Background(), k, Blob{ContentType: "image/png", Data: []byte{1, 2, 3}})
+
+			info, err := s.Info(k)
+			assert.Nil(t, err)
+			assert.Equal(t, TypeString, info.Type)
+			assert.Equal(t, 12, info.Size)
+		}
+	}
+}
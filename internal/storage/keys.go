@@ -0,0 +1,132 @@
+package storage
+
+import "context"
+
+// Condition of the SetIf method.
+type Condition int
+
+const (
+	// Always sets the value.
+	Always Condition = iota
+	// IfNotExists sets the value only
+	// when key does not exist.
+	IfNotExists
+	// IfExists sets the value only
+	// when key already exists.
+	IfExists
+)
+
+// SetIf sets value of the key like Set when the
+// condition holds for key of any kind, otherwise
+// ctx is released. It reports whether value is set,
+// value which doesn't fit the quota is an error.
+func (m *muxMap) SetIf(ctx context.Context, key, value interface{}, cond Condition) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	_, exists := m.storage[key]
+	if (cond == IfNotExists && exists) || (cond == IfExists && !exists) {
+		release(ctx)
+		return false, nil
+	}
+
+	if _, err := m.put(ctx, key, TypeString, value, sizeOf(value)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Del removes keys of any kind and
+// returns number of removed keys.
+func (m *muxMap) Del(keys ...interface{}) int {
+	m.Lock()
+	defer m.Unlock()
+
+	var deleted int
+	for _, key := range keys {
+		if _, ok := m.storage[key]; ok {
+			m.drop(key)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+// Expire bounds lifetime of the existing key of any
+// kind with ctx instead of the previous context.
+func (m *muxMap) Expire(ctx context.Context, key interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+
+	d, ok := m.storage[key]
+	if !ok {
+		release(ctx)
+		return ErrNotFound
+	}
+
+	return m.expire(ctx, key, d)
+}
+
+// expire replaces context bounding lifetime of the entry
+// stored at key when it fits ttl quota of the key namespace,
+// otherwise ctx is released. Must be called with lock held.
+func (m *muxMap) expire(ctx context.Context, key interface{}, d *data) error {
+	if err := m.admitTTL(ctx, key); err != nil {
+		release(ctx)
+		return err
+	}
+
+	close(d.reset)
+	d.reset = make(chan struct{})
+	d.info.ExpiresAt, _ = ctx.Deadline()
+
+	go m.watch(ctx, key, d.reset)
+
+	return nil
+}
+
+// Len returns number of keys of the
+// storage outside of the namespaces.
+func (m *muxMap) Len() int {
+	return m.Stats().Keys
+}
+
+// Peek returns value stored at key with its metadata
+// without consuming the key, the value must not be
+// modified.
+func (m *muxMap) Peek(key interface{}) (interface{}, Info, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeString)
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	return d.value, d.info, nil
+}
+
+// CompareAndSet sets value of the key like Set only when
+// value of the key of any kind has the version, otherwise
+// ctx is released.
+func (m *muxMap) CompareAndSet(ctx context.Context, key, value interface{}, version uint64) error {
+	m.Lock()
+	defer m.Unlock()
+
+	d, ok := m.storage[key]
+	if !ok {
+		release(ctx)
+		return ErrNotFound
+	}
+
+	if d.info.Version != version {
+		release(ctx)
+		return ErrVersionMismatch
+	}
+
+	_, err := m.put(ctx, key, TypeString, value, sizeOf(value))
+
+	return err
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_SetIf(t *testing.T) {
+	s := New()
+	setIf := func(key string, cond Condition) bool {
+		ok, err := s.SetIf(context.Background(), key, "value", cond)
+		assert.Nil(t, err)
+		return ok
+	}
+
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key does not exist, should set only if not exists.")
+		{
+			k := "setif0"
+			assert.False(t, setIf(k, IfExists))
+			assert.True(t, setIf(k, IfNotExists))
+
+			value, err := s.Get(k)
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+		}
+
+		t.Log("\t Test: 1\t When key of other kind exists, should set only if exists.")
+		{
+			k := "setif1"
+			s.SAdd(context.Background(), k, "a")
+			assert.False(t, setIf(k, IfNotExists))
+			assert.True(t, setIf(k, IfExists))
+
+			value, err := s.Get(k)
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+		}
+	}
+}
+
+func Test_muxMap_Del(t *testing.T) {
+	s := New()
+	s.Set(context.Background(), "del0", "value")
+	s.HSet(context.Background(), "del1", "field", "value")
+
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, 2, s.Del("del0", "del1", "del2"))
+	assert.Equal(t, 0, s.Del("del0"))
+	assert.Equal(t, 0, s.Len())
+}
+
+func Test_muxMap_Expire(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key does not exist, should return not found error.")
+		{
+			assert.Equal(t, ErrNotFound, s.Expire(Expire(time.Minute), "expire0"))
+		}
+
+		t.Log("\t Test: 1\t When key exists, should replace its lifetime.")
+		{
+			k := "expire1"
+			ctx, cancel := context.WithCancel(context.Background())
+			s.Set(ctx, k, "value")
+
+			assert.Nil(t, s.Expire(Expire(time.Minute), k))
+			cancel()
+
+			info, err := s.Info(k)
+			assert.Nil(t, err)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), info.ExpiresAt, time.Second)
+		}
+
+		t.Log("\t Test: 2\t When new lifetime is done, should delete key.")
+		{
+			d := make(chan struct{})
+			ctxDoneCall = func() {
+				d <- struct{}{}
+			}
+
+			k := "expire2"
+			s.Set(context.Background(), k, "value")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			assert.Nil(t, s.Expire(ctx, k))
+			cancel()
+			<-d
+			ctxDoneCall = func() {}
+
+			_, err := s.Info(k)
+			assert.Equal(t, ErrNotFound, err)
+		}
+	}
+}
+
+func Test_muxMap_CompareAndSet(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key does not exist, should return not found error.")
+		{
+			assert.Equal(t, ErrNotFound, s.CompareAndSet(context.Background(), "cas0", "value", 1))
+		}
+
+		t.Log("\t Test: 1\t When version matches, should set value and change version.")
+		{
+			k := "cas1"
+			s.Set(context.Background(), k, "a")
+
+			value, info, err := s.Peek(k)
+			assert.Nil(t, err)
+			assert.Equal(t, "a", value)
+
+			assert.Nil(t, s.CompareAndSet(context.Background(), k, "b", info.Version))
+			assert.Equal(t, ErrVersionMismatch, s.CompareAndSet(context.Background(), k, "c", info.Version))
+
+			value, next, err := s.Peek(k)
+			assert.Nil(t, err)
+			assert.Equal(t, "b", value)
+			assert.True(t, next.Version > info.Version)
+		}
+
+		t.Log("\t Test: 2\t When value is updated, should change version.")
+		{
+			k := "cas2"
+			s.Set(context.Background(), k, "a")
+			_, info, _ := s.Peek(k)
+
+			s.Update(k, func(interface{}) (interface{}, error) { return "b", nil })
+
+			assert.Equal(t, ErrVersionMismatch, s.CompareAndSet(context.Background(), k, "c", info.Version))
+		}
+	}
+}
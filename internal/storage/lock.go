@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrLocked returns when a lock
+	// is acquired by other holder.
+	ErrLocked = errors.New("lock is held")
+	// ErrNotHolder returns when a lock is
+	// not held with the fencing token.
+	ErrNotHolder = errors.New("lock is not held with the token")
+)
+
+// Acquire acquires lock of the key when it is not held
+// and returns its fencing token, ctx bounds the lease.
+// Tokens increase with every acquired lock, so stale
+// holders can be fenced off. Otherwise ctx is released.
+func (m *muxMap) Acquire(ctx context.Context, key interface{}) (uint64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if d, ok := m.storage[key]; ok {
+		release(ctx)
+		if d.info.Type != TypeLock {
+			return 0, ErrWrongType
+		}
+		return 0, ErrLocked
+	}
+
+	// Token is the version of the entry,
+	// numbers are of the same size.
+	d, err := m.put(ctx, key, TypeLock, nil, sizeOf(uint64(0)))
+	if err != nil {
+		return 0, err
+	}
+	d.value = d.info.Version
+
+	return d.info.Version, nil
+}
+
+// Renew bounds the lease of the lock held with the
+// token with ctx instead of the previous context,
+// otherwise ctx is released.
+func (m *muxMap) Renew(ctx context.Context, key interface{}, token uint64) error {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.held(key, token)
+	if err != nil {
+		release(ctx)
+		return err
+	}
+
+	return m.expire(ctx, key, d)
+}
+
+// Release releases the lock held with the token.
+func (m *muxMap) Release(key interface{}, token uint64) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, err := m.held(key, token); err != nil {
+		return err
+	}
+
+	m.drop(key)
+
+	return nil
+}
+
+// held returns entry of the lock held with
+// the token. Must be called with lock held.
+func (m *muxMap) held(key interface{}, token uint64) (*data, error) {
+	d, err := m.lookup(key, TypeLock)
+	switch {
+	case err == ErrNotFound:
+		return nil, ErrNotHolder
+	case err != nil:
+		return nil, err
+	case d.value.(uint64) != token:
+		return nil, ErrNotHolder
+	}
+
+	return d, nil
+}
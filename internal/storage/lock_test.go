@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Lock(t *testing.T) {
+	s := New()
+
+	t.Log("Given initialized storage.")
+	{
+		var token uint64
+
+		t.Log("\t Test: 0\t When lock is acquired, should hold it until released.")
+		{
+			var err error
+			token, err = s.Acquire(Expire(time.Minute), "lock")
+			assert.Nil(t, err)
+
+			_, err = s.Acquire(Expire(time.Minute), "lock")
+			assert.Equal(t, ErrLocked, err)
+		}
+
+		t.Log("\t Test: 1\t When other token is used, should keep the lock.")
+		{
+			assert.Equal(t, ErrNotHolder, s.Renew(Expire(time.Minute), "lock", token+1))
+			assert.Equal(t, ErrNotHolder, s.Release("lock", token+1))
+			assert.Equal(t, ErrNotHolder, s.Release("other", token))
+		}
+
+		t.Log("\t Test: 2\t When lock is renewed, should extend its lease.")
+		{
+			assert.Nil(t, s.Renew(Expire(time.Hour), "lock", token))
+
+			info, err := s.Info("lock")
+			assert.Nil(t, err)
+			assert.Equal(t, TypeLock, info.Type)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), info.ExpiresAt, time.Minute)
+		}
+
+		t.Log("\t Test: 3\t When lock is released, should acquire it with greater token.")
+		{
+			assert.Nil(t, s.Release("lock", token))
+
+			next, err := s.Acquire(Expire(time.Minute), "lock")
+			assert.Nil(t, err)
+			assert.True(t, next > token)
+		}
+
+		t.Log("\t Test: 4\t When lease expires, should release the lock.")
+		{
+			d := make(chan struct{})
+			ctxDoneCall = func() {
+				d <- struct{}{}
+			}
+
+			expired, err := s.Acquire(Expire(time.Millisecond), "expiring")
+			assert.Nil(t, err)
+			<-d
+			ctxDoneCall = func() {}
+
+			assert.Equal(t, ErrNotHolder, s.Renew(Expire(time.Minute), "expiring", expired))
+
+			_, err = s.Acquire(Expire(time.Minute), "expiring")
+			assert.Nil(t, err)
+		}
+
+		t.Log("\t Test: 5\t When key holds other kind, should fail.")
+		{
+			s.Set(context.Background(), "string", "value")
+
+			_, err := s.Acquire(Expire(time.Minute), "string")
+			assert.Equal(t, ErrWrongType, err)
+			assert.Equal(t, ErrWrongType, s.Release("string", 1))
+		}
+	}
+}
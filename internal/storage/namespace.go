@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Stats describes keys of the namespace.
+type Stats struct {
+	Keys int
+	// Bytes is approximate size
+	// of the values in bytes.
+	Bytes int
+}
+
+// namespacedKey is a key of the namespace,
+// keys of the root storage are not wrapped.
+type namespacedKey struct {
+	namespace string
+	key       interface{}
+}
+
+// namespaceOf returns namespace of the
+// key, empty one for the root storage.
+func namespaceOf(key interface{}) string {
+	if k, ok := key.(namespacedKey); ok {
+		return k.namespace
+	}
+
+	return ""
+}
+
+// keyOf returns key as it was passed
+// to storage of its namespace.
+func keyOf(key interface{}) interface{} {
+	if k, ok := key.(namespacedKey); ok {
+		return k.key
+	}
+
+	return key
+}
+
+// Namespace returns storage of the namespace, its keys
+// are isolated from keys of the root storage and other
+// namespaces. Empty name is the root storage itself.
+func (m *muxMap) Namespace(name string) Storage {
+	if name == "" {
+		return m
+	}
+
+	return &namespace{m: m, name: name}
+}
+
+// Flush removes keys of the storage, keys of
+// namespaces are kept. It returns number of
+// removed keys.
+func (m *muxMap) Flush() int {
+	return m.flush("")
+}
+
+// Stats returns stats of the storage,
+// keys of namespaces are not counted.
+func (m *muxMap) Stats() Stats {
+	return m.statsOf("")
+}
+
+func (m *muxMap) flush(ns string) int {
+	m.Lock()
+	defer m.Unlock()
+
+	var deleted int
+	for key := range m.storage {
+		if namespaceOf(key) == ns {
+			m.drop(key)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+func (m *muxMap) statsOf(ns string) Stats {
+	m.Lock()
+	defer m.Unlock()
+
+	if stats, ok := m.stats[ns]; ok {
+		return *stats
+	}
+
+	return Stats{}
+}
+
+// namespace is storage of the namespace,
+// it wraps keys passed to the root one.
+type namespace struct {
+	m    *muxMap
+	name string
+}
+
+func (n *namespace) key(key interface{}) interface{} {
+	return namespacedKey{namespace: n.name, key: key}
+}
+
+func (n *namespace) keys(keys []interface{}) []interface{} {
+	wrapped := make([]interface{}, len(keys))
+	for i, key := range keys {
+		wrapped[i] = n.key(key)
+	}
+
+	return wrapped
+}
+
+func (n *namespace) Set(ctx context.Context, key, value interface{}) {
+	n.m.Set(ctx, n.key(key), value)
+}
+
+func (n *namespace) Get(key interface{}) (interface{}, error) {
+	return n.m.Get(n.key(key))
+}
+
+func (n *namespace) View(key interface{}, consume bool, fn func(value interface{}) (interface{}, error)) (interface{}, error) {
+	return n.m.View(n.key(key), consume, fn)
+}
+
+func (n *namespace) Update(key interface{}, fn func(value interface{}) (interface{}, error)) (interface{}, error) {
+	return n.m.Update(n.key(key), fn)
+}
+
+func (n *namespace) HSet(ctx context.Context, key interface{}, field string, value interface{}) (bool, error) {
+	return n.m.HSet(ctx, n.key(key), field, value)
+}
+
+func (n *namespace) HGet(key interface{}, field string) (interface{}, error) {
+	return n.m.HGet(n.key(key), field)
+}
+
+func (n *namespace) HDel(key interface{}, fields ...string) (int, error) {
+	return n.m.HDel(n.key(key), fields...)
+}
+
+func (n *namespace) HGetAll(key interface{}) (map[string]interface{}, error) {
+	return n.m.HGetAll(n.key(key))
+}
+
+func (n *namespace) HIncrBy(ctx context.Context, key interface{}, field string, incr int64) (int64, error) {
+	return n.m.HIncrBy(ctx, n.key(key), field, incr)
+}
+
+func (n *namespace) SAdd(ctx context.Context, key interface{}, members ...string) (int, error) {
+	return n.m.SAdd(ctx, n.key(key), members...)
+}
+
+func (n *namespace) SRem(key interface{}, members ...string) (int, error) {
+	return n.m.SRem(n.key(key), members...)
+}
+
+func (n *namespace) SIsMember(key interface{}, member string) (bool, error) {
+	return n.m.SIsMember(n.key(key), member)
+}
+
+func (n *namespace) SMembers(key interface{}) ([]string, error) {
+	return n.m.SMembers(n.key(key))
+}
+
+func (n *namespace) SInter(keys ...interface{}) ([]string, error) {
+	return n.m.SInter(n.keys(keys)...)
+}
+
+func (n *namespace) SUnion(keys ...interface{}) ([]string, error) {
+	return n.m.SUnion(n.keys(keys)...)
+}
+
+func (n *namespace) ZAdd(ctx context.Context, key interface{}, members ...ZMember) (int, error) {
+	return n.m.ZAdd(ctx, n.key(key), members...)
+}
+
+func (n *namespace) ZRange(key interface{}, start, stop int) ([]ZMember, error) {
+	return n.m.ZRange(n.key(key), start, stop)
+}
+
+func (n *namespace) ZRangeByScore(key interface{}, min, max float64) ([]ZMember, error) {
+	return n.m.ZRangeByScore(n.key(key), min, max)
+}
+
+func (n *namespace) ZRank(key interface{}, member string) (int, error) {
+	return n.m.ZRank(n.key(key), member)
+}
+
+func (n *namespace) Info(key interface{}) (Info, error) {
+	return n.m.Info(n.key(key))
+}
+
+func (n *namespace) SetIf(ctx context.Context, key, value interface{}, cond Condition) (bool, error) {
+	return n.m.SetIf(ctx, n.key(key), value, cond)
+}
+
+func (n *namespace) Del(keys ...interface{}) int {
+	return n.m.Del(n.keys(keys)...)
+}
+
+func (n *namespace) Expire(ctx context.Context, key interface{}) error {
+	return n.m.Expire(ctx, n.key(key))
+}
+
+func (n *namespace) Len() int {
+	return n.Stats().Keys
+}
+
+func (n *namespace) Peek(key interface{}) (interface{}, Info, error) {
+	return n.m.Peek(n.key(key))
+}
+
+func (n *namespace) CompareAndSet(ctx context.Context, key, value interface{}, version uint64) error {
+	return n.m.CompareAndSet(ctx, n.key(key), value, version)
+}
+
+func (n *namespace) Watch(ctx context.Context, key interface{}) <-chan Event {
+	return n.m.Watch(ctx, n.key(key))
+}
+
+// Namespace returns storage of other namespace,
+// namespaces are not nested.
+func (n *namespace) Namespace(name string) Storage {
+	return n.m.Namespace(name)
+}
+
+// Flush removes keys of the namespace.
+func (n *namespace) Flush() int {
+	return n.m.flush(n.name)
+}
+
+func (n *namespace) Throttle(key interface{}, rate Rate, quantity int) (ThrottleResult, error) {
+	return n.m.Throttle(n.key(key), rate, quantity)
+}
+
+func (n *namespace) Acquire(ctx context.Context, key interface{}) (uint64, error) {
+	return n.m.Acquire(ctx, n.key(key))
+}
+
+func (n *namespace) Renew(ctx context.Context, key interface{}, token uint64) error {
+	return n.m.Renew(ctx, n.key(key), token)
+}
+
+func (n *namespace) Release(key interface{}, token uint64) error {
+	return n.m.Release(n.key(key), token)
+}
+
+func (n *namespace) AcquirePermit(ctx context.Context, key interface{}, limit int, ttl time.Duration) (uint64, error) {
+	return n.m.AcquirePermit(ctx, n.key(key), limit, ttl)
+}
+
+func (n *namespace) ReleasePermit(key interface{}, permit uint64) error {
+	return n.m.ReleasePermit(n.key(key), permit)
+}
+
+// SetQuota sets quota of the namespace.
+func (n *namespace) SetQuota(q Quota) {
+	n.m.setQuota(n.name, q)
+}
+
+func (n *namespace) TrySet(ctx context.Context, key, value interface{}) error {
+	return n.m.TrySet(ctx, n.key(key), value)
+}
+
+// Stats returns stats of the namespace.
+func (n *namespace) Stats() Stats {
+	return n.m.statsOf(n.name)
+}
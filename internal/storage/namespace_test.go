@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Namespace(t *testing.T) {
+	s := New()
+	a := s.Namespace("a")
+	b := s.Namespace("b")
+
+	t.Log("Given initialized storage with namespaces.")
+	{
+		t.Log("\t Test: 0\t When the same key is set in namespaces, should keep them isolated.")
+		{
+			s.Set(context.Background(), "key", "root")
+			a.Set(context.Background(), "key", "a")
+			b.Set(context.Background(), "key", "b")
+
+			value, err := a.Get("key")
+			assert.Nil(t, err)
+			assert.Equal(t, "a", value)
+
+			_, err = a.Get("key")
+			assert.Equal(t, ErrNotFound, err)
+
+			value, err = b.Get("key")
+			assert.Nil(t, err)
+			assert.Equal(t, "b", value)
+
+			value, err = s.Get("key")
+			assert.Nil(t, err)
+			assert.Equal(t, "root", value)
+		}
+
+		t.Log("\t Test: 1\t When keys are changed, should track stats of each namespace.")
+		{
+			a.Set(context.Background(), "k0", "value")
+			a.HSet(context.Background(), "k1", "field", "value")
+			a.SAdd(context.Background(), "k2", "m0", "m1")
+			b.Set(context.Background(), "k0", "v")
+			s.Set(context.Background(), "k0", "root")
+
+			assert.Equal(t, Stats{Keys: 3, Bytes: 5 + 10 + 4}, a.Stats())
+			assert.Equal(t, Stats{Keys: 1, Bytes: 1}, b.Stats())
+			assert.Equal(t, Stats{Keys: 1, Bytes: 4}, s.Stats())
+			assert.Equal(t, 3, a.Len())
+
+			a.HDel("k1", "field")
+			a.SRem("k2", "m0")
+			a.Update("k0", func(interface{}) (interface{}, error) { return "v", nil })
+			assert.Equal(t, Stats{Keys: 2, Bytes: 1 + 2}, a.Stats())
+		}
+
+		t.Log("\t Test: 2\t When namespace is flushed, should keep other keys.")
+		{
+			assert.Equal(t, 2, a.Flush())
+			assert.Equal(t, Stats{}, a.Stats())
+			assert.Equal(t, Stats{Keys: 1, Bytes: 1}, b.Stats())
+
+			value, err := s.Get("k0")
+			assert.Nil(t, err)
+			assert.Equal(t, "root", value)
+
+			assert.Equal(t, 1, s.Namespace("b").Flush())
+			assert.Equal(t, 0, s.Flush())
+		}
+
+		t.Log("\t Test: 3\t When key expires, should untrack it.")
+		{
+			d := make(chan struct{})
+			ctxDoneCall = func() {
+				d <- struct{}{}
+			}
+
+			a.Set(Expire(time.Millisecond), "k0", "value")
+			<-d
+			ctxDoneCall = func() {}
+
+			assert.Equal(t, Stats{}, a.Stats())
+		}
+
+		t.Log("\t Test: 4\t When watched key changes, should send key of the namespace.")
+		{
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events := a.Watch(ctx, "k0")
+			b.Set(context.Background(), "k0", "b")
+			a.Set(context.Background(), "k0", "a")
+
+			assert.Equal(t, Event{Key: "k0", Op: OpSet, Value: "a"}, <-events)
+		}
+	}
+}
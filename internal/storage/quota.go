@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrKeysQuota returns when a key would
+	// exceed keys quota of the namespace.
+	ErrKeysQuota = errors.New("keys quota exceeded")
+	// ErrBytesQuota returns when a value would
+	// exceed bytes quota of the namespace.
+	ErrBytesQuota = errors.New("bytes quota exceeded")
+	// ErrTTLQuota returns when lifetime of
+	// a key would exceed the namespace quota.
+	ErrTTLQuota = errors.New("ttl quota exceeded")
+)
+
+// Quota limits keys of the namespace written by
+// any method except Set, zero fields are not
+// limited.
+type Quota struct {
+	MaxKeys int
+	// MaxBytes limits approximate
+	// size of the values in bytes.
+	MaxBytes int
+	MaxTTL   time.Duration
+}
+
+// SetQuota sets quota of the storage,
+// keys of namespaces are not limited.
+func (m *muxMap) SetQuota(q Quota) {
+	m.setQuota("", q)
+}
+
+// TrySet sets value of the key like Set when it fits
+// quota of the key namespace, otherwise ctx is released
+// and the exceeded quota is reported.
+func (m *muxMap) TrySet(ctx context.Context, key, value interface{}) error {
+	m.Lock()
+	defer m.Unlock()
+
+	_, err := m.put(ctx, key, TypeString, value, sizeOf(value))
+
+	return err
+}
+
+func (m *muxMap) setQuota(ns string, q Quota) {
+	m.Lock()
+	defer m.Unlock()
+
+	if q == (Quota{}) {
+		delete(m.quotas, ns)
+		return
+	}
+
+	m.quotas[ns] = q
+}
+
+// admit checks whether value of the size stored at key
+// until ctx is done fits quota of the key namespace.
+// Must be called with lock held.
+func (m *muxMap) admit(ctx context.Context, key interface{}, size int) error {
+	ns := namespaceOf(key)
+
+	q, ok := m.quotas[ns]
+	if !ok {
+		return nil
+	}
+
+	var stats Stats
+	if s, ok := m.stats[ns]; ok {
+		stats = *s
+	}
+
+	if d, ok := m.storage[key]; ok {
+		stats.Bytes -= d.info.Size
+	} else {
+		stats.Keys++
+	}
+	stats.Bytes += size
+
+	if q.MaxKeys > 0 && stats.Keys > q.MaxKeys {
+		return ErrKeysQuota
+	}
+
+	if q.MaxBytes > 0 && stats.Bytes > q.MaxBytes {
+		return ErrBytesQuota
+	}
+
+	return m.admitTTL(ctx, key)
+}
+
+// admitTTL checks whether key living until ctx is done
+// fits ttl quota of the key namespace. Must be called
+// with lock held.
+func (m *muxMap) admitTTL(ctx context.Context, key interface{}) error {
+	q, ok := m.quotas[namespaceOf(key)]
+	if !ok || q.MaxTTL <= 0 {
+		return nil
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > q.MaxTTL {
+		return ErrTTLQuota
+	}
+
+	return nil
+}
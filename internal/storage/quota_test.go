@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_TrySet(t *testing.T) {
+	s := New()
+	a := s.Namespace("a")
+	a.SetQuota(Quota{MaxKeys: 2, MaxBytes: 10, MaxTTL: time.Minute})
+
+	t.Log("Given initialized storage with quota of the namespace.")
+	{
+		t.Log("\t Test: 0\t When keys fit the quota, should set them.")
+		{
+			assert.Nil(t, a.TrySet(Expire(time.Second), "k0", "value"))
+			assert.Nil(t, a.TrySet(Expire(time.Second), "k1", "v"))
+			assert.Equal(t, Stats{Keys: 2, Bytes: 6}, a.Stats())
+		}
+
+		t.Log("\t Test: 1\t When quota would be exceeded, should keep the keys.")
+		{
+			assert.Equal(t, ErrKeysQuota, a.TrySet(Expire(time.Second), "k2", "v"))
+			assert.Equal(t, ErrBytesQuota, a.TrySet(Expire(time.Second), "k1", "long value"))
+			assert.Equal(t, ErrTTLQuota, a.TrySet(Expire(time.Hour), "k1", "v"))
+			assert.Equal(t, ErrTTLQuota, a.TrySet(context.Background(), "k1", "v"))
+			assert.Equal(t, Stats{Keys: 2, Bytes: 6}, a.Stats())
+		}
+
+		t.Log("\t Test: 2\t When key is replaced or consumed, should account its value.")
+		{
+			assert.Nil(t, a.TrySet(Expire(time.Second), "k0", "value1234"))
+
+			_, err := a.Get("k1")
+			assert.Nil(t, err)
+			assert.Nil(t, a.TrySet(Expire(time.Second), "k2", "v"))
+			assert.Equal(t, Stats{Keys: 2, Bytes: 10}, a.Stats())
+		}
+
+		t.Log("\t Test: 3\t When quota is set in other namespace, should not limit the key.")
+		{
+			assert.Nil(t, s.TrySet(context.Background(), "k0", "long value"))
+			assert.Nil(t, s.Namespace("b").TrySet(Expire(time.Hour), "k0", "long value"))
+		}
+
+		t.Log("\t Test: 4\t When quota is removed, should not limit the key.")
+		{
+			a.SetQuota(Quota{})
+			assert.Nil(t, a.TrySet(context.Background(), "k3", "long value"))
+		}
+	}
+}
+
+func Test_muxMap_quota(t *testing.T) {
+	s := New()
+	a := s.Namespace("a")
+	a.SetQuota(Quota{MaxKeys: 2, MaxBytes: 20, MaxTTL: time.Minute})
+
+	t.Log("Given initialized storage with quota of the namespace.")
+	{
+		t.Log("\t Test: 0\t When values grow over the quota, should keep them.")
+		{
+			_, err := a.HSet(Expire(time.Second), "h", "field", "value")
+			assert.Nil(t, err)
+
+			_, err = a.HSet(Expire(time.Second), "h", "other", "long value")
+			assert.Equal(t, ErrBytesQuota, err)
+
+			_, err = a.SAdd(Expire(time.Second), "s", "member", "long member")
+			assert.Equal(t, ErrBytesQuota, err)
+			assert.Equal(t, Stats{Keys: 1, Bytes: 10}, a.Stats())
+
+			fields, err := a.HGetAll("h")
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{"field": "value"}, fields)
+		}
+
+		t.Log("\t Test: 1\t When updated value exceeds the quota, should keep it.")
+		{
+			ok, err := a.SetIf(Expire(time.Second), "k", "v", IfNotExists)
+			assert.True(t, ok)
+			assert.Nil(t, err)
+
+			_, err = a.Update("k", func(interface{}) (interface{}, error) {
+				return "very long value", nil
+			})
+			assert.Equal(t, ErrBytesQuota, err)
+
+			value, _, err := a.Peek("k")
+			assert.Nil(t, err)
+			assert.Equal(t, "v", value)
+		}
+
+		t.Log("\t Test: 2\t When keys quota is reached, should not create keys.")
+		{
+			ok, err := a.SetIf(Expire(time.Second), "k2", "v", IfNotExists)
+			assert.False(t, ok)
+			assert.Equal(t, ErrKeysQuota, err)
+
+			_, err = a.Acquire(Expire(time.Second), "lock")
+			assert.Equal(t, ErrKeysQuota, err)
+
+			_, err = a.ZAdd(Expire(time.Second), "z", ZMember{Member: "m"})
+			assert.Equal(t, ErrKeysQuota, err)
+
+			_, err = a.Throttle("throttle", Rate{Count: 1, Period: time.Second, Burst: 1}, 1)
+			assert.Equal(t, ErrKeysQuota, err)
+			assert.Equal(t, Stats{Keys: 2, Bytes: 11}, a.Stats())
+		}
+
+		t.Log("\t Test: 3\t When lifetime exceeds the quota, should keep the key.")
+		{
+			assert.Equal(t, ErrTTLQuota, a.Expire(Expire(time.Hour), "k"))
+
+			a.Del("k")
+			done, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := a.AcquirePermit(done, "sem", 1, time.Hour)
+			assert.Equal(t, ErrTTLQuota, err)
+
+			token, err := a.Acquire(Expire(time.Second), "lock")
+			assert.Nil(t, err)
+			assert.Equal(t, ErrTTLQuota, a.Renew(Expire(time.Hour), "lock", token))
+		}
+	}
+}
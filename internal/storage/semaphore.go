@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNoPermits returns when all permits of
+	// the semaphore are held until ctx is done.
+	ErrNoPermits = errors.New("no permits available")
+	// ErrPermitNotHeld returns when a permit
+	// is released or expired already.
+	ErrPermitNotHeld = errors.New("permit is not held")
+	// ErrInvalidSemaphore returns when limit
+	// or ttl of the permits is not positive.
+	ErrInvalidSemaphore = errors.New("invalid semaphore")
+)
+
+// permitSize is approximate size
+// of the permit in bytes.
+const permitSize = 16
+
+// semaphore is a value type which maps
+// held permits to their expiration time.
+type semaphore struct {
+	permits map[uint64]time.Time
+	// released is closed when
+	// a permit is released.
+	released chan struct{}
+}
+
+// AcquirePermit acquires one of limit permits of the semaphore
+// stored at key for ttl and returns its id, permits of crashed
+// holders expire. It waits for a permit until ctx is done, but
+// tries at least once; ErrNoPermits is returned when no permit
+// was acquired. The key is removed with the last permit.
+func (m *muxMap) AcquirePermit(ctx context.Context, key interface{}, limit int, ttl time.Duration) (uint64, error) {
+	if limit <= 0 || ttl <= 0 {
+		return 0, ErrInvalidSemaphore
+	}
+
+	for {
+		permit, wait, err := m.tryAcquirePermit(key, limit, ttl)
+		if err != ErrNoPermits {
+			return permit, err
+		}
+
+		timer := time.NewTimer(wait.expires)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ErrNoPermits
+		case <-wait.released:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// permitWait describes when a permit
+// of the full semaphore may be free.
+type permitWait struct {
+	released chan struct{}
+	expires  time.Duration
+}
+
+func (m *muxMap) tryAcquirePermit(key interface{}, limit int, ttl time.Duration) (uint64, permitWait, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	t := now()
+
+	d, err := m.lookup(key, TypeSemaphore)
+	created := err == ErrNotFound
+	switch err {
+	case nil:
+	case ErrNotFound:
+		d, err = m.put(Expire(ttl), key, TypeSemaphore, semaphore{
+			permits:  make(map[uint64]time.Time),
+			released: make(chan struct{}),
+		}, 0)
+		if err != nil {
+			return 0, permitWait{}, err
+		}
+	default:
+		return 0, permitWait{}, err
+	}
+
+	sem := d.value.(semaphore)
+	m.expirePermits(key, d, t)
+
+	if len(sem.permits) >= limit {
+		wait := permitWait{released: sem.released}
+		for _, at := range sem.permits {
+			if wait.expires == 0 || at.Sub(t) < wait.expires {
+				wait.expires = at.Sub(t)
+			}
+		}
+
+		return 0, wait, ErrNoPermits
+	}
+
+	if err := m.grow(key, d, d.info.Size+permitSize); err != nil {
+		if created {
+			m.stop(key)
+		}
+		return 0, permitWait{}, err
+	}
+
+	// Key lives until its last permit expires.
+	if !created && d.info.ExpiresAt.Before(t.Add(ttl)) {
+		if err := m.expire(Expire(ttl), key, d); err != nil {
+			m.resize(key, d, d.info.Size-permitSize)
+			return 0, permitWait{}, err
+		}
+	}
+
+	permit := m.nextVersion()
+	sem.permits[permit] = t.Add(ttl)
+
+	return permit, permitWait{}, nil
+}
+
+// ReleasePermit releases the permit of the
+// semaphore stored at key, waiters are woken.
+func (m *muxMap) ReleasePermit(key interface{}, permit uint64) error {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeSemaphore)
+	if err == ErrNotFound {
+		return ErrPermitNotHeld
+	}
+	if err != nil {
+		return err
+	}
+
+	m.expirePermits(key, d, now())
+
+	sem := d.value.(semaphore)
+	if _, ok := sem.permits[permit]; !ok {
+		return ErrPermitNotHeld
+	}
+
+	delete(sem.permits, permit)
+	m.resize(key, d, d.info.Size-permitSize)
+
+	close(sem.released)
+	sem.released = make(chan struct{})
+	d.value = sem
+
+	if len(sem.permits) == 0 {
+		m.drop(key)
+	}
+
+	return nil
+}
+
+// expirePermits removes permits of the semaphore expired
+// at t. Must be called with lock held.
+func (m *muxMap) expirePermits(key interface{}, d *data, t time.Time) {
+	sem := d.value.(semaphore)
+	for permit, at := range sem.permits {
+		if !at.After(t) {
+			delete(sem.permits, permit)
+			m.resize(key, d, d.info.Size-permitSize)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Semaphore(t *testing.T) {
+	s := New()
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Log("Given initialized storage.")
+	{
+		var permits []uint64
+
+		t.Log("\t Test: 0\t When permits are held, should not acquire over the limit.")
+		{
+			for i := 0; i < 2; i++ {
+				permit, err := s.AcquirePermit(done, "sem", 2, time.Minute)
+				assert.Nil(t, err)
+				permits = append(permits, permit)
+			}
+
+			_, err := s.AcquirePermit(done, "sem", 2, time.Minute)
+			assert.Equal(t, ErrNoPermits, err)
+		}
+
+		t.Log("\t Test: 1\t When permit is released, should acquire it by the waiter.")
+		{
+			acquired := make(chan error)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				_, err := s.AcquirePermit(ctx, "sem", 2, time.Minute)
+				acquired <- err
+			}()
+
+			assert.Nil(t, s.ReleasePermit("sem", permits[0]))
+			assert.Nil(t, <-acquired)
+			assert.Equal(t, ErrPermitNotHeld, s.ReleasePermit("sem", permits[0]))
+		}
+
+		t.Log("\t Test: 2\t When permits expire, should acquire them.")
+		{
+			now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+			defer func() { now = time.Now }()
+
+			_, err := s.AcquirePermit(done, "sem", 2, time.Minute)
+			assert.Nil(t, err)
+			assert.Equal(t, ErrPermitNotHeld, s.ReleasePermit("sem", permits[1]))
+
+			info, err := s.Info("sem")
+			assert.Nil(t, err)
+			assert.Equal(t, TypeSemaphore, info.Type)
+			assert.Equal(t, permitSize, info.Size)
+		}
+
+		t.Log("\t Test: 3\t When the last permit is released, should remove the key.")
+		{
+			permit, err := s.AcquirePermit(done, "other", 1, time.Minute)
+			assert.Nil(t, err)
+			assert.Nil(t, s.ReleasePermit("other", permit))
+
+			_, err = s.Info("other")
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 4\t When limit is invalid or key holds other kind, should fail.")
+		{
+			_, err := s.AcquirePermit(done, "sem", 0, time.Minute)
+			assert.Equal(t, ErrInvalidSemaphore, err)
+
+			s.Set(context.Background(), "string", "value")
+			_, err = s.AcquirePermit(done, "string", 1, time.Minute)
+			assert.Equal(t, ErrWrongType, err)
+		}
+
+		t.Log("\t Test: 5\t When permit expires before the key, should keep the key lifetime.")
+		{
+			_, err := s.AcquirePermit(done, "third", 2, time.Minute)
+			assert.Nil(t, err)
+
+			before, err := s.Info("third")
+			assert.Nil(t, err)
+
+			now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+			defer func() { now = time.Now }()
+
+			_, err = s.AcquirePermit(done, "third", 2, 90*time.Second)
+			assert.Nil(t, err)
+
+			after, err := s.Info("third")
+			assert.Nil(t, err)
+			assert.Equal(t, before.ExpiresAt, after.ExpiresAt)
+		}
+	}
+}
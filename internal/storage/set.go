@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"sort"
+)
+
+// set is a value type which holds
+// unordered unique members.
+type set map[string]struct{}
+
+// SAdd adds members to the set stored at key and
+// returns the number of added members. When key
+// does not exist new set is created and ctx bounds
+// its lifetime, otherwise ctx is ignored.
+func (m *muxMap) SAdd(ctx context.Context, key interface{}, members ...string) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, created, err := m.lookupOrPut(ctx, key, TypeSet, set{})
+	if err != nil {
+		return 0, err
+	}
+
+	s := d.value.(set)
+
+	// Members are added only when
+	// all of them fit the quota.
+	size := d.info.Size
+	added := make(set)
+	for _, member := range members {
+		if _, ok := s[member]; ok {
+			continue
+		}
+		if _, ok := added[member]; !ok {
+			added[member] = struct{}{}
+			size += len(member)
+		}
+	}
+
+	if err := m.grow(key, d, size); err != nil {
+		if created {
+			m.stop(key)
+		}
+		return 0, err
+	}
+
+	for member := range added {
+		s[member] = struct{}{}
+	}
+
+	return len(added), nil
+}
+
+// SRem removes members from the set and returns
+// the number of removed members. Set without
+// members is removed from the storage.
+func (m *muxMap) SRem(key interface{}, members ...string) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeSet)
+	if err != nil {
+		return 0, err
+	}
+
+	s := d.value.(set)
+
+	var removed int
+	for _, member := range members {
+		if _, ok := s[member]; ok {
+			delete(s, member)
+			m.resize(key, d, d.info.Size-len(member))
+			removed++
+		}
+	}
+
+	if len(s) == 0 {
+		m.drop(key)
+	}
+
+	return removed, nil
+}
+
+// SIsMember reports whether member belongs
+// to the set, missing key is an empty set.
+func (m *muxMap) SIsMember(key interface{}, member string) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	s, err := m.lookupSet(key)
+	switch err {
+	case nil:
+		_, ok := s[member]
+		return ok, nil
+	case ErrNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// SMembers returns sorted members of the set.
+func (m *muxMap) SMembers(key interface{}) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	s, err := m.lookupSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.members(), nil
+}
+
+// SInter returns sorted members which belong to all
+// of the sets, missing keys are empty sets.
+func (m *muxMap) SInter(keys ...interface{}) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	sets, err := m.lookupSets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	inter := set{}
+	if len(sets) == 0 || len(sets) < len(keys) {
+		return inter.members(), nil
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		return len(sets[i]) < len(sets[j])
+	})
+
+next:
+	for member := range sets[0] {
+		for _, s := range sets[1:] {
+			if _, ok := s[member]; !ok {
+				continue next
+			}
+		}
+		inter[member] = struct{}{}
+	}
+
+	return inter.members(), nil
+}
+
+// SUnion returns sorted members which belong to any
+// of the sets, missing keys are empty sets.
+func (m *muxMap) SUnion(keys ...interface{}) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	sets, err := m.lookupSets(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	union := set{}
+	for _, s := range sets {
+		for member := range s {
+			union[member] = struct{}{}
+		}
+	}
+
+	return union.members(), nil
+}
+
+func (s set) members() []string {
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members
+}
+
+// lookupSet returns set stored at key.
+// Must be called with lock held.
+func (m *muxMap) lookupSet(key interface{}) (set, error) {
+	d, err := m.lookup(key, TypeSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.value.(set), nil
+}
+
+// lookupSets returns existing sets stored at keys,
+// missing keys are skipped. Must be called with
+// lock held.
+func (m *muxMap) lookupSets(keys []interface{}) ([]set, error) {
+	sets := make([]set, 0, len(keys))
+	for _, key := range keys {
+		s, err := m.lookupSet(key)
+		switch err {
+		case nil:
+			sets = append(sets, s)
+		case ErrNotFound:
+		default:
+			return nil, err
+		}
+	}
+
+	return sets, nil
+}
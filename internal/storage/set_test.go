@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Sets(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When set is not defined, should return not found error or empty result.")
+		{
+			members, err := s.SMembers("set0")
+			assert.Equal(t, ErrNotFound, err)
+			assert.Nil(t, members)
+
+			ok, err := s.SIsMember("set0", "a")
+			assert.Nil(t, err)
+			assert.False(t, ok)
+		}
+
+		t.Log("\t Test: 1\t When members are added, should store unique members.")
+		{
+			k := "set1"
+
+			added, err := s.SAdd(context.Background(), k, "b", "a", "b")
+			assert.Nil(t, err)
+			assert.Equal(t, 2, added)
+
+			added, err = s.SAdd(context.Background(), k, "a", "c")
+			assert.Nil(t, err)
+			assert.Equal(t, 1, added)
+
+			members, err := s.SMembers(k)
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"a", "b", "c"}, members)
+
+			ok, err := s.SIsMember(k, "c")
+			assert.Nil(t, err)
+			assert.True(t, ok)
+		}
+
+		t.Log("\t Test: 2\t When all members are removed, should remove set.")
+		{
+			k := "set2"
+			s.SAdd(context.Background(), k, "a", "b")
+
+			removed, err := s.SRem(k, "a", "b", "c")
+			assert.Nil(t, err)
+			assert.Equal(t, 2, removed)
+
+			_, err = s.SMembers(k)
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 3\t When sets are intersected or united, should treat missing keys as empty sets.")
+		{
+			s.SAdd(context.Background(), "set3a", "a", "b", "c")
+			s.SAdd(context.Background(), "set3b", "b", "c", "d")
+
+			members, err := s.SInter("set3a", "set3b")
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"b", "c"}, members)
+
+			members, err = s.SInter("set3a", "set3b", "set3c")
+			assert.Nil(t, err)
+			assert.Equal(t, []string{}, members)
+
+			members, err = s.SUnion("set3a", "set3b", "set3c")
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"a", "b", "c", "d"}, members)
+		}
+
+		t.Log("\t Test: 4\t When key holds other kind of value, should return wrong type error.")
+		{
+			s.Set(context.Background(), "set4", "value")
+			s.SAdd(context.Background(), "set4a", "a")
+
+			_, err := s.SAdd(context.Background(), "set4", "a")
+			assert.Equal(t, ErrWrongType, err)
+
+			_, err = s.SUnion("set4a", "set4")
+			assert.Equal(t, ErrWrongType, err)
+
+			_, err = s.Get("set4a")
+			assert.Equal(t, ErrWrongType, err)
+		}
+	}
+}
+
+func Test_muxMap_ZSets(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When sorted set is not defined, should return not found error.")
+		{
+			_, err := s.ZRange("zset0", 0, -1)
+			assert.Equal(t, ErrNotFound, err)
+
+			_, err = s.ZRank("zset0", "a")
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 1\t When members are added, should order them by score and update existing.")
+		{
+			k := "zset1"
+
+			added, err := s.ZAdd(context.Background(), k,
+				ZMember{Member: "c", Score: 3},
+				ZMember{Member: "a", Score: 1},
+				ZMember{Member: "b", Score: 2},
+			)
+			assert.Nil(t, err)
+			assert.Equal(t, 3, added)
+
+			added, err = s.ZAdd(context.Background(), k, ZMember{Member: "a", Score: 4})
+			assert.Nil(t, err)
+			assert.Equal(t, 0, added)
+
+			members, err := s.ZRange(k, 0, -1)
+			assert.Nil(t, err)
+			assert.Equal(t, []ZMember{{"b", 2}, {"c", 3}, {"a", 4}}, members)
+
+			members, err = s.ZRange(k, -2, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, []ZMember{{"c", 3}, {"a", 4}}, members)
+
+			members, err = s.ZRange(k, 2, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, []ZMember{}, members)
+
+			rank, err := s.ZRank(k, "a")
+			assert.Nil(t, err)
+			assert.Equal(t, 2, rank)
+
+			_, err = s.ZRank(k, "d")
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 2\t When range by score is requested, should return members with scores in range.")
+		{
+			k := "zset2"
+			s.ZAdd(context.Background(), k,
+				ZMember{Member: "a", Score: 1},
+				ZMember{Member: "b", Score: 2},
+				ZMember{Member: "c", Score: 2},
+				ZMember{Member: "d", Score: 5},
+			)
+
+			members, err := s.ZRangeByScore(k, 2, 4)
+			assert.Nil(t, err)
+			assert.Equal(t, []ZMember{{"b", 2}, {"c", 2}}, members)
+		}
+
+		t.Log("\t Test: 3\t When key holds other kind of value, should return wrong type error.")
+		{
+			s.SAdd(context.Background(), "zset3", "a")
+
+			_, err := s.ZAdd(context.Background(), "zset3", ZMember{Member: "a"})
+			assert.Equal(t, ErrWrongType, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package storage
+
+import "math/rand"
+
+const (
+	skipListMaxLevel = 32
+	skipListP        = 0.25
+)
+
+// skipList keeps members ordered by score and
+// member name. Every level link stores the span,
+// the number of nodes it skips over, so the rank
+// of a node is calculated in O(log N).
+type skipList struct {
+	head   *skipListNode
+	level  int
+	length int
+}
+
+type skipListNode struct {
+	member string
+	score  float64
+	levels []skipListLevel
+}
+
+type skipListLevel struct {
+	next *skipListNode
+	span int
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		head:  newSkipListNode(skipListMaxLevel, "", 0),
+		level: 1,
+	}
+}
+
+func newSkipListNode(level int, member string, score float64) *skipListNode {
+	return &skipListNode{
+		member: member,
+		score:  score,
+		levels: make([]skipListLevel, level),
+	}
+}
+
+// less reports whether node is ordered
+// before member with the score.
+func (n *skipListNode) less(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+// after reports whether node is ordered
+// after member with the score.
+func (n *skipListNode) after(score float64, member string) bool {
+	return n.score > score || (n.score == score && n.member > member)
+}
+
+func randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && rand.Float64() < skipListP {
+		level++
+	}
+	return level
+}
+
+// insert adds member with the score, member
+// must not be present in the list.
+func (l *skipList) insert(member string, score float64) {
+	var (
+		update [skipListMaxLevel]*skipListNode
+		rank   [skipListMaxLevel]int
+	)
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.levels[i].next != nil && x.levels[i].next.less(score, member) {
+			rank[i] += x.levels[i].span
+			x = x.levels[i].next
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > l.level {
+		for i := l.level; i < level; i++ {
+			update[i] = l.head
+			update[i].levels[i].span = l.length
+		}
+		l.level = level
+	}
+
+	x = newSkipListNode(level, member, score)
+	for i := 0; i < level; i++ {
+		x.levels[i].next = update[i].levels[i].next
+		update[i].levels[i].next = x
+
+		x.levels[i].span = update[i].levels[i].span - (rank[0] - rank[i])
+		update[i].levels[i].span = rank[0] - rank[i] + 1
+	}
+
+	for i := level; i < l.level; i++ {
+		update[i].levels[i].span++
+	}
+
+	l.length++
+}
+
+// delete removes member with the score.
+func (l *skipList) delete(member string, score float64) {
+	var update [skipListMaxLevel]*skipListNode
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].next != nil && x.levels[i].next.less(score, member) {
+			x = x.levels[i].next
+		}
+		update[i] = x
+	}
+
+	x = x.levels[0].next
+	if x == nil || x.score != score || x.member != member {
+		return
+	}
+
+	for i := 0; i < l.level; i++ {
+		if update[i].levels[i].next == x {
+			update[i].levels[i].span += x.levels[i].span - 1
+			update[i].levels[i].next = x.levels[i].next
+		} else {
+			update[i].levels[i].span--
+		}
+	}
+
+	for l.level > 1 && l.head.levels[l.level-1].next == nil {
+		l.level--
+	}
+
+	l.length--
+}
+
+// rank returns 0 based rank of the member
+// with the score or -1 if it is not found.
+func (l *skipList) rank(member string, score float64) int {
+	var rank int
+
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].next != nil && !x.levels[i].next.after(score, member) {
+			rank += x.levels[i].span
+			x = x.levels[i].next
+		}
+		if x != l.head && x.member == member {
+			return rank - 1
+		}
+	}
+
+	return -1
+}
+
+// byRank returns node with 0 based rank.
+func (l *skipList) byRank(rank int) *skipListNode {
+	var traversed int
+
+	rank++
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].next != nil && traversed+x.levels[i].span <= rank {
+			traversed += x.levels[i].span
+			x = x.levels[i].next
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+
+	return nil
+}
+
+// firstFrom returns first node
+// with the score not less than min.
+func (l *skipList) firstFrom(min float64) *skipListNode {
+	x := l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.levels[i].next != nil && x.levels[i].next.score < min {
+			x = x.levels[i].next
+		}
+	}
+
+	return x.levels[0].next
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_skipList(t *testing.T) {
+	l := newSkipList()
+	scores := make(map[string]float64)
+
+	for i := 0; i < 1000; i++ {
+		member := fmt.Sprintf("member_%d", rand.Intn(300))
+		score := float64(rand.Intn(50))
+
+		if old, ok := scores[member]; ok {
+			l.delete(member, old)
+		}
+		scores[member] = score
+		l.insert(member, score)
+	}
+
+	expect := make([]ZMember, 0, len(scores))
+	for member, score := range scores {
+		expect = append(expect, ZMember{Member: member, Score: score})
+	}
+	sort.Slice(expect, func(i, j int) bool {
+		if expect[i].Score != expect[j].Score {
+			return expect[i].Score < expect[j].Score
+		}
+		return expect[i].Member < expect[j].Member
+	})
+
+	assert.Equal(t, len(expect), l.length)
+
+	for rank, m := range expect {
+		assert.Equal(t, rank, l.rank(m.Member, m.Score))
+
+		x := l.byRank(rank)
+		assert.Equal(t, m.Member, x.member)
+	}
+
+	assert.Nil(t, l.byRank(len(expect)))
+
+	x := l.firstFrom(25)
+	for i, m := range expect {
+		if m.Score >= 25 {
+			assert.Equal(t, expect[i].Member, x.member)
+			break
+		}
+	}
+}
+
+func Benchmark_skipList_insert(b *testing.B) {
+	l := newSkipList()
+
+	for n := 0; n < b.N; n++ {
+		l.insert(fmt.Sprintf("member_%d", n), rand.Float64())
+	}
+}
@@ -4,12 +4,28 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
 	// ErrNotFound returns when a key is not
 	// found in the storage.
 	ErrNotFound = errors.New("not found")
+	// ErrWrongType returns when an operation
+	// is applied to a key holding the wrong
+	// kind of value.
+	ErrWrongType = errors.New("WRONGTYPE operation against a key holding the wrong kind of value")
+	// ErrNotInteger returns when an increment
+	// is applied to a value which is not an
+	// integer.
+	ErrNotInteger = errors.New("value is not an integer")
+	// ErrOverflow returns when an increment
+	// would overflow the integer value.
+	ErrOverflow = errors.New("increment or decrement would overflow")
+	// ErrVersionMismatch returns when compare
+	// and set is applied to a key which value
+	// has other version.
+	ErrVersionMismatch = errors.New("version mismatch")
 )
 
 // Storage represents abstraction
@@ -17,6 +33,53 @@ var (
 type Storage interface {
 	Set(ctx context.Context, key, value interface{})
 	Get(key interface{}) (value interface{}, err error)
+	View(key interface{}, consume bool, fn func(value interface{}) (interface{}, error)) (value interface{}, err error)
+	Update(key interface{}, fn func(value interface{}) (interface{}, error)) (value interface{}, err error)
+
+	HSet(ctx context.Context, key interface{}, field string, value interface{}) (created bool, err error)
+	HGet(key interface{}, field string) (value interface{}, err error)
+	HDel(key interface{}, fields ...string) (deleted int, err error)
+	HGetAll(key interface{}) (fields map[string]interface{}, err error)
+	HIncrBy(ctx context.Context, key interface{}, field string, incr int64) (value int64, err error)
+
+	SAdd(ctx context.Context, key interface{}, members ...string) (added int, err error)
+	SRem(key interface{}, members ...string) (removed int, err error)
+	SIsMember(key interface{}, member string) (ok bool, err error)
+	SMembers(key interface{}) (members []string, err error)
+	SInter(keys ...interface{}) (members []string, err error)
+	SUnion(keys ...interface{}) (members []string, err error)
+
+	ZAdd(ctx context.Context, key interface{}, members ...ZMember) (added int, err error)
+	ZRange(key interface{}, start, stop int) (members []ZMember, err error)
+	ZRangeByScore(key interface{}, min, max float64) (members []ZMember, err error)
+	ZRank(key interface{}, member string) (rank int, err error)
+
+	Info(key interface{}) (info Info, err error)
+
+	SetIf(ctx context.Context, key, value interface{}, cond Condition) (ok bool, err error)
+	Del(keys ...interface{}) (deleted int)
+	Expire(ctx context.Context, key interface{}) (err error)
+	Len() (keys int)
+	Peek(key interface{}) (value interface{}, info Info, err error)
+	CompareAndSet(ctx context.Context, key, value interface{}, version uint64) (err error)
+
+	Watch(ctx context.Context, key interface{}) (events <-chan Event)
+
+	Throttle(key interface{}, rate Rate, quantity int) (result ThrottleResult, err error)
+
+	Acquire(ctx context.Context, key interface{}) (token uint64, err error)
+	Renew(ctx context.Context, key interface{}, token uint64) (err error)
+	Release(key interface{}, token uint64) (err error)
+
+	AcquirePermit(ctx context.Context, key interface{}, limit int, ttl time.Duration) (permit uint64, err error)
+	ReleasePermit(key interface{}, permit uint64) (err error)
+
+	Namespace(name string) (keyspace Storage)
+	Flush() (deleted int)
+	Stats() (stats Stats)
+
+	SetQuota(quota Quota)
+	TrySet(ctx context.Context, key, value interface{}) (err error)
 }
 
 // New returns initialized storage
@@ -27,78 +90,315 @@ type Storage interface {
 // context done wait will be expired.
 func New() Storage {
 	m := muxMap{
-		Mutex:   &sync.Mutex{},
-		storage: make(map[interface{}]data),
+		Mutex:    &sync.Mutex{},
+		storage:  make(map[interface{}]*data),
+		watchers: make(map[interface{}]map[chan Event]struct{}),
+		stats:    make(map[string]*Stats),
+		quotas:   make(map[string]Quota),
 	}
 
 	return &m
 }
 
+type cancelKey struct{}
+
+// Expire returns context which will be done
+// after d, it is intended to be passed to the
+// storage methods to bound the key lifetime.
+// Storage releases the context timer when the
+// key is reset or consumed before it expires.
+func Expire(d time.Duration) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	return context.WithValue(ctx, cancelKey{}, cancel)
+}
+
+// release cancels context created by Expire.
+func release(ctx context.Context) {
+	if cancel, ok := ctx.Value(cancelKey{}).(context.CancelFunc); ok {
+		cancel()
+	}
+}
+
 type muxMap struct {
 	*sync.Mutex
-	storage map[interface{}]data
+	storage  map[interface{}]*data
+	watchers map[interface{}]map[chan Event]struct{}
+	// version is the last version
+	// assigned to the values.
+	version uint64
+	// stats of namespaces with keys.
+	stats map[string]*Stats
+	// quotas of limited namespaces.
+	quotas map[string]Quota
 }
 
 type data struct {
 	value interface{}
 	reset chan struct{}
+	info  Info
 }
 
 func (m *muxMap) Get(key interface{}) (interface{}, error) {
 	m.Lock()
 	defer m.Unlock()
 
-	data, ok := m.storage[key]
-	if !ok {
-		return nil, ErrNotFound
+	data, err := m.lookup(key, TypeString)
+	if err != nil {
+		return nil, err
 	}
 
-	close(data.reset)
-	delete(m.storage, key)
+	m.drop(key)
 
 	return data.value, nil
 }
 
+// Set sets value of the key, it is not
+// limited by quotas unlike TrySet.
 func (m *muxMap) Set(ctx context.Context, key, value interface{}) {
 	m.Lock()
-	{
+	m.insert(ctx, key, TypeString, value, sizeOf(value))
+	m.Unlock()
+}
+
+// View returns the result of fn applied to value stored
+// at key. When consume is true key is removed from the
+// storage, unless fn returns an error. Fn is called with
+// lock held and must not modify the passed value.
+func (m *muxMap) View(key interface{}, consume bool, fn func(value interface{}) (interface{}, error)) (interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeString)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := fn(d.value)
+	if err != nil {
+		return nil, err
+	}
+
+	if consume {
+		m.drop(key)
+	}
+
+	return value, nil
+}
+
+// Update replaces value stored at key with the result
+// of fn, key keeps its remaining lifetime. Fn is called
+// with lock held and must not modify the passed value,
+// when it returns an error value stays unchanged.
+func (m *muxMap) Update(key interface{}, fn func(value interface{}) (interface{}, error)) (interface{}, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, err := m.lookup(key, TypeString)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := fn(d.value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.grow(key, d, sizeOf(value)); err != nil {
+		return nil, err
+	}
+	d.value = value
+	d.info.Version = m.nextVersion()
+
+	m.notify(Event{Key: key, Op: OpSet, Value: value})
+
+	return value, nil
+}
+
+// lookup returns entry stored at key and marks it
+// accessed, entry must hold value of the kind.
+// Must be called with lock held.
+func (m *muxMap) lookup(key interface{}, kind Type) (*data, error) {
+	d, ok := m.storage[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if d.info.Type != kind {
+		return nil, ErrWrongType
+	}
+
+	d.info.AccessedAt = now()
+
+	return d, nil
+}
+
+// lookupOrPut returns entry stored at key, when key does
+// not exist empty value is stored and ctx bounds its
+// lifetime, otherwise ctx is released. Created entries
+// are removed with stop when they can't grow.
+// Must be called with lock held.
+func (m *muxMap) lookupOrPut(ctx context.Context, key interface{}, kind Type, value interface{}) (d *data, created bool, err error) {
+	d, err = m.lookup(key, kind)
+	if err == ErrNotFound {
+		d, err = m.put(ctx, key, kind, value, 0)
+		return d, err == nil, err
+	}
+
+	release(ctx)
+
+	return d, false, err
+}
+
+// put stores value of the kind and size under the key like
+// insert when it fits quota of the key namespace, otherwise
+// ctx is released and the storage is left unchanged.
+// Must be called with lock held.
+func (m *muxMap) put(ctx context.Context, key interface{}, kind Type, value interface{}, size int) (*data, error) {
+	if err := m.admit(ctx, key, size); err != nil {
+		release(ctx)
+		return nil, err
+	}
+
+	return m.insert(ctx, key, kind, value, size), nil
+}
+
+// insert stores value of the kind and size under the key and
+// removes it from the storage when ctx is done. Previous value
+// of the key is replaced. Must be called with lock held.
+func (m *muxMap) insert(ctx context.Context, key interface{}, kind Type, value interface{}, size int) *data {
+	if _, ok := m.storage[key]; ok {
+		// signal reset channel to
+		// prevent key deletion on
+		// <- ctx.Done().
+		m.stop(key)
+	}
+
+	reset := make(chan struct{})
+	created := now()
+	expires, _ := ctx.Deadline()
+
+	d := data{
+		value: value,
+		reset: reset,
+		info: Info{
+			Type:       kind,
+			CreatedAt:  created,
+			AccessedAt: created,
+			ExpiresAt:  expires,
+			Version:    m.nextVersion(),
+		},
+	}
+	m.storage[key] = &d
+	m.usage(key).Keys++
+
+	go m.watch(ctx, key, reset)
+
+	m.resize(key, &d, size)
+
+	if kind == TypeString {
+		m.notify(Event{Key: key, Op: OpSet, Value: value})
+	}
+
+	return &d
+}
+
+// watch removes key from the storage when ctx is done,
+// unless its entry is reset or dropped before.
+func (m *muxMap) watch(ctx context.Context, key interface{}, reset chan struct{}) {
+	select {
+	case <-ctx.Done():
+		m.Lock()
+		// key could be reset at the same time
+		// when ctx is done, so only own entry
+		// is removed.
 		d, ok := m.storage[key]
-		if ok {
-			// signal reset channel to
-			// prevent key deletion on
-			// <- ctx.Done().
-			close(d.reset)
+		own := ok && d.reset == reset
+		if own {
+			m.drop(key)
 		}
+		m.Unlock()
 
-		reset := make(chan struct{})
-
-		m.storage[key] = data{
-			value: value,
-			reset: reset,
+		if own {
+			ctxDoneCall()
 		}
+	case <-reset:
+		// Prevent context leaks.
+		release(ctx)
+	}
+}
 
-		go func() {
-			select {
-			case <-ctx.Done():
-				m.Lock()
-				{
-					d, ok := m.storage[key]
-					if ok {
-						close(d.reset)
-					}
-					delete(m.storage, key)
-				}
-				m.Unlock()
-				ctxDoneCall()
-			case <-reset:
-				// Prevent context leaks.
-				_, cancel := context.WithCancel(ctx)
-				cancel()
-				return
-			}
-		}()
+// nextVersion returns version of the changed
+// value. Must be called with lock held.
+func (m *muxMap) nextVersion() uint64 {
+	m.version++
+	return m.version
+}
+
+// drop removes key from the storage, stops its expiration
+// wait and notifies watchers. Must be called with lock held.
+func (m *muxMap) drop(key interface{}) {
+	if _, ok := m.storage[key]; !ok {
+		return
 	}
-	m.Unlock()
+
+	m.stop(key)
+	m.notify(Event{Key: key, Op: OpDel})
+}
+
+// stop removes key from the storage and stops its
+// expiration wait. Must be called with lock held.
+func (m *muxMap) stop(key interface{}) {
+	d, ok := m.storage[key]
+	if !ok {
+		return
+	}
+
+	close(d.reset)
+	delete(m.storage, key)
+
+	ns := namespaceOf(key)
+	stats := m.stats[ns]
+	stats.Keys--
+	stats.Bytes -= d.info.Size
+	if stats.Keys == 0 {
+		delete(m.stats, ns)
+	}
+}
+
+// grow sets size of the entry stored at key like resize
+// when it fits bytes quota of the key namespace, values
+// may always shrink. Must be called with lock held.
+func (m *muxMap) grow(key interface{}, d *data, size int) error {
+	q, ok := m.quotas[namespaceOf(key)]
+	if ok && q.MaxBytes > 0 && size > d.info.Size && m.usage(key).Bytes+size-d.info.Size > q.MaxBytes {
+		return ErrBytesQuota
+	}
+
+	m.resize(key, d, size)
+
+	return nil
+}
+
+// resize sets size of the entry stored at key and
+// accounts it in stats of the key namespace. Must
+// be called with lock held.
+func (m *muxMap) resize(key interface{}, d *data, size int) {
+	m.usage(key).Bytes += size - d.info.Size
+	d.info.Size = size
+}
+
+// usage returns stats of the key namespace.
+// Must be called with lock held.
+func (m *muxMap) usage(key interface{}) *Stats {
+	ns := namespaceOf(key)
+
+	stats, ok := m.stats[ns]
+	if !ok {
+		stats = &Stats{}
+		m.stats[ns] = stats
+	}
+
+	return stats
 }
 
 var ctxDoneCall = func() {}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -107,3 +108,91 @@ func Benchmark_muxMap_SetGet(b *testing.B) {
 		s.Get(key)
 	}
 }
+
+func Test_muxMap_Update(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key is not defined, should return not found error.")
+		{
+			_, err := s.Update("update0", func(v interface{}) (interface{}, error) {
+				return v, nil
+			})
+			assert.Equal(t, ErrNotFound, err)
+		}
+
+		t.Log("\t Test: 1\t When key is present, should replace value and keep its lifetime.")
+		{
+			k := "update1"
+			ctx := Expire(time.Minute)
+			s.Set(ctx, k, "value")
+			before, _ := s.Info(k)
+
+			value, err := s.Update(k, func(v interface{}) (interface{}, error) {
+				return v.(string) + "!", nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, "value!", value)
+
+			after, _ := s.Info(k)
+			assert.Equal(t, before.ExpiresAt, after.ExpiresAt)
+			assert.Equal(t, 6, after.Size)
+		}
+
+		t.Log("\t Test: 2\t When update fails, should keep previous value.")
+		{
+			k := "update2"
+			s.Set(context.Background(), k, "value")
+
+			_, err := s.Update(k, func(v interface{}) (interface{}, error) {
+				return nil, ErrNotInteger
+			})
+			assert.Equal(t, ErrNotInteger, err)
+
+			value, _ := s.Get(k)
+			assert.Equal(t, "value", value)
+		}
+	}
+}
+
+func Test_muxMap_View(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key is not consumed, should keep it in the storage.")
+		{
+			k := "view0"
+			s.Set(context.Background(), k, "value")
+
+			value, err := s.View(k, false, func(v interface{}) (interface{}, error) {
+				return v.(string)[:1], nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, "v", value)
+
+			value, err = s.Get(k)
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+		}
+
+		t.Log("\t Test: 1\t When key is consumed, should remove it only if fn succeeds.")
+		{
+			k := "view1"
+			s.Set(context.Background(), k, "value")
+
+			_, err := s.View(k, true, func(v interface{}) (interface{}, error) {
+				return nil, ErrNotFound
+			})
+			assert.Equal(t, ErrNotFound, err)
+
+			value, err := s.View(k, true, func(v interface{}) (interface{}, error) {
+				return v, nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, "value", value)
+
+			_, err = s.Get(k)
+			assert.Equal(t, ErrNotFound, err)
+		}
+	}
+}
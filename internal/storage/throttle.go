@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidRate returns when rate of the throttle is
+// not positive, allows more than one request per
+// nanosecond or its burst spans over MaxBurstSpan.
+var ErrInvalidRate = errors.New("invalid rate")
+
+// MaxBurstSpan limits time taken by the
+// burst of requests at the rate, so the
+// arrival times don't overflow.
+const MaxBurstSpan = 100 * 365 * 24 * time.Hour
+
+// Valid reports whether the rate may be throttled.
+func (r Rate) Valid() bool {
+	if r.Count <= 0 || r.Period <= 0 || r.Burst <= 0 {
+		return false
+	}
+
+	interval := r.Period / time.Duration(r.Count)
+	return interval > 0 && int64(r.Burst) <= int64(MaxBurstSpan/interval)
+}
+
+// Rate allows Count requests per Period,
+// up to Burst of them at once.
+type Rate struct {
+	Count  int
+	Period time.Duration
+	Burst  int
+}
+
+// ThrottleResult describes requests checked by Throttle.
+type ThrottleResult struct {
+	Allowed bool
+	// Remaining is number of requests
+	// which would be allowed at once.
+	Remaining int
+	// ResetAfter is time until all
+	// the burst would be allowed.
+	ResetAfter time.Duration
+	// RetryAfter is time until the requests would
+	// be allowed, zero when allowed or never.
+	RetryAfter time.Duration
+}
+
+// Throttle checks quantity of requests against the rate
+// with the generic cell rate algorithm and counts them
+// when allowed. The key holds theoretical arrival time
+// of the next request and expires when all the burst
+// would be allowed again. Quantity over the burst is
+// never allowed.
+func (m *muxMap) Throttle(key interface{}, rate Rate, quantity int) (ThrottleResult, error) {
+	if !rate.Valid() || quantity < 0 {
+		return ThrottleResult{}, ErrInvalidRate
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	t := now()
+	interval := rate.Period / time.Duration(rate.Count)
+	tolerance := interval * time.Duration(rate.Burst)
+
+	tat := t
+	d, err := m.lookup(key, TypeThrottle)
+	switch err {
+	case nil:
+		if stored := d.value.(time.Time); stored.After(t) {
+			tat = stored
+		}
+	case ErrNotFound:
+	default:
+		return ThrottleResult{}, err
+	}
+
+	if quantity > rate.Burst {
+		return ThrottleResult{
+			Remaining:  remaining(t, tat, tolerance, interval),
+			ResetAfter: tat.Sub(t),
+		}, nil
+	}
+
+	next := tat.Add(interval * time.Duration(quantity))
+	if allowAt := next.Add(-tolerance); allowAt.After(t) {
+		return ThrottleResult{
+			Remaining:  remaining(t, tat, tolerance, interval),
+			ResetAfter: tat.Sub(t),
+			RetryAfter: allowAt.Sub(t),
+		}, nil
+	}
+
+	if quantity > 0 {
+		if _, err := m.put(Expire(next.Sub(t)), key, TypeThrottle, next, sizeOf(next)); err != nil {
+			return ThrottleResult{}, err
+		}
+	}
+
+	return ThrottleResult{
+		Allowed:    true,
+		Remaining:  remaining(t, next, tolerance, interval),
+		ResetAfter: next.Sub(t),
+	}, nil
+}
+
+// remaining returns number of requests
+// allowed at t with arrival time tat.
+func remaining(t, tat time.Time, tolerance, interval time.Duration) int {
+	return int((t.Sub(tat) + tolerance) / interval)
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Throttle(t *testing.T) {
+	start := time.Now()
+	at := start
+	now = func() time.Time { return at }
+	defer func() { now = time.Now }()
+
+	s := New()
+	rate := Rate{Count: 1, Period: time.Second, Burst: 2}
+
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When requests fit the burst, should allow them.")
+		{
+			res, err := s.Throttle("key", rate, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, ThrottleResult{Allowed: true, Remaining: 1, ResetAfter: time.Second}, res)
+
+			res, err = s.Throttle("key", rate, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, ThrottleResult{Allowed: true, Remaining: 0, ResetAfter: 2 * time.Second}, res)
+		}
+
+		t.Log("\t Test: 1\t When burst is exhausted, should deny requests until they fit.")
+		{
+			res, err := s.Throttle("key", rate, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, ThrottleResult{ResetAfter: 2 * time.Second, RetryAfter: time.Second}, res)
+
+			at = start.Add(time.Second)
+			res, err = s.Throttle("key", rate, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, ThrottleResult{Allowed: true, Remaining: 0, ResetAfter: 2 * time.Second}, res)
+		}
+
+		t.Log("\t Test: 2\t When quantity exceeds the burst, should never allow it.")
+		{
+			res, err := s.Throttle("other", rate, 3)
+			assert.Nil(t, err)
+			assert.False(t, res.Allowed)
+			assert.Equal(t, time.Duration(0), res.RetryAfter)
+		}
+
+		t.Log("\t Test: 3\t When rate is invalid or key holds other kind, should fail.")
+		{
+			_, err := s.Throttle("key", Rate{Count: 1, Period: time.Second}, 1)
+			assert.Equal(t, ErrInvalidRate, err)
+
+			// Interval of the rate would be zero.
+			_, err = s.Throttle("key", Rate{Count: 2, Period: time.Nanosecond, Burst: 1}, 1)
+			assert.Equal(t, ErrInvalidRate, err)
+
+			// Tolerance of the burst would overflow.
+			_, err = s.Throttle("key", Rate{Count: 1, Period: time.Hour, Burst: 1e9}, 1)
+			assert.Equal(t, ErrInvalidRate, err)
+
+			s.Set(context.Background(), "string", "value")
+			_, err = s.Throttle("string", rate, 1)
+			assert.Equal(t, ErrWrongType, err)
+		}
+
+		t.Log("\t Test: 4\t When burst would be allowed again, should expire the key.")
+		{
+			info, err := s.Info("key")
+			assert.Nil(t, err)
+			assert.Equal(t, TypeThrottle, info.Type)
+			assert.WithinDuration(t, time.Now().Add(2*time.Second), info.ExpiresAt, time.Second)
+		}
+
+		t.Log("\t Test: 5\t When keys hold limit state, should count them in stats.")
+		{
+			stats := s.Stats()
+			assert.Equal(t, 2, stats.Keys)
+			assert.Equal(t, sizeOf("value")+sizeOf(time.Time{}), stats.Bytes)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import "context"
+
+// watchBuffer is number of events buffered for
+// the watcher before it is considered lagging.
+const watchBuffer = 64
+
+// Op is a kind of the key change.
+type Op int
+
+const (
+	// OpSet reports plain value set or updated.
+	OpSet Op = iota + 1
+	// OpDel reports key of any kind removed,
+	// consumed or expired.
+	OpDel
+)
+
+// Event describes change of the key,
+// Value is set only for OpSet.
+type Event struct {
+	Key   interface{}
+	Op    Op
+	Value interface{}
+}
+
+// Watch returns channel of changes of the key. The channel
+// is closed when ctx is done or when the watcher does not
+// keep up with the changes, so events would be lost; ctx is
+// not done in the latter case.
+func (m *muxMap) Watch(ctx context.Context, key interface{}) <-chan Event {
+	events := make(chan Event, watchBuffer)
+
+	m.Lock()
+	watchers, ok := m.watchers[key]
+	if !ok {
+		watchers = make(map[chan Event]struct{})
+		m.watchers[key] = watchers
+	}
+	watchers[events] = struct{}{}
+	m.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		m.Lock()
+		m.unwatch(key, events)
+		m.Unlock()
+	}()
+
+	return events
+}
+
+// notify sends event to watchers of its key, lagging
+// ones are removed. Key of the sent event is the one
+// of its namespace. Must be called with lock held.
+func (m *muxMap) notify(e Event) {
+	sent := e
+	sent.Key = keyOf(e.Key)
+
+	for events := range m.watchers[e.Key] {
+		select {
+		case events <- sent:
+		default:
+			m.unwatch(e.Key, events)
+		}
+	}
+}
+
+// unwatch removes watcher of the key and closes its
+// channel, when it is registered. Must be called with
+// lock held.
+func (m *muxMap) unwatch(key interface{}, events chan Event) {
+	watchers := m.watchers[key]
+	if _, ok := watchers[events]; !ok {
+		return
+	}
+
+	delete(watchers, events)
+	close(events)
+
+	if len(watchers) == 0 {
+		delete(m.watchers, key)
+	}
+}
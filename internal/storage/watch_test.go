@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_muxMap_Watch(t *testing.T) {
+	s := New()
+	t.Log("Given initialized storage.")
+	{
+		t.Log("\t Test: 0\t When key is set, updated and removed, should report changes.")
+		{
+			k := "watch0"
+			ctx, cancel := context.WithCancel(context.Background())
+			events := s.Watch(ctx, k)
+
+			s.Set(context.Background(), k, "a")
+			s.Set(context.Background(), k, "b")
+			s.Update(k, func(interface{}) (interface{}, error) { return "c", nil })
+			s.Get(k)
+			s.Set(context.Background(), "other", "value")
+
+			assert.Equal(t, Event{Key: k, Op: OpSet, Value: "a"}, <-events)
+			assert.Equal(t, Event{Key: k, Op: OpSet, Value: "b"}, <-events)
+			assert.Equal(t, Event{Key: k, Op: OpSet, Value: "c"}, <-events)
+			assert.Equal(t, Event{Key: k, Op: OpDel}, <-events)
+
+			cancel()
+			_, ok := <-events
+			assert.False(t, ok)
+		}
+
+		t.Log("\t Test: 1\t When key expires, should report removal.")
+		{
+			d := make(chan struct{})
+			ctxDoneCall = func() {
+				d <- struct{}{}
+			}
+			k := "watch1"
+			events := s.Watch(context.Background(), k)
+
+			s.Set(Expire(time.Millisecond), k, "a")
+			<-d
+			ctxDoneCall = func() {}
+
+			assert.Equal(t, OpSet, (<-events).Op)
+			assert.Equal(t, Event{Key: k, Op: OpDel}, <-events)
+		}
+
+		t.Log("\t Test: 2\t When watcher lags, should close its channel.")
+		{
+			k := "watch2"
+			events := s.Watch(context.Background(), k)
+
+			for i := 0; i <= watchBuffer; i++ {
+				s.Set(context.Background(), k, i)
+			}
+
+			var n int
+			for range events {
+				n++
+			}
+			assert.Equal(t, watchBuffer, n)
+		}
+	}
+}
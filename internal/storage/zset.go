@@ -0,0 +1,164 @@
+package storage
+
+import "context"
+
+// ZMember is a member of the sorted set.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// zset is a value type which holds unique
+// members ordered by their scores.
+type zset struct {
+	scores map[string]float64
+	list   *skipList
+}
+
+func newZSet() *zset {
+	return &zset{
+		scores: make(map[string]float64),
+		list:   newSkipList(),
+	}
+}
+
+// ZAdd adds members to the sorted set stored at key
+// or updates scores of existing ones and returns the
+// number of added members. When key does not exist
+// new sorted set is created and ctx bounds its
+// lifetime, otherwise ctx is ignored.
+func (m *muxMap) ZAdd(ctx context.Context, key interface{}, members ...ZMember) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	d, created, err := m.lookupOrPut(ctx, key, TypeZSet, newZSet())
+	if err != nil {
+		return 0, err
+	}
+
+	z := d.value.(*zset)
+
+	// Members are added only when
+	// all of them fit the quota.
+	size := d.info.Size
+	counted := make(map[string]bool)
+	for _, member := range members {
+		if _, ok := z.scores[member.Member]; !ok && !counted[member.Member] {
+			counted[member.Member] = true
+			size += len(member.Member) + sizeOf(member.Score)
+		}
+	}
+
+	if err := m.grow(key, d, size); err != nil {
+		if created {
+			m.stop(key)
+		}
+		return 0, err
+	}
+
+	var added int
+	for _, member := range members {
+		score, ok := z.scores[member.Member]
+		switch {
+		case !ok:
+			added++
+		case score == member.Score:
+			continue
+		default:
+			z.list.delete(member.Member, score)
+		}
+
+		z.scores[member.Member] = member.Score
+		z.list.insert(member.Member, member.Score)
+	}
+
+	return added, nil
+}
+
+// ZRange returns members of the sorted set with 0 based
+// ranks from start to stop inclusive, negative ranks
+// are counted from the end of the set.
+func (m *muxMap) ZRange(key interface{}, start, stop int) ([]ZMember, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	z, err := m.lookupZSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	length := z.list.length
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+
+	members := []ZMember{}
+	if start > stop {
+		return members, nil
+	}
+
+	for x := z.list.byRank(start); x != nil && start <= stop; x = x.levels[0].next {
+		members = append(members, ZMember{Member: x.member, Score: x.score})
+		start++
+	}
+
+	return members, nil
+}
+
+// ZRangeByScore returns members of the sorted set
+// with scores between min and max inclusive.
+func (m *muxMap) ZRangeByScore(key interface{}, min, max float64) ([]ZMember, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	z, err := m.lookupZSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []ZMember{}
+	for x := z.list.firstFrom(min); x != nil && x.score <= max; x = x.levels[0].next {
+		members = append(members, ZMember{Member: x.member, Score: x.score})
+	}
+
+	return members, nil
+}
+
+// ZRank returns 0 based rank of the member
+// in the sorted set ordered by scores.
+func (m *muxMap) ZRank(key interface{}, member string) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	z, err := m.lookupZSet(key)
+	if err != nil {
+		return 0, err
+	}
+
+	score, ok := z.scores[member]
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	return z.list.rank(member, score), nil
+}
+
+// lookupZSet returns sorted set stored at key.
+// Must be called with lock held.
+func (m *muxMap) lookupZSet(key interface{}) (*zset, error) {
+	d, err := m.lookup(key, TypeZSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.value.(*zset), nil
+}
@@ -0,0 +1,81 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+)
+
+// Rules are server configured constraints of keys
+// and values shared by all services. Zero value only
+// requires keys to be not blank.
+type Rules struct {
+	// MaxKeyLength limits key length in
+	// characters, zero means no limit.
+	MaxKeyLength int
+	// KeyPattern, when set, must match keys.
+	KeyPattern *regexp.Regexp
+	// ReservedPrefixes are prefixes keys must
+	// not start with.
+	ReservedPrefixes []string
+	// MaxValueSize limits size of JSON encoded
+	// value in bytes, zero means no limit.
+	MaxValueSize int
+}
+
+// Key validates the key and returns the
+// error of the first failed rule.
+func (r Rules) Key(key string) error {
+	rules := []validation.Rule{validation.Required}
+	if r.MaxKeyLength > 0 {
+		rules = append(rules, validation.RuneLength(0, r.MaxKeyLength))
+	}
+	if r.KeyPattern != nil {
+		rules = append(rules, validation.Match(r.KeyPattern))
+	}
+	rules = append(rules, reserved(r.ReservedPrefixes))
+
+	return validation.Validate(key, rules...)
+}
+
+// Value validates size of the value, raw
+// bytes are measured without encoding.
+func (r Rules) Value(value interface{}) error {
+	if r.MaxValueSize <= 0 {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(value); err != nil {
+			return errors.Wrap(err, "marshal value")
+		}
+	}
+
+	if len(b) > r.MaxValueSize {
+		return fmt.Errorf("the size must be no more than %d bytes", r.MaxValueSize)
+	}
+
+	return nil
+}
+
+// reserved is a rule which rejects
+// keys with the reserved prefixes.
+type reserved []string
+
+// Validate implements the validation.Rule interface.
+func (prefixes reserved) Validate(value interface{}) error {
+	key, _ := value.(string)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return fmt.Errorf("must not start with reserved prefix %q", prefix)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package validate
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRules_Key(t *testing.T) {
+	rules := Rules{
+		MaxKeyLength:     8,
+		KeyPattern:       regexp.MustCompile(`^[a-z_:]+$`),
+		ReservedPrefixes: []string{"__"},
+	}
+
+	tests := []struct {
+		name   string
+		rules  Rules
+		key    string
+		expect string
+	}{
+		{
+			name: "zero rules",
+			key:  "Any key at all",
+		},
+		{
+			name:   "zero rules blank",
+			key:    "",
+			expect: "cannot be blank",
+		},
+		{
+			name:  "valid",
+			rules: rules,
+			key:   "user:a",
+		},
+		{
+			name:   "too long",
+			rules:  rules,
+			key:    "user:abcd",
+			expect: "the length must be no more than 8",
+		},
+		{
+			name:   "pattern",
+			rules:  rules,
+			key:    "user:1",
+			expect: "must be in a valid format",
+		},
+		{
+			name:   "reserved",
+			rules:  rules,
+			key:    "__lock",
+			expect: `must not start with reserved prefix "__"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.rules.Key(tt.key)
+			if tt.expect == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.expect)
+		})
+	}
+}
+
+func TestRules_Value(t *testing.T) {
+	rules := Rules{MaxValueSize: 7}
+
+	assert.Nil(t, Rules{}.Value("any value at all"))
+	assert.Nil(t, rules.Value("value"))
+	assert.EqualError(t, rules.Value("values"), "the size must be no more than 7 bytes")
+	assert.Nil(t, rules.Value([]byte("1234567")))
+	assert.EqualError(t, rules.Value([]byte("12345678")), "the size must be no more than 7 bytes")
+	assert.Error(t, rules.Value(func() {}))
+}
@@ -0,0 +1,57 @@
+package zsets
+
+import (
+	"net/http"
+
+	"github.com/romanyx/integral_db/internal/responses"
+)
+
+// NewZAddHandler returns handler for sorted set members add requests.
+func NewZAddHandler(srv ZSetter) http.HandlerFunc {
+	return handler(srv.ZAdd)
+}
+
+// NewZRangeHandler returns handler for sorted set range by rank requests.
+func NewZRangeHandler(srv ZSetter) http.HandlerFunc {
+	return handler(srv.ZRange)
+}
+
+// NewZRangeByScoreHandler returns handler for sorted set range by score requests.
+func NewZRangeByScoreHandler(srv ZSetter) http.HandlerFunc {
+	return handler(srv.ZRangeByScore)
+}
+
+// NewZRankHandler returns handler for sorted set member rank requests.
+func NewZRankHandler(srv ZSetter) http.HandlerFunc {
+	return handler(srv.ZRank)
+}
+
+func handler(serve func(*http.Request, *response) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+
+		if err := serve(r, &resp); err != nil {
+			responses.Error(w, r, err)
+			return
+		}
+
+		responses.OK(w, r, resp)
+	}
+}
+
+type response struct {
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type addedData struct {
+	Added int `json:"added"`
+}
+
+type membersData struct {
+	Members []member `json:"members"`
+}
+
+type rankData struct {
+	Rank int `json:"rank"`
+}
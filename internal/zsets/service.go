@@ -0,0 +1,347 @@
+package zsets
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/decode"
+	"github.com/romanyx/integral_db/internal/responses"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/romanyx/integral_db/internal/validate"
+)
+
+const (
+	membersAddedMessage            = "members added"
+	membersFoundMessage            = "members found"
+	rankFoundMessage               = "rank found"
+	validationErrorResponseMessage = "you have validation errors"
+	notFoundMessage                = "key or member not found"
+	wrongTypeMessage               = "key holds the wrong kind of value"
+)
+
+// ZSetter service for sorted set requests.
+type ZSetter interface {
+	ZAdd(r *http.Request, resp *response) error
+	ZRange(r *http.Request, resp *response) error
+	ZRangeByScore(r *http.Request, resp *response) error
+	ZRank(r *http.Request, resp *response) error
+}
+
+// NewService returns initialized service,
+// keys are validated with the rules.
+func NewService(storage storage.Storage, keyLiveTime time.Duration, rules validate.Rules) ZSetter {
+	srv := muxMap{
+		decoder: jsonDecoder{},
+		validater: ozzoValidater{
+			rules: rules,
+		},
+		zsetter: &sZSetter{
+			storage: storage,
+		},
+		keyLiveTime: keyLiveTime,
+	}
+
+	return &srv
+}
+
+type muxMap struct {
+	decoder
+	validater
+	zsetter
+	keyLiveTime time.Duration
+}
+
+type request struct {
+	Key     string   `json:"key"`
+	Member  string   `json:"member"`
+	Members []member `json:"members"`
+	Start   int      `json:"start"`
+	Stop    int      `json:"stop"`
+	Min     float64  `json:"min"`
+	Max     float64  `json:"max"`
+}
+
+type member struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+type decoder interface {
+	Decode(*http.Request, *request) error
+}
+
+type validater interface {
+	Validate(r request, required ...string) error
+}
+
+type zsetter interface {
+	ZAdd(ctx context.Context, key string, members ...member) (int, error)
+	ZRange(key string, start, stop int) ([]member, error)
+	ZRangeByScore(key string, min, max float64) ([]member, error)
+	ZRank(key, member string) (int, error)
+}
+
+func (s muxMap) ZAdd(r *http.Request, resp *response) error {
+	req, err := s.request(r, "members")
+	if err != nil {
+		return err
+	}
+
+	added, err := s.zsetter.ZAdd(storage.Expire(s.keyLiveTime), req.Key, req.Members...)
+	if err != nil {
+		return errors.Wrap(err, "zadd failed")
+	}
+
+	resp.Message = membersAddedMessage
+	resp.Data = addedData{Added: added}
+
+	return nil
+}
+
+func (s muxMap) ZRange(r *http.Request, resp *response) error {
+	req, err := s.request(r)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.zsetter.ZRange(req.Key, req.Start, req.Stop)
+	if err != nil {
+		return errors.Wrap(err, "zrange failed")
+	}
+
+	resp.Message = membersFoundMessage
+	resp.Data = membersData{Members: members}
+
+	return nil
+}
+
+func (s muxMap) ZRangeByScore(r *http.Request, resp *response) error {
+	req, err := s.request(r)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.zsetter.ZRangeByScore(req.Key, req.Min, req.Max)
+	if err != nil {
+		return errors.Wrap(err, "zrangebyscore failed")
+	}
+
+	resp.Message = membersFoundMessage
+	resp.Data = membersData{Members: members}
+
+	return nil
+}
+
+func (s muxMap) ZRank(r *http.Request, resp *response) error {
+	req, err := s.request(r, "member")
+	if err != nil {
+		return err
+	}
+
+	rank, err := s.zsetter.ZRank(req.Key, req.Member)
+	if err != nil {
+		return errors.Wrap(err, "zrank failed")
+	}
+
+	resp.Message = rankFoundMessage
+	resp.Data = rankData{Rank: rank}
+
+	return nil
+}
+
+// request decodes and validates request, key is
+// always required in addition to passed fields.
+// Omitted range bounds cover the whole set.
+func (s muxMap) request(r *http.Request, required ...string) (request, error) {
+	req := request{
+		Stop: -1,
+		Min:  math.Inf(-1),
+		Max:  math.Inf(1),
+	}
+
+	if err := s.decoder.Decode(r, &req); err != nil {
+		return req, errors.Wrap(err, "decode failed")
+	}
+
+	if err := s.validater.Validate(req, required...); err != nil {
+		return req, errors.Wrap(err, "validation failed")
+	}
+
+	return req, nil
+}
+
+type jsonDecoder struct{}
+
+func (d jsonDecoder) Decode(r *http.Request, req *request) error {
+	return decode.Request(r, req)
+}
+
+type ozzoValidater struct {
+	rules validate.Rules
+}
+
+func (v ozzoValidater) Validate(r request, required ...string) error {
+	validatationError := validationErrorResponse{Message: validationErrorResponseMessage}
+
+	values := map[string]interface{}{
+		"member":  r.Member,
+		"members": r.Members,
+	}
+
+	for _, field := range append([]string{"key"}, required...) {
+		var err error
+		switch field {
+		case "key":
+			err = v.rules.Key(r.Key)
+		default:
+			err = validation.Validate(values[field], validation.Required)
+		}
+
+		if err != nil {
+			validatationError.Errors = append(validatationError.Errors,
+				validationError{Field: field, Message: err.Error()},
+			)
+		}
+	}
+
+	if len(validatationError.Errors) > 0 {
+		return validatationError
+	}
+
+	return nil
+}
+
+type sZSetter struct {
+	storage storage.Storage
+}
+
+func (s *sZSetter) ZAdd(ctx context.Context, key string, members ...member) (int, error) {
+	zmembers := make([]storage.ZMember, len(members))
+	for i, m := range members {
+		zmembers[i] = storage.ZMember{Member: m.Member, Score: m.Score}
+	}
+
+	added, err := s.storage.ZAdd(ctx, key, zmembers...)
+	return added, storageError(err)
+}
+
+func (s *sZSetter) ZRange(key string, start, stop int) ([]member, error) {
+	zmembers, err := s.storage.ZRange(key, start, stop)
+	return fromZMembers(zmembers), storageError(err)
+}
+
+func (s *sZSetter) ZRangeByScore(key string, min, max float64) ([]member, error) {
+	zmembers, err := s.storage.ZRangeByScore(key, min, max)
+	return fromZMembers(zmembers), storageError(err)
+}
+
+func (s *sZSetter) ZRank(key, member string) (int, error) {
+	rank, err := s.storage.ZRank(key, member)
+	return rank, storageError(err)
+}
+
+func fromZMembers(zmembers []storage.ZMember) []member {
+	if zmembers == nil {
+		return nil
+	}
+
+	members := make([]member, len(zmembers))
+	for i, m := range zmembers {
+		members[i] = member{Member: m.Member, Score: m.Score}
+	}
+
+	return members
+}
+
+// storageError converts storage errors
+// to the response errors.
+func storageError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case storage.ErrNotFound:
+		return notFoundResponse{Message: notFoundMessage}
+	case storage.ErrWrongType:
+		return wrongTypeResponse{Message: wrongTypeMessage}
+	case storage.ErrKeysQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeKeysQuotaExceeded}
+	case storage.ErrBytesQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeBytesQuotaExceeded}
+	case storage.ErrTTLQuota:
+		return quotaExceededResponse{Message: err.Error(), code: responses.CodeTTLQuotaExceeded}
+	default:
+		return err
+	}
+}
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r notFoundResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r notFoundResponse) Code() responses.Code {
+	return responses.CodeKeyNotFound
+}
+
+type quotaExceededResponse struct {
+	Message string `json:"message"`
+	code    responses.Code
+}
+
+// Error implements the error interface.
+func (r quotaExceededResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r quotaExceededResponse) Code() responses.Code {
+	return r.code
+}
+
+type wrongTypeResponse struct {
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r wrongTypeResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r wrongTypeResponse) Code() responses.Code {
+	return responses.CodeWrongType
+}
+
+type validationErrorResponse struct {
+	Message string            `json:"message"`
+	Errors  []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (r validationErrorResponse) Error() string {
+	return r.Message
+}
+
+// Code implements the responses.Coder interface.
+func (r validationErrorResponse) Code() responses.Code {
+	return responses.CodeValidationFailed
+}
+
+// InvalidFields implements the responses.Invalid interface.
+func (r validationErrorResponse) InvalidFields() interface{} {
+	return r.Errors
+}
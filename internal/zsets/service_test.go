@@ -0,0 +1,233 @@
+package zsets
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/romanyx/integral_db/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type decoderFunc func(*http.Request, *request) error
+
+func (f decoderFunc) Decode(r *http.Request, m *request) error {
+	return f(r, m)
+}
+
+type validaterFunc func(request, ...string) error
+
+func (f validaterFunc) Validate(r request, required ...string) error {
+	return f(r, required...)
+}
+
+type zsetterMock struct {
+	err error
+}
+
+func (z zsetterMock) ZAdd(context.Context, string, ...member) (int, error) {
+	return 1, z.err
+}
+
+func (z zsetterMock) ZRange(string, int, int) ([]member, error) {
+	return []member{{Member: "a", Score: 1}}, z.err
+}
+
+func (z zsetterMock) ZRangeByScore(string, float64, float64) ([]member, error) {
+	return []member{{Member: "b", Score: 2}}, z.err
+}
+
+func (z zsetterMock) ZRank(string, string) (int, error) {
+	return 3, z.err
+}
+
+func Test_muxMap(t *testing.T) {
+	ok := func(*http.Request, *request) error { return nil }
+	valid := func(request, ...string) error { return nil }
+	mockErr := errors.New("mock error")
+
+	tests := []struct {
+		name         string
+		call         func(ZSetter, *http.Request, *response) error
+		decodeFunc   func(*http.Request, *request) error
+		validateFunc func(request, ...string) error
+		zsetterErr   error
+		wantErr      bool
+		expect       response
+	}{
+		{
+			name: "decoder error",
+			call: ZSetter.ZAdd,
+			decodeFunc: func(*http.Request, *request) error {
+				return mockErr
+			},
+			wantErr: true,
+		},
+		{
+			name:       "validater error",
+			call:       ZSetter.ZRank,
+			decodeFunc: ok,
+			validateFunc: func(request, ...string) error {
+				return mockErr
+			},
+			wantErr: true,
+		},
+		{
+			name:         "zsetter error",
+			call:         ZSetter.ZRange,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			zsetterErr:   mockErr,
+			wantErr:      true,
+		},
+		{
+			name:         "zadd",
+			call:         ZSetter.ZAdd,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members added", Data: addedData{Added: 1}},
+		},
+		{
+			name:         "zrange",
+			call:         ZSetter.ZRange,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members found", Data: membersData{Members: []member{{Member: "a", Score: 1}}}},
+		},
+		{
+			name:         "zrangebyscore",
+			call:         ZSetter.ZRangeByScore,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "members found", Data: membersData{Members: []member{{Member: "b", Score: 2}}}},
+		},
+		{
+			name:         "zrank",
+			call:         ZSetter.ZRank,
+			decodeFunc:   ok,
+			validateFunc: valid,
+			expect:       response{Message: "rank found", Data: rankData{Rank: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := muxMap{
+				decoder:   decoderFunc(tt.decodeFunc),
+				validater: validaterFunc(tt.validateFunc),
+				zsetter:   zsetterMock{err: tt.zsetterErr},
+			}
+
+			var got response
+			req := httptest.NewRequest("POST", "http://any", nil)
+			err := tt.call(s, req, &got)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, got)
+			}
+		})
+	}
+}
+
+func Test_muxMap_request(t *testing.T) {
+	s := muxMap{
+		decoder:   jsonDecoder{},
+		validater: ozzoValidater{},
+	}
+
+	req := httptest.NewRequest("GET", "http://any", strings.NewReader(`{"key": "key", "min": 1}`))
+	got, err := s.request(req)
+
+	assert.Nil(t, err)
+	assert.Equal(t, request{Key: "key", Stop: -1, Min: 1, Max: math.Inf(1)}, got)
+}
+
+func Test_ozzoValidater_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      request
+		required []string
+		wantErr  bool
+		expect   validationErrorResponse
+	}{
+		{
+			name:     "valid",
+			req:      request{Key: "key", Members: []member{{Member: "a"}}},
+			required: []string{"members"},
+		},
+		{
+			name:     "invalid key and member",
+			req:      request{},
+			required: []string{"member"},
+			wantErr:  true,
+			expect: validationErrorResponse{
+				Message: "you have validation errors",
+				Errors: []validationError{
+					validationError{
+						Field:   "key",
+						Message: "cannot be blank",
+					},
+					validationError{
+						Field:   "member",
+						Message: "cannot be blank",
+					},
+				},
+			},
+		},
+	}
+
+	validater := ozzoValidater{}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validater.Validate(tt.req, tt.required...)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				got, ok := err.(validationErrorResponse)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expect, got)
+			}
+
+			if !tt.wantErr {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func Test_sZSetter(t *testing.T) {
+	z := &sZSetter{
+		storage: storage.New(),
+	}
+
+	z.storage.Set(context.Background(), "string", "value")
+
+	_, err := z.ZRank("zset", "a")
+	assert.Equal(t, notFoundResponse{Message: "key or member not found"}, err)
+
+	_, err = z.ZAdd(context.Background(), "string", member{Member: "a"})
+	assert.Equal(t, wrongTypeResponse{Message: "key holds the wrong kind of value"}, err)
+
+	_, err = z.ZAdd(context.Background(), "zset", member{Member: "a", Score: 2}, member{Member: "b", Score: 1})
+	assert.Nil(t, err)
+
+	members, err := z.ZRange("zset", 0, -1)
+	assert.Nil(t, err)
+	assert.Equal(t, []member{{Member: "b", Score: 1}, {Member: "a", Score: 2}}, members)
+}
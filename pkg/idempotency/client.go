@@ -0,0 +1,137 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Client is the Store keeping requests
+// in integral_db server at the url.
+type Client struct {
+	url  string
+	http *http.Client
+}
+
+// NewClient returns initialized client of the server at url,
+// like http://localhost:8080 or http://localhost:8080/ns/name
+// for the namespace. Default client is used when c is nil.
+func NewClient(url string, c *http.Client) *Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	return &Client{
+		url:  strings.TrimSuffix(url, "/"),
+		http: c,
+	}
+}
+
+// problem is error response of the server.
+type problem struct {
+	Code   Code   `json:"code"`
+	Detail string `json:"detail"`
+}
+
+type clientRequest struct {
+	Fingerprint string    `json:"fingerprint"`
+	TTL         string    `json:"ttl,omitempty"`
+	Response    *Response `json:"response,omitempty"`
+}
+
+// Begin implements the Store interface.
+func (c *Client) Begin(ctx context.Context, key, fingerprint string, lease time.Duration) (*Response, error) {
+	var resp Response
+	found, err := c.do(ctx, key, "begin", clientRequest{
+		Fingerprint: fingerprint,
+		TTL:         lease.String(),
+	}, &resp)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Complete implements the Store interface.
+func (c *Client) Complete(ctx context.Context, key, fingerprint string, resp Response, ttl time.Duration) error {
+	_, err := c.do(ctx, key, "complete", clientRequest{
+		Fingerprint: fingerprint,
+		TTL:         ttl.String(),
+		Response:    &resp,
+	}, nil)
+	return err
+}
+
+// Abort implements the Store interface.
+func (c *Client) Abort(ctx context.Context, key, fingerprint string) error {
+	_, err := c.do(ctx, key, "abort", clientRequest{
+		Fingerprint: fingerprint,
+	}, nil)
+	return err
+}
+
+// do posts req to the action of the key and decodes data
+// of the response into v, found reports it was present.
+func (c *Client) do(ctx context.Context, key, action string, req clientRequest, v interface{}) (bool, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return false, errors.Wrap(err, "marshal failed")
+	}
+
+	target := fmt.Sprintf("%s/v1/idempotency/%s/%s", c.url, url.PathEscape(key), action)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(b))
+	if err != nil {
+		return false, errors.Wrap(err, "new request failed")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return false, errors.Wrap(err, "request failed")
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		var p problem
+		if err := json.NewDecoder(httpResp.Body).Decode(&p); err != nil {
+			return false, errors.Errorf("unexpected status %d", httpResp.StatusCode)
+		}
+
+		switch p.Code {
+		case CodeInFlight:
+			return false, ErrInFlight
+		case CodeMismatch:
+			return false, ErrMismatch
+		case CodeNotStarted:
+			return false, ErrNotStarted
+		default:
+			return false, errors.Errorf("unexpected status %d: %s", httpResp.StatusCode, p.Detail)
+		}
+	}
+
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(&body); err != nil {
+		return false, errors.Wrap(err, "decode failed")
+	}
+
+	if v == nil || len(body.Data) == 0 {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(body.Data, v); err != nil {
+		return false, errors.Wrap(err, "decode failed")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// MaxBodySize limits bodies of fingerprinted
+// requests, larger ones are rejected.
+const MaxBodySize = 1 << 20
+
+// Code is a stable machine readable code of the error.
+type Code string
+
+// Codes of the errors.
+const (
+	CodeInFlight   Code = "request_in_flight"
+	CodeMismatch   Code = "request_mismatch"
+	CodeNotStarted Code = "request_not_started"
+	CodeTooLarge   Code = "body_too_large"
+	CodeInternal   Code = "internal_error"
+)
+
+var (
+	// ErrInFlight returns when the request with
+	// the key is processed at the moment.
+	ErrInFlight = &Error{Message: "request with the key is in progress", code: CodeInFlight, status: http.StatusConflict}
+	// ErrMismatch returns when the key was used
+	// by the request with other fingerprint.
+	ErrMismatch = &Error{Message: "key is used by other request", code: CodeMismatch, status: http.StatusUnprocessableEntity}
+	// ErrNotStarted returns when the request with
+	// the key is not in progress, so its response
+	// can't be stored.
+	ErrNotStarted = &Error{Message: "request with the key is not in progress", code: CodeNotStarted, status: http.StatusConflict}
+	// ErrTooLarge returns when the request body
+	// exceeds MaxBodySize, so it can't be fingerprinted.
+	ErrTooLarge = &Error{Message: "request body is too large", code: CodeTooLarge, status: http.StatusRequestEntityTooLarge}
+
+	// errInternal is responded for other errors.
+	errInternal = &Error{Message: "internal server error", code: CodeInternal, status: http.StatusInternalServerError}
+)
+
+// Error describes request which
+// can't be processed with the key.
+type Error struct {
+	code    Code
+	status  int
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Code returns code of the error.
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// Status returns HTTP status responded with the error.
+func (e *Error) Status() int {
+	return e.status
+}
+
+// Response is the final response of
+// the request stored for its retries.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// Store records requests and their
+// responses under idempotency keys.
+type Store interface {
+	// Begin marks the request with the fingerprint in progress
+	// under the key for lease and returns nil response. Response
+	// of the completed request is returned for its retries.
+	Begin(ctx context.Context, key, fingerprint string, lease time.Duration) (*Response, error)
+	// Complete stores the response of the request in progress
+	// under the key for ttl, which replaces its lease.
+	Complete(ctx context.Context, key, fingerprint string, resp Response, ttl time.Duration) error
+	// Abort forgets the request in progress,
+	// so retries process it again.
+	Abort(ctx context.Context, key, fingerprint string) error
+}
+
+// Fingerprint returns hash of method, URI and body of the
+// request, the body is restored for the next handlers.
+// Bodies over MaxBodySize are rejected with ErrTooLarge.
+func Fingerprint(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+		if err != nil {
+			return "", err
+		}
+		if len(b) > MaxBodySize {
+			return "", ErrTooLarge
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+		body = b
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,166 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// KeyHeader carries idempotency key of the request.
+	KeyHeader = "Idempotency-Key"
+	// ReplayedHeader marks responses replayed
+	// from the store for retried requests.
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// Middleware returns middleware which processes write requests
+// with Idempotency-Key header at most once while the key lives.
+// The key is reserved for lease while the request is processed,
+// so it must outlive handlers, and the response is stored for
+// ttl. Retries get the stored response, duplicates of the
+// request in progress get 409 and requests reusing the key
+// get 422. Responses with 5xx status are not stored, so the
+// request can be retried.
+func Middleware(store Store, lease, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(KeyHeader)
+			if key == "" || safe(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			fingerprint, err := Fingerprint(r)
+			if err != nil {
+				writeError(w, errors.Wrap(err, "fingerprint failed"))
+				return
+			}
+
+			stored, err := store.Begin(r.Context(), key, fingerprint, lease)
+			if err != nil {
+				writeError(w, errors.Wrap(err, "begin failed"))
+				return
+			}
+
+			if stored != nil {
+				replay(w, *stored)
+				return
+			}
+
+			rec := recorder{ResponseWriter: w}
+			// Request is finished even when the client is
+			// gone, so its retry doesn't wait for the ttl.
+			ctx := context.Background()
+
+			abort := func() {
+				if err := store.Abort(ctx, key, fingerprint); err != nil {
+					log.Printf("idempotency: could not abort request with key %q: %v", key, err)
+				}
+			}
+
+			defer func() {
+				if p := recover(); p != nil {
+					abort()
+					panic(p)
+				}
+			}()
+
+			next.ServeHTTP(&rec, r)
+
+			if rec.status == 0 {
+				rec.WriteHeader(http.StatusOK)
+			}
+
+			if rec.status >= http.StatusInternalServerError {
+				abort()
+				return
+			}
+
+			// Request is processed already, so the key stays
+			// reserved until the lease ends and retries are
+			// refused rather than processed once more.
+			if err := store.Complete(ctx, key, fingerprint, Response{
+				Status: rec.status,
+				Header: rec.header,
+				Body:   rec.body.Bytes(),
+			}, ttl); err != nil {
+				log.Printf("idempotency: could not complete request with key %q: %v", key, err)
+			}
+		})
+	}
+}
+
+// errorBody is JSON body of the error response.
+type errorBody struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError responds with status and code of the
+// error, other errors are logged as internal ones.
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := errors.Cause(err).(*Error)
+	if !ok {
+		log.Printf("idempotency: %v", err)
+		e = errInternal
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.status)
+	json.NewEncoder(w).Encode(errorBody{Code: e.code, Message: e.Message})
+}
+
+// safe reports whether requests of the
+// method don't change the state.
+func safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func replay(w http.ResponseWriter, resp Response) {
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(ReplayedHeader, strconv.FormatBool(true))
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// recorder copies the response
+// written to the client.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status != 0 {
+		return
+	}
+
+	r.status = status
+	r.header = r.ResponseWriter.Header().Clone()
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,265 @@
+package idempotency
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type storeMock struct {
+	beginFunc    func(string, string) (*Response, error)
+	completeFunc func(string, Response) error
+	abortFunc    func(string)
+}
+
+func (m storeMock) Begin(_ context.Context, key, fingerprint string, _ time.Duration) (*Response, error) {
+	return m.beginFunc(key, fingerprint)
+}
+
+func (m storeMock) Complete(_ context.Context, key, _ string, resp Response, _ time.Duration) error {
+	return m.completeFunc(key, resp)
+}
+
+func (m storeMock) Abort(_ context.Context, key, _ string) error {
+	m.abortFunc(key)
+	return nil
+}
+
+func Test_Middleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		key          string
+		body         string
+		beginFunc    func(string, string) (*Response, error)
+		completeErr  error
+		status       int
+		expectStatus int
+		expectBody   string
+		expectCalled bool
+		expectStored *Response
+		expectAbort  bool
+	}{
+		{
+			name:         "without key",
+			method:       "POST",
+			status:       http.StatusCreated,
+			expectStatus: http.StatusCreated,
+			expectBody:   "payment",
+			expectCalled: true,
+		},
+		{
+			name:         "safe method",
+			method:       "GET",
+			key:          "pay-1",
+			status:       http.StatusOK,
+			expectStatus: http.StatusOK,
+			expectBody:   "payment",
+			expectCalled: true,
+		},
+		{
+			name:   "first request",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return nil, nil
+			},
+			status:       http.StatusCreated,
+			expectStatus: http.StatusCreated,
+			expectBody:   "payment",
+			expectCalled: true,
+			expectStored: &Response{
+				Status: http.StatusCreated,
+				Header: http.Header{"Content-Type": {"text/plain"}},
+				Body:   []byte("payment"),
+			},
+		},
+		{
+			name:   "retry",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return &Response{Status: http.StatusCreated, Body: []byte("stored")}, nil
+			},
+			expectStatus: http.StatusCreated,
+			expectBody:   "stored",
+		},
+		{
+			name:   "in flight",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return nil, ErrInFlight
+			},
+			expectStatus: http.StatusConflict,
+			expectBody:   `"code":"request_in_flight"`,
+		},
+		{
+			name:   "store error",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return nil, errors.New("mock error")
+			},
+			expectStatus: http.StatusInternalServerError,
+			expectBody:   `{"code":"internal_error","message":"internal server error"}`,
+		},
+		{
+			name:         "too large",
+			method:       "POST",
+			key:          "pay-1",
+			body:         strings.Repeat("a", MaxBodySize+1),
+			expectStatus: http.StatusRequestEntityTooLarge,
+			expectBody:   `"code":"body_too_large"`,
+		},
+		{
+			name:   "mismatch",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return nil, ErrMismatch
+			},
+			expectStatus: http.StatusUnprocessableEntity,
+			expectBody:   `"code":"request_mismatch"`,
+		},
+		{
+			name:   "server error",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return nil, nil
+			},
+			status:       http.StatusBadGateway,
+			expectStatus: http.StatusBadGateway,
+			expectBody:   "payment",
+			expectCalled: true,
+			expectAbort:  true,
+		},
+		{
+			name:   "complete error",
+			method: "POST",
+			key:    "pay-1",
+			beginFunc: func(string, string) (*Response, error) {
+				return nil, nil
+			},
+			completeErr:  ErrNotStarted,
+			status:       http.StatusCreated,
+			expectStatus: http.StatusCreated,
+			expectBody:   "payment",
+			expectCalled: true,
+			expectStored: &Response{
+				Status: http.StatusCreated,
+				Header: http.Header{"Content-Type": {"text/plain"}},
+				Body:   []byte("payment"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				called  bool
+				stored  *Response
+				aborted bool
+			)
+
+			store := storeMock{
+				beginFunc: tt.beginFunc,
+				completeFunc: func(_ string, resp Response) error {
+					stored = &resp
+					return tt.completeErr
+				},
+				abortFunc: func(string) {
+					aborted = true
+				},
+			}
+
+			handler := Middleware(store, time.Minute, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+
+				// Body is restored after the fingerprint.
+				b, _ := ioutil.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(tt.status)
+				w.Write(b)
+			}))
+
+			body := tt.body
+			if body == "" {
+				body = "payment"
+			}
+
+			req := httptest.NewRequest(tt.method, "http://any/payments", strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set(KeyHeader, tt.key)
+			}
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tt.expectStatus, res.Code)
+			assert.Contains(t, res.Body.String(), tt.expectBody)
+			assert.Equal(t, tt.expectCalled, called)
+			assert.Equal(t, tt.expectStored, stored)
+			assert.Equal(t, tt.expectAbort, aborted)
+		})
+	}
+}
+
+func Test_Middleware_Replayed(t *testing.T) {
+	store := storeMock{
+		beginFunc: func(string, string) (*Response, error) {
+			return &Response{
+				Status: http.StatusCreated,
+				Header: http.Header{"Location": {"/payments/1"}},
+			}, nil
+		},
+	}
+
+	handler := Middleware(store, time.Minute, time.Hour)(http.NotFoundHandler())
+
+	req := httptest.NewRequest("POST", "http://any/payments", strings.NewReader("payment"))
+	req.Header.Set(KeyHeader, "pay-1")
+	res := httptest.NewRecorder()
+
+	handler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "/payments/1", res.Header().Get("Location"))
+	assert.Equal(t, "true", res.Header().Get(ReplayedHeader))
+}
+
+func Test_Fingerprint(t *testing.T) {
+	fingerprint := func(method, target, body string) string {
+		f, err := Fingerprint(httptest.NewRequest(method, target, strings.NewReader(body)))
+		assert.Nil(t, err)
+		return f
+	}
+
+	expect := fingerprint("POST", "http://any/payments", `{"amount":1}`)
+	assert.Equal(t, expect, fingerprint("POST", "http://other/payments", `{"amount":1}`))
+	assert.NotEqual(t, expect, fingerprint("POST", "http://any/payments", `{"amount":2}`))
+	assert.NotEqual(t, expect, fingerprint("PUT", "http://any/payments", `{"amount":1}`))
+	assert.NotEqual(t, expect, fingerprint("POST", "http://any/payments?dry=1", `{"amount":1}`))
+
+	_, err := Fingerprint(httptest.NewRequest("POST", "http://any/payments", strings.NewReader(strings.Repeat("a", MaxBodySize+1))))
+	assert.Equal(t, ErrTooLarge, err)
+}
+
+func TestError_Status(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, ErrInFlight.Status())
+	assert.Equal(t, http.StatusUnprocessableEntity, ErrMismatch.Status())
+	assert.Equal(t, http.StatusConflict, ErrNotStarted.Status())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, ErrTooLarge.Status())
+	assert.Equal(t, CodeInFlight, ErrInFlight.Code())
+}